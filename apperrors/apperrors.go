@@ -0,0 +1,91 @@
+// Package apperrors defines the domain errors shared by every layer. Each
+// error belongs to one of the sentinel kinds below, so callers can test for
+// it with errors.Is and the HTTP layer can pick the matching status code.
+package apperrors
+
+import "errors"
+
+var (
+	ErrBadRequest   = errors.New("bad request")
+	ErrValidation   = errors.New("validation failed")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrForeignKey   = errors.New("foreign key violation")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+
+	ErrPreconditionFailed   = errors.New("precondition failed")
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+)
+
+// Error is a domain error of a given kind. Field names the offending input
+// field, when there is one, and Details carries extra data for the client.
+type Error struct {
+	Kind    error
+	Message string
+	Field   string
+	Details interface{}
+	Err     error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is reports whether target is the kind of this error, so errors.Is(err,
+// ErrNotFound) matches any not-found error.
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func BadRequest(message string) *Error {
+	return &Error{Kind: ErrBadRequest, Message: message}
+}
+
+func Validation(field, message string) *Error {
+	return &Error{Kind: ErrValidation, Message: message, Field: field}
+}
+
+func NotFound(message string) *Error {
+	return &Error{Kind: ErrNotFound, Message: message}
+}
+
+func Conflict(message string) *Error {
+	return &Error{Kind: ErrConflict, Message: message}
+}
+
+func ForeignKey(field, message string) *Error {
+	return &Error{Kind: ErrForeignKey, Message: message, Field: field}
+}
+
+func Unauthorized(message string) *Error {
+	return &Error{Kind: ErrUnauthorized, Message: message}
+}
+
+func Forbidden(message string) *Error {
+	return &Error{Kind: ErrForbidden, Message: message}
+}
+
+func PreconditionFailed(message string) *Error {
+	return &Error{Kind: ErrPreconditionFailed, Message: message}
+}
+
+func UnsupportedMediaType(message string) *Error {
+	return &Error{Kind: ErrUnsupportedMediaType, Message: message}
+}
+
+// WithDetails attaches data that is rendered under "details" in the response.
+func (e *Error) WithDetails(details interface{}) *Error {
+	e.Details = details
+	return e
+}
+
+// Wrap attaches an underlying cause to a domain error.
+func Wrap(err error, appErr *Error) *Error {
+	appErr.Err = err
+	return appErr
+}
@@ -0,0 +1,62 @@
+package apperrors
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ErrorBody is the JSON error envelope returned by every endpoint.
+type ErrorBody struct {
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Field   string       `json:"field,omitempty"`
+	Errors  []FieldError `json:"errors,omitempty"`
+	Details interface{}  `json:"details,omitempty"`
+	TraceID string       `json:"trace_id,omitempty"`
+}
+
+// ErrorResponse wraps ErrorBody under an "error" key.
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+var statuses = []struct {
+	kind   error
+	status int
+	code   string
+}{
+	{ErrBadRequest, http.StatusBadRequest, "bad_request"},
+	{ErrValidation, http.StatusUnprocessableEntity, "validation_failed"},
+	{ErrNotFound, http.StatusNotFound, "not_found"},
+	{ErrConflict, http.StatusConflict, "conflict"},
+	{ErrForeignKey, http.StatusUnprocessableEntity, "foreign_key_violation"},
+	{ErrUnauthorized, http.StatusUnauthorized, "unauthorized"},
+	{ErrForbidden, http.StatusForbidden, "forbidden"},
+	{ErrPreconditionFailed, http.StatusPreconditionFailed, "precondition_failed"},
+	{ErrUnsupportedMediaType, http.StatusUnsupportedMediaType, "unsupported_media_type"},
+}
+
+// HTTPStatus maps an error to its status code and envelope. Errors that are
+// not domain errors are reported as a 500 without leaking their message.
+func HTTPStatus(err error) (int, ErrorBody) {
+	for _, s := range statuses {
+		if errors.Is(err, s.kind) {
+			body := ErrorBody{Code: s.code, Message: err.Error()}
+			var appErr *Error
+			if errors.As(err, &appErr) {
+				body.Message = appErr.Message
+				body.Field = appErr.Field
+				body.Details = appErr.Details
+			}
+			var fieldErrs ValidationErrors
+			if errors.As(err, &fieldErrs) {
+				body.Message = "request validation failed"
+				body.Errors = fieldErrs
+			} else if s.kind == ErrValidation && body.Field != "" {
+				body.Errors = []FieldError{{Field: body.Field, Rule: "invalid", Message: body.Message}}
+			}
+			return s.status, body
+		}
+	}
+	return http.StatusInternalServerError, ErrorBody{Code: "internal_error", Message: "Internal server error"}
+}
@@ -0,0 +1,27 @@
+package apperrors
+
+import "strings"
+
+// FieldError describes a single rule a request field broke.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors collects every rule violation found in a request, so a
+// client can fix them all at once instead of one round trip per field.
+type ValidationErrors []FieldError
+
+func (v ValidationErrors) Error() string {
+	messages := make([]string, len(v))
+	for i, fe := range v {
+		messages[i] = fe.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Is makes ValidationErrors match ErrValidation.
+func (v ValidationErrors) Is(target error) bool {
+	return target == ErrValidation
+}
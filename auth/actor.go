@@ -0,0 +1,28 @@
+package auth
+
+import "context"
+
+// SystemActor is the actor of changes made by the service itself, such as
+// scheduled jobs, rather than on behalf of a user.
+const SystemActor = "system"
+
+type actorKey struct{}
+
+// WithActor returns a copy of ctx recording the user a request is made by.
+func WithActor(ctx context.Context, username string) context.Context {
+	return context.WithValue(ctx, actorKey{}, username)
+}
+
+// Actor returns the user recorded in ctx by WithActor, or SystemActor.
+func Actor(ctx context.Context) string {
+	if username, ok := User(ctx); ok {
+		return username
+	}
+	return SystemActor
+}
+
+// User returns the user recorded in ctx by WithActor, if there is one.
+func User(ctx context.Context) (string, bool) {
+	username, ok := ctx.Value(actorKey{}).(string)
+	return username, ok && username != ""
+}
@@ -0,0 +1,204 @@
+// Package auth holds the keys used to sign and verify carzone JWTs.
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// legacyKeyID identifies the shared HS256 secret. Tokens signed with it carry
+// no kid header, so it is only looked up for headerless HS256 tokens.
+const legacyKeyID = ""
+
+// SigningKey is a single key. Keys loaded from a public key file, and the
+// legacy HS256 secret, can only verify; everything else can also sign.
+type SigningKey struct {
+	ID      string
+	Method  jwt.SigningMethod
+	private interface{}
+	public  interface{}
+}
+
+// KeySet is the collection of keys carzone trusts. One of them, the active
+// key, signs new tokens; every key in the set is accepted for verification so
+// tokens signed with a retired key stay valid until they expire.
+type KeySet struct {
+	active *SigningKey
+	keys   map[string]*SigningKey
+}
+
+// LoadKeySet builds the key set from the environment.
+//
+// JWT_KEYS_DIR points at a directory of PEM files named <kid>.pem. Private keys
+// (PKCS#1 or PKCS#8, RSA or Ed25519) can sign and verify; public keys (PKIX)
+// only verify, which is how a retired key is kept around during rotation.
+// JWT_ACTIVE_KID picks the signing key and may be omitted when the directory
+// holds a single private key.
+//
+// When JWT_KEYS_DIR is unset, tokens are signed with HS256 using JWT_SECRET as
+// before. When both are set, JWT_SECRET is only used to verify tokens issued
+// before the switch and should be removed once they have expired.
+func LoadKeySet() (*KeySet, error) {
+	ks := &KeySet{keys: map[string]*SigningKey{}}
+
+	if secret := os.Getenv("JWT_SECRET"); secret != "" {
+		ks.keys[legacyKeyID] = &SigningKey{
+			ID:      legacyKeyID,
+			Method:  jwt.SigningMethodHS256,
+			private: []byte(secret),
+			public:  []byte(secret),
+		}
+	}
+
+	dir := os.Getenv("JWT_KEYS_DIR")
+	if dir == "" {
+		ks.active = ks.keys[legacyKeyID]
+		if ks.active == nil {
+			return nil, errors.New("either JWT_KEYS_DIR or JWT_SECRET must be set")
+		}
+		return ks, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+
+	var signers []string
+	for _, file := range files {
+		key, err := loadKeyFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("loading %s: %w", file, err)
+		}
+		ks.keys[key.ID] = key
+		if key.private != nil {
+			signers = append(signers, key.ID)
+		}
+	}
+
+	activeID := os.Getenv("JWT_ACTIVE_KID")
+	if activeID == "" && len(signers) == 1 {
+		activeID = signers[0]
+	}
+	if activeID == "" {
+		return nil, errors.New("JWT_ACTIVE_KID must be set when JWT_KEYS_DIR holds zero or several private keys")
+	}
+
+	active, ok := ks.keys[activeID]
+	if !ok || active.private == nil || activeID == legacyKeyID {
+		return nil, fmt.Errorf("no private key found for JWT_ACTIVE_KID %q", activeID)
+	}
+	ks.active = active
+
+	return ks, nil
+}
+
+// Sign signs the claims with the active key and records its kid in the header.
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.active.Method, claims)
+	if ks.active.ID != legacyKeyID {
+		token.Header["kid"] = ks.active.ID
+	}
+	return token.SignedString(ks.active.private)
+}
+
+// Keyfunc resolves the verification key for a token, for use with jwt.Parse.
+// The token's alg must match the key, so a public key can never be used as an
+// HMAC secret.
+func (ks *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+	}
+
+	return key.public, nil
+}
+
+// Keys returns the asymmetric verification keys, sorted by kid. The HS256
+// secret is never included.
+func (ks *KeySet) Keys() []*SigningKey {
+	var keys []*SigningKey
+	for id, key := range ks.keys {
+		if id != legacyKeyID {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+	return keys
+}
+
+func loadKeyFile(file string) (*SigningKey, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	key := &SigningKey{ID: strings.TrimSuffix(filepath.Base(file), ".pem")}
+	if key.ID == legacyKeyID {
+		return nil, errors.New("key file name cannot be empty")
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		private, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		key.private = private
+	case "PRIVATE KEY":
+		private, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		key.private = private
+	case "PUBLIC KEY":
+		public, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		key.public = public
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %q", block.Type)
+	}
+
+	if key.private != nil {
+		switch private := key.private.(type) {
+		case *rsa.PrivateKey:
+			key.public = &private.PublicKey
+		case ed25519.PrivateKey:
+			key.public = private.Public()
+		}
+	}
+
+	switch key.public.(type) {
+	case *rsa.PublicKey:
+		key.Method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		key.Method = jwt.SigningMethodEdDSA
+	default:
+		return nil, errors.New("only RSA and Ed25519 keys are supported")
+	}
+
+	return key, nil
+}
@@ -0,0 +1,57 @@
+package driver
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"gorm.io/gorm"
+)
+
+// The databases the server can run on, chosen with DB_DRIVER.
+const (
+	// Postgres is the production database, configured with DB_HOST,
+	// DB_PORT, DB_USER, DB_PASS and DB_NAME. It is the default.
+	Postgres = "postgres"
+	// SQLite keeps everything in the single file named by DB_PATH, for
+	// demos and offline development.
+	SQLite = "sqlite"
+	// Memory keeps cars, engines and brands in the in-memory repositories
+	// and everything else in an in-memory SQLite database. Nothing survives
+	// a restart.
+	Memory = "memory"
+)
+
+// Name returns the driver selected with DB_DRIVER.
+func Name() (string, error) {
+	switch name := os.Getenv("DB_DRIVER"); name {
+	case "":
+		return Postgres, nil
+	case Postgres, SQLite, Memory:
+		return name, nil
+	default:
+		return "", fmt.Errorf("unknown DB_DRIVER %q, expected %s, %s or %s", name, Postgres, SQLite, Memory)
+	}
+}
+
+// InitDB connects to the database selected with DB_DRIVER. GORM logs the
+// statements it runs to logger.
+func InitDB(logger *slog.Logger) (*gorm.DB, error) {
+	name, err := Name()
+	if err != nil {
+		return nil, err
+	}
+
+	switch name {
+	case SQLite:
+		path := os.Getenv("DB_PATH")
+		if path == "" {
+			path = "carzone.db"
+		}
+		return initSQLite("file:"+path, logger)
+	case Memory:
+		return initSQLite("file::memory:", logger)
+	default:
+		return initPostgres(logger)
+	}
+}
@@ -2,15 +2,16 @@ package driver
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 
+	"github.com/Tushar456/go-carzone/logging"
 	_ "github.com/lib/pq"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-func InitDB() (*gorm.DB, error) {
+func initPostgres(logger *slog.Logger) (*gorm.DB, error) {
 
 	constStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
 		os.Getenv("DB_HOST"),
@@ -20,24 +21,26 @@ func InitDB() (*gorm.DB, error) {
 		os.Getenv("DB_NAME"))
 
 	//db, err := sql.Open("postgres", constStr)
-	db, err := gorm.Open(postgres.Open(constStr), &gorm.Config{})
+	db, err := gorm.Open(postgres.Open(constStr), &gorm.Config{
+		// Report constraint violations as gorm.ErrDuplicatedKey and
+		// gorm.ErrForeignKeyViolated instead of driver specific errors.
+		TranslateError: true,
+		Logger:         logging.GORM(logger),
+	})
 	if err != nil {
-		log.Fatalf("Error %s when opening DB\n", err)
 		return nil, err
 	}
 
 	sqlDB, err := db.DB()
 	if err != nil {
-		log.Fatalf("Error %s when getting generic DB\n", err)
 		return nil, err
 	}
 
 	if err = sqlDB.Ping(); err != nil {
-		log.Fatalf("Error %s pinging DB\n", err)
 		return nil, err
 	}
 
-	fmt.Println("Connected to DB successfully")
+	logger.Info("Connected to DB successfully")
 
 	return db, nil
 }
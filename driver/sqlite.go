@@ -0,0 +1,42 @@
+package driver
+
+import (
+	"log/slog"
+
+	"github.com/Tushar456/go-carzone/logging"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// initSQLite opens an SQLite database with the pure Go driver, so no C
+// toolchain is needed.
+func initSQLite(dsn string, logger *slog.Logger) (*gorm.DB, error) {
+	db, err := gorm.Open(sqlite.Open(dsn+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"), &gorm.Config{
+		TranslateError: true,
+		Logger:         logging.GORM(logger),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite allows one writer at a time, and an in-memory database only
+	// lives as long as its connection. A single connection queues writes
+	// instead of failing them with SQLITE_BUSY, and keeps the database
+	// alive.
+	sqlDB.SetMaxOpenConns(1)
+	sqlDB.SetConnMaxLifetime(0)
+	sqlDB.SetConnMaxIdleTime(0)
+
+	if err = sqlDB.Ping(); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Connected to DB successfully")
+
+	return db, nil
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"errors"
+	"os"
+
+	"github.com/Tushar456/go-carzone/outbox"
+)
+
+// outboxSink builds the sink the outbox relay publishes to besides the
+// webhook subscriptions from OUTBOX_WEBHOOK_URL, which POSTs each event to a
+// URL, or OUTBOX_FILE, which appends them to a file. With neither set it
+// returns nil.
+func outboxSink() (outbox.Sink, error) {
+	url, path := os.Getenv("OUTBOX_WEBHOOK_URL"), os.Getenv("OUTBOX_FILE")
+	switch {
+	case url != "" && path != "":
+		return nil, errors.New("set only one of OUTBOX_WEBHOOK_URL and OUTBOX_FILE")
+	case url != "":
+		return outbox.NewWebhookSink(url), nil
+	case path != "":
+		return outbox.NewFileSink(path), nil
+	default:
+		return nil, nil
+	}
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Tushar456/go-carzone/apperrors"
+	"github.com/Tushar456/go-carzone/models"
+	"github.com/Tushar456/go-carzone/service"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+)
+
+type AuditHandler struct {
+	auditService service.AuditServiceInterface
+}
+
+func NewAuditHandler(auditService service.AuditServiceInterface) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// ListAuditEntriesHandler godoc
+// @Summary      List audit log entries
+// @Description  list who created, updated, deleted or restored cars and engines, most recent first
+// @Tags         audit
+// @Produce      json
+// @Param        entity  query     string  false  "Entity type"  Enums(car, engine)
+// @Param        id      query     string  false  "Entity ID"
+// @Param        actor   query     string  false  "Username of the actor"
+// @Param        action  query     string  false  "Action"  Enums(create, update, delete, restore)
+// @Param        from    query     string  false  "Earliest time, RFC 3339"
+// @Param        to      query     string  false  "Latest time, RFC 3339"
+// @Param        limit   query     int     false  "Page size (max 100)"
+// @Param        offset  query     int     false  "Number of entries to skip"
+// @Success      200  {object}  models.Page[models.AuditEntry]
+// @Failure      400  {object}  apperrors.ErrorResponse
+// @Failure      403  {object}  apperrors.ErrorResponse
+// @Failure      422  {object}  apperrors.ErrorResponse
+// @Router       /audit [get]
+// @Security     BearerAuth
+func (ah *AuditHandler) ListAuditEntriesHandler(c *gin.Context) {
+	ctx, span := otel.Tracer("auditservice").Start(c.Request.Context(), "ListAuditEntriesHandler")
+	defer span.End()
+
+	var filter models.AuditFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Error(apperrors.BadRequest(err.Error()))
+		return
+	}
+
+	page, err := ah.auditService.ListAuditEntries(ctx, &filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Tushar456/go-carzone/apperrors"
+	"github.com/Tushar456/go-carzone/models"
+	"github.com/Tushar456/go-carzone/service"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+)
+
+type BrandHandler struct {
+	brandService service.BrandServiceInterface
+}
+
+func NewBrandHandler(brandService service.BrandServiceInterface) *BrandHandler {
+	return &BrandHandler{
+		brandService: brandService,
+	}
+}
+
+// ListBrandsHandler godoc
+// @Summary      List brands
+// @Description  list the brand catalog ordered by name
+// @Tags         brands
+// @Produce      json
+// @Param        limit   query     int  false  "Page size (max 100)"
+// @Param        offset  query     int  false  "Number of brands to skip"
+// @Success      200  {object}  models.Page[models.Brand]
+// @Failure      400  {object}  apperrors.ErrorResponse
+// @Router       /brands [get]
+// @Security     BearerAuth
+func (bh *BrandHandler) ListBrandsHandler(c *gin.Context) {
+	ctx, span := otel.Tracer("brandservice").Start(c.Request.Context(), "ListBrandsHandler")
+	defer span.End()
+
+	var filter models.BrandFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Error(apperrors.BadRequest(err.Error()))
+		return
+	}
+
+	page, err := bh.brandService.ListBrands(ctx, &filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// GetBrandByIdHandler godoc
+// @Summary      Get brand by ID
+// @Description  get brand by ID
+// @Tags         brands
+// @Produce      json
+// @Param        id   path      string  true  "Brand ID"
+// @Success      200  {object}  models.Brand
+// @Failure      400  {object}  apperrors.ErrorResponse
+// @Failure      404  {object}  apperrors.ErrorResponse
+// @Router       /brands/{id} [get]
+// @Security     BearerAuth
+func (bh *BrandHandler) GetBrandByIdHandler(c *gin.Context) {
+	ctx, span := otel.Tracer("brandservice").Start(c.Request.Context(), "GetBrandByIdHandler")
+	defer span.End()
+
+	brand, err := bh.brandService.GetBrandById(ctx, c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, brand)
+}
+
+// ResolveBrandHandler godoc
+// @Summary      Resolve brand name
+// @Description  find the brand a name or alias refers to, ignoring case, spaces and punctuation
+// @Tags         brands
+// @Produce      json
+// @Param        name  query     string  true  "Brand name or alias"
+// @Success      200   {object}  models.Brand
+// @Failure      404   {object}  apperrors.ErrorResponse
+// @Failure      422   {object}  apperrors.ErrorResponse
+// @Router       /brands/resolve [get]
+// @Security     BearerAuth
+func (bh *BrandHandler) ResolveBrandHandler(c *gin.Context) {
+	ctx, span := otel.Tracer("brandservice").Start(c.Request.Context(), "ResolveBrandHandler")
+	defer span.End()
+
+	brand, err := bh.brandService.ResolveBrand(ctx, c.Query("name"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, brand)
+}
+
+// CreateBrandHandler godoc
+// @Summary      Create brand
+// @Description  add a brand and its aliases to the catalog
+// @Tags         brands
+// @Accept       json
+// @Produce      json
+// @Param        brand  body      models.BrandRequest  true  "Brand Request"
+// @Success      201    {object}  models.Brand
+// @Failure      400    {object}  apperrors.ErrorResponse
+// @Failure      409    {object}  apperrors.ErrorResponse
+// @Failure      422    {object}  apperrors.ErrorResponse
+// @Router       /brands [post]
+// @Security     BearerAuth
+func (bh *BrandHandler) CreateBrandHandler(c *gin.Context) {
+	ctx, span := otel.Tracer("brandservice").Start(c.Request.Context(), "CreateBrandHandler")
+	defer span.End()
+
+	var brandRequest models.BrandRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&brandRequest); err != nil {
+		c.Error(apperrors.BadRequest(err.Error()))
+		return
+	}
+
+	createdBrand, err := bh.brandService.CreateBrand(ctx, &brandRequest)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, createdBrand)
+}
+
+// UpdateBrandHandler godoc
+// @Summary      Update brand
+// @Description  replace a brand's fields and aliases; renaming a brand renames its cars
+// @Tags         brands
+// @Accept       json
+// @Produce      json
+// @Param        id     path      string               true  "Brand ID"
+// @Param        brand  body      models.BrandRequest  true  "Brand Request"
+// @Success      200    {object}  models.Brand
+// @Failure      400    {object}  apperrors.ErrorResponse
+// @Failure      404    {object}  apperrors.ErrorResponse
+// @Failure      409    {object}  apperrors.ErrorResponse
+// @Failure      422    {object}  apperrors.ErrorResponse
+// @Router       /brands/{id} [put]
+// @Security     BearerAuth
+func (bh *BrandHandler) UpdateBrandHandler(c *gin.Context) {
+	ctx, span := otel.Tracer("brandservice").Start(c.Request.Context(), "UpdateBrandHandler")
+	defer span.End()
+
+	var brandRequest models.BrandRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&brandRequest); err != nil {
+		c.Error(apperrors.BadRequest(err.Error()))
+		return
+	}
+
+	updatedBrand, err := bh.brandService.UpdateBrand(ctx, c.Param("id"), &brandRequest)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, updatedBrand)
+}
+
+// DeleteBrandHandler godoc
+// @Summary      Delete brand
+// @Description  delete a brand that no car refers to
+// @Tags         brands
+// @Produce      json
+// @Param        id   path      string  true  "Brand ID"
+// @Success      200  {object}  models.Brand
+// @Failure      400  {object}  apperrors.ErrorResponse
+// @Failure      404  {object}  apperrors.ErrorResponse
+// @Failure      409  {object}  apperrors.ErrorResponse
+// @Router       /brands/{id} [delete]
+// @Security     BearerAuth
+func (bh *BrandHandler) DeleteBrandHandler(c *gin.Context) {
+	ctx, span := otel.Tracer("brandservice").Start(c.Request.Context(), "DeleteBrandHandler")
+	defer span.End()
+
+	deletedBrand, err := bh.brandService.DeleteBrand(ctx, c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, deletedBrand)
+}
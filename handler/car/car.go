@@ -2,9 +2,13 @@ package handler
 
 import (
 	"encoding/json"
-	"log"
+	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
 
+	"github.com/Tushar456/go-carzone/apperrors"
+	"github.com/Tushar456/go-carzone/middleware"
 	"github.com/Tushar456/go-carzone/models"
 	"github.com/Tushar456/go-carzone/service"
 	"github.com/gin-gonic/gin"
@@ -13,11 +17,13 @@ import (
 
 type CarHandler struct {
 	carService service.CarServiceInterface
+	logger     *slog.Logger
 }
 
-func NewCarHandler(carService service.CarServiceInterface) *CarHandler {
+func NewCarHandler(carService service.CarServiceInterface, logger *slog.Logger) *CarHandler {
 	return &CarHandler{
 		carService: carService,
+		logger:     logger,
 	}
 }
 
@@ -26,9 +32,12 @@ func NewCarHandler(carService service.CarServiceInterface) *CarHandler {
 //	@Summary		Get car by ID
 //	@Description	get car by ID
 //	@Tags			cars
-//	@Param			id	path		string	true	"Car ID"
-//	@Success		200	{object}	models.Car
-//	@Failure		404	{object}	map[string]string
+//	@Param			id				path		string	true	"Car ID"
+//	@Param			If-None-Match	header		string	false	"ETag of the cached car"
+//	@Success		200				{object}	models.Car
+//	@Header			200				{string}	ETag	"Versions of the car and its engine"
+//	@Success		304
+//	@Failure		404	{object}	apperrors.ErrorResponse
 //	@Router			/cars/{id} [get]
 //
 // @Security     BearerAuth
@@ -39,26 +48,49 @@ func (ch *CarHandler) GetCarByIdHandler(c *gin.Context) {
 	car, err := ch.carService.GetCarById(ctx, id)
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
-		log.Printf("Error fetching car by ID: %v", err)
+		c.Error(err)
 		return
 	}
 
-	if car.ID.String() == "" {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "not found"})
+	if middleware.NotModified(c, car.Version, car.Engine.Version) {
 		return
 	}
 
 	body, err := json.Marshal(car)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		log.Printf("Error marshalling car data: %v", err)
+		c.Error(err)
 		return
 	}
 	c.Data(http.StatusOK, "application/json", body)
 
 }
 
+// GetCarPriceHistoryHandler godoc
+//
+//	@Summary		Get car price history
+//	@Description	list the price changes of a car, most recent first
+//	@Tags			cars
+//	@Produce		json
+//	@Param			id	path		string	true	"Car ID"
+//	@Success		200	{array}		models.CarPriceHistory
+//	@Failure		400	{object}	apperrors.ErrorResponse
+//	@Failure		404	{object}	apperrors.ErrorResponse
+//	@Router			/cars/{id}/price-history [get]
+//
+// @Security     BearerAuth
+func (ch *CarHandler) GetCarPriceHistoryHandler(c *gin.Context) {
+	ctx, span := otel.Tracer("carservice").Start(c.Request.Context(), "GetCarPriceHistoryHandler")
+	defer span.End()
+
+	history, err := ch.carService.GetCarPriceHistory(ctx, c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
 // GetCarByBrandHandler godoc
 //
 //	@Summary		Get cars by brand
@@ -67,7 +99,7 @@ func (ch *CarHandler) GetCarByIdHandler(c *gin.Context) {
 //	@Param			brand		path		string	true	"Brand"
 //	@Param			isEngine	query		bool	false	"Include engine"
 //	@Success		200			{array}		models.Car
-//	@Failure		404			{object}	map[string]string
+//	@Failure		404			{object}	apperrors.ErrorResponse
 //	@Router			/cars/brand/{brand} [get]
 //
 // @Security     BearerAuth
@@ -79,19 +111,105 @@ func (ch *CarHandler) GetCarByBrandHandler(c *gin.Context) {
 	isEngine := c.DefaultQuery("isEngine", "false") == "true"
 	cars, err := ch.carService.GetCarByBrand(ctx, brand, isEngine)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
-		log.Printf("Error fetching cars by brand: %v", err)
+		c.Error(err)
 		return
 	}
 
 	if len(cars) == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "No cars found"})
+		c.Error(apperrors.NotFound("no cars found"))
 		return
 	}
 
 	c.JSON(http.StatusOK, cars)
 }
 
+// ListCarsHandler godoc
+//
+//	@Summary		List cars
+//	@Description	list cars with optional filters, sorting and offset or cursor pagination
+//	@Tags			cars
+//	@Produce		json
+//	@Param			brand		query		string	false	"Brand"
+//	@Param			fuel_type	query		string	false	"Fuel type"	Enums(Petrol, Diesel, Electric, Hybrid)
+//	@Param			year_from	query		int		false	"Minimum year"
+//	@Param			year_to		query		int		false	"Maximum year"
+//	@Param			price_min	query		number	false	"Minimum price, in the currency given by currency"
+//	@Param			price_max	query		number	false	"Maximum price, in the currency given by currency"
+//	@Param			currency	query		string	false	"Only cars priced in this ISO 4217 currency"
+//	@Param			convert_to	query		string	false	"ISO 4217 currency to convert the prices into, using the configured exchange rates"
+//	@Param			engine_id	query		string	false	"Engine ID"
+//	@Param			sort_by		query		string	false	"Sort column; price requires currency"	Enums(id, name, year, brand, fuel_type, engine_id, price, created_at, updated_at)
+//	@Param			sort_order	query		string	false	"Sort order"	Enums(asc, desc)
+//	@Param			limit		query		int		false	"Page size (max 100)"
+//	@Param			offset		query		int		false	"Number of cars to skip"
+//	@Param			cursor		query		string	false	"Cursor returned as next_cursor by the previous page"
+//	@Param			isEngine	query		bool	false	"Include engine"
+//	@Param			include_deleted	query		bool	false	"Include soft deleted cars (admins only)"
+//	@Success		200			{object}	models.Page[models.Car]
+//	@Failure		400			{object}	apperrors.ErrorResponse
+//	@Failure		403			{object}	apperrors.ErrorResponse
+//	@Router			/cars [get]
+//
+// @Security     BearerAuth
+func (ch *CarHandler) ListCarsHandler(c *gin.Context) {
+	ctx, span := otel.Tracer("carservice").Start(c.Request.Context(), "ListCarsHandler")
+	defer span.End()
+
+	var filter models.CarFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Error(apperrors.BadRequest(err.Error()))
+		return
+	}
+
+	if filter.IncludeDeleted && c.GetString("role") != models.RoleAdmin {
+		c.Error(apperrors.Forbidden("only admins can list deleted cars"))
+		return
+	}
+
+	page, err := ch.carService.ListCars(ctx, &filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// SearchCarsHandler godoc
+//
+//	@Summary		Search cars
+//	@Description	full-text and typo tolerant search over car names and brands, best matches first, with the matched terms highlighted
+//	@Tags			cars
+//	@Produce		json
+//	@Param			q			query		string	true	"Search text"
+//	@Param			limit		query		int		false	"Page size (max 100)"
+//	@Param			offset		query		int		false	"Number of results to skip"
+//	@Param			isEngine	query		bool	false	"Include engine"
+//	@Success		200			{object}	models.Page[models.CarSearchResult]
+//	@Failure		400			{object}	apperrors.ErrorResponse
+//	@Failure		422			{object}	apperrors.ErrorResponse
+//	@Router			/cars/search [get]
+//
+// @Security     BearerAuth
+func (ch *CarHandler) SearchCarsHandler(c *gin.Context) {
+	ctx, span := otel.Tracer("carservice").Start(c.Request.Context(), "SearchCarsHandler")
+	defer span.End()
+
+	var query models.CarSearchQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.Error(apperrors.BadRequest(err.Error()))
+		return
+	}
+
+	page, err := ch.carService.SearchCars(ctx, &query)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
 // CreateCarHandler godoc
 //
 //	@Summary		Create car
@@ -101,7 +219,8 @@ func (ch *CarHandler) GetCarByBrandHandler(c *gin.Context) {
 //	@Produce		json
 //	@Param			car	body		models.CarRequest	true	"Car Request"
 //	@Success		201	{object}	models.Car
-//	@Failure		400	{object}	map[string]string
+//	@Failure		400	{object}	apperrors.ErrorResponse
+//	@Failure		422	{object}	apperrors.ErrorResponse
 //	@Router			/cars [post]
 //
 // @Security BearerAuth
@@ -112,27 +231,90 @@ func (ch *CarHandler) CreateCarHandler(c *gin.Context) {
 	var carRequest models.CarRequest
 	err := c.ShouldBindJSON(&carRequest)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		log.Printf("Error decoding car request: %v", err)
+		c.Error(apperrors.BadRequest(err.Error()))
 		return
 	}
 
 	createdCar, err := ch.carService.CreateCar(ctx, &carRequest)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
-		log.Printf("Error creating car: %v", err)
+		c.Error(err)
 		return
 	}
+	c.Header("ETag", middleware.ETag(createdCar.Version, createdCar.Engine.Version))
 	body, err := json.Marshal(createdCar)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		log.Printf("Error marshalling created car data: %v", err)
+		c.Error(err)
 		return
 	}
 	c.Data(http.StatusCreated, "application/json", body)
 
 }
 
+// BulkCarsHandler godoc
+//
+//	@Summary		Bulk create, update and delete cars
+//	@Description	apply many car operations at once, sent as a JSON array or as NDJSON (one operation per line); deletes require the admin role
+//	@Tags			cars
+//	@Accept			json
+//	@Accept			application/x-ndjson
+//	@Produce		json
+//	@Param			mode		query		string						false	"atomic applies all operations or none, best_effort (default) applies those that succeed"	Enums(atomic, best_effort)
+//	@Param			dry_run		query		bool						false	"Validate the operations without writing anything"
+//	@Param			operations	body		[]models.BulkCarOperation	true	"Operations"
+//	@Success		200			{object}	models.BulkCarResult
+//	@Success		207			{object}	models.BulkCarResult
+//	@Failure		400			{object}	apperrors.ErrorResponse
+//	@Failure		403			{object}	apperrors.ErrorResponse
+//	@Failure		422			{object}	apperrors.ErrorResponse
+//	@Router			/cars/bulk [post]
+//
+// @Security BearerAuth
+func (ch *CarHandler) BulkCarsHandler(c *gin.Context) {
+	ctx, span := otel.Tracer("carservice").Start(c.Request.Context(), "BulkCarsHandler")
+	defer span.End()
+
+	var options models.BulkOptions
+	if err := c.ShouldBindQuery(&options); err != nil {
+		c.Error(apperrors.BadRequest(err.Error()))
+		return
+	}
+
+	var operations []models.BulkCarOperation
+	if c.ContentType() == "application/x-ndjson" {
+		decoder := json.NewDecoder(c.Request.Body)
+		for decoder.More() {
+			var operation models.BulkCarOperation
+			if err := decoder.Decode(&operation); err != nil {
+				c.Error(apperrors.BadRequest(fmt.Sprintf("line %d: %v", len(operations)+1, err)))
+				return
+			}
+			operations = append(operations, operation)
+		}
+	} else if err := c.ShouldBindJSON(&operations); err != nil {
+		c.Error(apperrors.BadRequest(err.Error()))
+		return
+	}
+
+	for _, operation := range operations {
+		if operation.Op == models.BulkOpDelete && c.GetString("role") != models.RoleAdmin {
+			c.Error(apperrors.Forbidden("only admins can delete cars"))
+			return
+		}
+	}
+
+	result, err := ch.carService.BulkCars(ctx, options, operations)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	status := http.StatusOK
+	if result.Failed > 0 {
+		status = http.StatusMultiStatus
+	}
+	c.JSON(status, result)
+}
+
 // UpdateCarHandler godoc
 //
 //	@Summary		Update car
@@ -140,10 +322,14 @@ func (ch *CarHandler) CreateCarHandler(c *gin.Context) {
 //	@Tags			cars
 //	@Accept			json
 //	@Produce		json
-//	@Param			id	path		string				true	"Car ID"
-//	@Param			car	body		models.CarRequest	true	"Car Request"
-//	@Success		200	{object}	models.Car
-//	@Failure		400	{object}	map[string]string
+//	@Param			id			path		string				true	"Car ID"
+//	@Param			If-Match	header		string				false	"ETag the car must still have"
+//	@Param			car			body		models.CarRequest	true	"Car Request"
+//	@Success		200			{object}	models.Car
+//	@Header			200			{string}	ETag	"Versions of the updated car and its engine"
+//	@Failure		400			{object}	apperrors.ErrorResponse
+//	@Failure		412			{object}	apperrors.ErrorResponse
+//	@Failure		422			{object}	apperrors.ErrorResponse
 //	@Router			/cars/{id} [put]
 //
 // @Security BearerAuth
@@ -155,21 +341,78 @@ func (ch *CarHandler) UpdateCarHandler(c *gin.Context) {
 	id := c.Param("id")
 	err := c.ShouldBindJSON(&carRequest)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		log.Printf("Error decoding car request: %v", err)
+		c.Error(apperrors.BadRequest(err.Error()))
+		return
+	}
+
+	version, err := middleware.IfMatchVersion(c)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	updatedCar, err := ch.carService.UpdateCar(ctx, id, &carRequest)
+	updatedCar, err := ch.carService.UpdateCar(ctx, id, &carRequest, version)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
-		log.Printf("Error updating car: %v", err)
+		c.Error(err)
 		return
 	}
+	c.Header("ETag", middleware.ETag(updatedCar.Version, updatedCar.Engine.Version))
 	body, err := json.Marshal(updatedCar)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		log.Printf("Error marshalling updated car data: %v", err)
+		c.Error(err)
+		return
+	}
+	c.Data(http.StatusOK, "application/json", body)
+}
+
+// PatchCarHandler godoc
+//
+//	@Summary		Patch car
+//	@Description	partially update a car with a JSON Merge Patch (RFC 7396) or JSON Patch (RFC 6902) applied to its request fields
+//	@Tags			cars
+//	@Accept			application/merge-patch+json
+//	@Accept			application/json-patch+json
+//	@Produce		json
+//	@Param			id			path		string	true	"Car ID"
+//	@Param			If-Match	header		string	false	"ETag the car must still have"
+//	@Param			patch		body		object	true	"Merge patch object or array of JSON Patch operations"
+//	@Success		200			{object}	models.Car
+//	@Header			200			{string}	ETag	"Versions of the patched car and its engine"
+//	@Failure		400			{object}	apperrors.ErrorResponse
+//	@Failure		404			{object}	apperrors.ErrorResponse
+//	@Failure		409			{object}	apperrors.ErrorResponse
+//	@Failure		412			{object}	apperrors.ErrorResponse
+//	@Failure		415			{object}	apperrors.ErrorResponse
+//	@Failure		422			{object}	apperrors.ErrorResponse
+//	@Router			/cars/{id} [patch]
+//
+// @Security BearerAuth
+func (ch *CarHandler) PatchCarHandler(c *gin.Context) {
+	ctx, span := otel.Tracer("carservice").Start(c.Request.Context(), "PatchCarHandler")
+	defer span.End()
+	id := c.Param("id")
+
+	patch, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.Error(apperrors.BadRequest(err.Error()))
+		return
+	}
+
+	version, err := middleware.IfMatchVersion(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	patchedCar, err := ch.carService.PatchCar(ctx, id, c.ContentType(), patch, version)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.Header("ETag", middleware.ETag(patchedCar.Version, patchedCar.Engine.Version))
+	body, err := json.Marshal(patchedCar)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 	c.Data(http.StatusOK, "application/json", body)
@@ -180,9 +423,11 @@ func (ch *CarHandler) UpdateCarHandler(c *gin.Context) {
 //	@Summary		Delete car
 //	@Description	delete car
 //	@Tags			cars
-//	@Param			id	path		string	true	"Car ID"
-//	@Success		200	{object}	models.Car
-//	@Failure		404	{object}	map[string]string
+//	@Param			id			path		string	true	"Car ID"
+//	@Param			If-Match	header		string	false	"ETag the car must still have"
+//	@Success		200			{object}	models.Car
+//	@Failure		404			{object}	apperrors.ErrorResponse
+//	@Failure		412			{object}	apperrors.ErrorResponse
 //	@Router			/cars/{id} [delete]
 //
 // @Security BearerAuth
@@ -191,20 +436,51 @@ func (ch *CarHandler) DeleteCarHandler(c *gin.Context) {
 	defer span.End()
 	id := c.Param("id")
 
-	deletedCar, err := ch.carService.DeleteCar(ctx, id)
+	version, err := middleware.IfMatchVersion(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
-		log.Printf("Error deleting car: %v", err)
+		c.Error(err)
 		return
 	}
-	if deletedCar.ID.String() == "" {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Car not found"})
+
+	deletedCar, err := ch.carService.DeleteCar(ctx, id, version)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 	body, err := json.Marshal(deletedCar)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		log.Printf("Error marshalling deleted car data: %v", err)
+		c.Error(err)
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json", body)
+}
+
+// RestoreCarHandler godoc
+//
+//	@Summary		Restore car
+//	@Description	restore a soft deleted car whose engine is not deleted
+//	@Tags			cars
+//	@Param			id	path		string	true	"Car ID"
+//	@Success		200	{object}	models.Car
+//	@Failure		404	{object}	apperrors.ErrorResponse
+//	@Failure		422	{object}	apperrors.ErrorResponse
+//	@Router			/cars/{id}/restore [post]
+//
+// @Security BearerAuth
+func (ch *CarHandler) RestoreCarHandler(c *gin.Context) {
+	ctx, span := otel.Tracer("carservice").Start(c.Request.Context(), "RestoreCarHandler")
+	defer span.End()
+	id := c.Param("id")
+
+	restoredCar, err := ch.carService.RestoreCar(ctx, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	body, err := json.Marshal(restoredCar)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -0,0 +1,261 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/Tushar456/go-carzone/apperrors"
+	"github.com/Tushar456/go-carzone/models"
+	"github.com/Tushar456/go-carzone/spreadsheet"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+)
+
+// maxImportSize caps the size of an uploaded spreadsheet.
+const maxImportSize = 32 << 20
+
+// ExportCarsHandler godoc
+//
+//	@Summary		Export cars
+//	@Description	stream the cars matching the filters as CSV or XLSX, with the engine columns flattened into each row
+//	@Tags			cars
+//	@Produce		text/csv
+//	@Produce		application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+//	@Param			format			query		string	false	"File format"	Enums(csv, xlsx)
+//	@Param			brand			query		string	false	"Brand"
+//	@Param			fuel_type		query		string	false	"Fuel type"	Enums(Petrol, Diesel, Electric, Hybrid)
+//	@Param			year_from		query		int		false	"Minimum year"
+//	@Param			year_to			query		int		false	"Maximum year"
+//	@Param			price_min		query		number	false	"Minimum price, in the currency given by currency"
+//	@Param			price_max		query		number	false	"Maximum price, in the currency given by currency"
+//	@Param			currency		query		string	false	"Only cars priced in this ISO 4217 currency"
+//	@Param			engine_id		query		string	false	"Engine ID"
+//	@Param			sort_by			query		string	false	"Sort column; price requires currency"	Enums(id, name, year, brand, fuel_type, engine_id, price, created_at, updated_at)
+//	@Param			sort_order		query		string	false	"Sort order"	Enums(asc, desc)
+//	@Param			include_deleted	query		bool	false	"Include soft deleted cars (admins only)"
+//	@Success		200				{file}		file
+//	@Failure		400				{object}	apperrors.ErrorResponse
+//	@Failure		403				{object}	apperrors.ErrorResponse
+//	@Failure		422				{object}	apperrors.ErrorResponse
+//	@Router			/cars/export [get]
+//
+// @Security BearerAuth
+func (ch *CarHandler) ExportCarsHandler(c *gin.Context) {
+	ctx, span := otel.Tracer("carservice").Start(c.Request.Context(), "ExportCarsHandler")
+	defer span.End()
+
+	format, err := spreadsheet.ValidateFormat(c.Query("format"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var filter models.CarFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Error(apperrors.BadRequest(err.Error()))
+		return
+	}
+	if filter.IncludeDeleted && c.GetString("role") != models.RoleAdmin {
+		c.Error(apperrors.Forbidden("only admins can export deleted cars"))
+		return
+	}
+
+	// Nothing is sent until the first car is found, so that an invalid
+	// filter still gets a JSON error response.
+	var writer spreadsheet.Writer
+	start := func() error {
+		c.Header("Content-Type", spreadsheet.ContentType(format))
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="cars.%s"`, format))
+		c.Status(http.StatusOK)
+
+		writer, err = spreadsheet.NewWriter(format, c.Writer, "cars")
+		if err != nil {
+			return err
+		}
+		return writer.WriteRow(models.CarExportHeader)
+	}
+
+	exported := 0
+	err = ch.carService.ExportCars(ctx, &filter, func(car *models.Car) error {
+		if writer == nil {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		exported++
+		return writer.WriteRow(car.ExportRow())
+	})
+	if err == nil && writer == nil {
+		err = start()
+	}
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	ch.logger.InfoContext(ctx, "Exported cars", "format", format, "cars", exported)
+}
+
+// ImportCarsHandler godoc
+//
+//	@Summary		Import cars
+//	@Description	create cars from the rows of a CSV or XLSX file whose first row is a header; columns are matched to car fields by name or through mapping
+//	@Tags			cars
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Produce		text/csv
+//	@Param			file	formData	file	true	"CSV or XLSX file"
+//	@Param			mapping	formData	string	false	"JSON object mapping column headers to car fields, e.g. {\"Model\":\"name\"}"
+//	@Param			format	query		string	false	"File format, taken from the file name when omitted"	Enums(csv, xlsx)
+//	@Param			mode	query		string	false	"atomic imports all rows or none, best_effort (default) imports the valid rows"	Enums(atomic, best_effort)
+//	@Param			dry_run	query		bool	false	"Validate the rows without importing them"
+//	@Param			report	query		string	false	"Respond with the rejected rows and their errors as a file instead of JSON"	Enums(csv, xlsx)
+//	@Success		200		{object}	models.ImportResult
+//	@Success		207		{object}	models.ImportResult
+//	@Failure		400		{object}	apperrors.ErrorResponse
+//	@Failure		422		{object}	apperrors.ErrorResponse
+//	@Router			/cars/import [post]
+//
+// @Security BearerAuth
+func (ch *CarHandler) ImportCarsHandler(c *gin.Context) {
+	ctx, span := otel.Tracer("carservice").Start(c.Request.Context(), "ImportCarsHandler")
+	defer span.End()
+
+	var options models.BulkOptions
+	if err := c.ShouldBindQuery(&options); err != nil {
+		c.Error(apperrors.BadRequest(err.Error()))
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.Error(apperrors.BadRequest("file is required: " + err.Error()))
+		return
+	}
+	if fileHeader.Size > maxImportSize {
+		c.Error(apperrors.BadRequest("file is too large"))
+		return
+	}
+
+	formatName := c.Query("format")
+	if formatName == "" {
+		formatName = strings.TrimPrefix(strings.ToLower(filepath.Ext(fileHeader.Filename)), ".")
+	}
+	format, err := spreadsheet.ValidateFormat(formatName)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// The report format is checked before anything is imported, so that a
+	// bad one cannot fail the request after the cars have been written.
+	var reportFormat string
+	if raw := c.Query("report"); raw != "" {
+		if reportFormat, err = spreadsheet.ValidateFormat(raw); err != nil {
+			c.Error(apperrors.Validation("report", "report must be one of csv xlsx"))
+			return
+		}
+	}
+
+	var mapping map[string]string
+	if raw := c.PostForm("mapping"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &mapping); err != nil {
+			c.Error(apperrors.Validation("mapping", "mapping must be a JSON object of strings"))
+			return
+		}
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	defer file.Close()
+
+	rows, err := spreadsheet.ReadAll(format, io.LimitReader(file, maxImportSize))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	ch.logger.DebugContext(ctx, "Read import file", "file", fileHeader.Filename, "format", format, "size", fileHeader.Size, "rows", len(rows))
+
+	result, err := ch.carService.ImportCars(ctx, options, rows, mapping)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if reportFormat != "" {
+		writeImportReport(c, reportFormat, rows, result)
+		return
+	}
+
+	status := http.StatusOK
+	if result.Rejected > 0 {
+		status = http.StatusMultiStatus
+	}
+	c.JSON(status, result)
+}
+
+// writeImportReport responds with the rejected rows as they were uploaded,
+// preceded by their line number and followed by the reason they were rejected,
+// in a format already checked with spreadsheet.ValidateFormat.
+func writeImportReport(c *gin.Context, format string, rows [][]string, result *models.ImportResult) {
+	var buf bytes.Buffer
+	writer, err := spreadsheet.NewWriter(format, &buf, "rejected")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	header := []interface{}{"row"}
+	for _, column := range rows[0] {
+		header = append(header, column)
+	}
+	if err := writer.WriteRow(append(header, "error")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	for _, row := range result.Rows {
+		if row.Error == nil {
+			continue
+		}
+		record := []interface{}{row.Row}
+		for _, cell := range rows[row.Row-1] {
+			record = append(record, cell)
+		}
+		for len(record) < len(header) {
+			record = append(record, "")
+		}
+		if err := writer.WriteRow(append(record, importErrorMessage(row.Error))); err != nil {
+			c.Error(err)
+			return
+		}
+	}
+	if err := writer.Close(); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="rejected-cars.%s"`, format))
+	c.Data(http.StatusOK, spreadsheet.ContentType(format), buf.Bytes())
+}
+
+func importErrorMessage(body *apperrors.ErrorBody) string {
+	if len(body.Errors) == 0 {
+		return body.Message
+	}
+	messages := make([]string, len(body.Errors))
+	for i, fe := range body.Errors {
+		messages[i] = fe.Message
+	}
+	return strings.Join(messages, "; ")
+}
@@ -2,9 +2,11 @@ package handler
 
 import (
 	"encoding/json"
-	"log"
+	"io"
 	"net/http"
 
+	"github.com/Tushar456/go-carzone/apperrors"
+	"github.com/Tushar456/go-carzone/middleware"
 	"github.com/Tushar456/go-carzone/models"
 	"github.com/Tushar456/go-carzone/service"
 	"github.com/gin-gonic/gin"
@@ -25,9 +27,12 @@ func NewEngineHandler(engineService service.EngineServiceInterface) *EngineHandl
 // @Summary      Get engine by ID
 // @Description  get engine by ID
 // @Tags         engines
-// @Param        id   path      string  true  "Engine ID"
+// @Param        id             path      string  true   "Engine ID"
+// @Param        If-None-Match  header    string  false  "ETag of the cached engine"
 // @Success      200  {object}  models.Engine
-// @Failure      404  {object}  map[string]string
+// @Header       200  {string}  ETag  "Version of the engine"
+// @Success      304
+// @Failure      404  {object}  apperrors.ErrorResponse
 // @Router       /engines/{id} [get]
 // @Security     BearerAuth
 func (eh *EngineHandler) GetEngineByIdHandler(c *gin.Context) {
@@ -35,18 +40,15 @@ func (eh *EngineHandler) GetEngineByIdHandler(c *gin.Context) {
 	id := c.Param("id")
 	engine, err := eh.engineService.GetEngineById(ctx, id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
-		log.Printf("Error fetching engine by ID: %v", err)
+		c.Error(err)
 		return
 	}
-	if engine.EngineID.String() == "" {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Engine not found"})
+	if middleware.NotModified(c, engine.Version) {
 		return
 	}
 	body, err := json.Marshal(engine)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		log.Printf("Error marshalling engine data: %v", err)
+		c.Error(err)
 		return
 	}
 	c.Data(http.StatusOK, "application/json", body)
@@ -61,7 +63,8 @@ func (eh *EngineHandler) GetEngineByIdHandler(c *gin.Context) {
 // @Produce      json
 // @Param        engine  body      models.EngineRequest  true  "Engine Request"
 // @Success      201     {object}  models.Engine
-// @Failure      400     {object}  map[string]string
+// @Failure      400     {object}  apperrors.ErrorResponse
+// @Failure      422     {object}  apperrors.ErrorResponse
 // @Router       /engines [post]
 // @Security     BearerAuth
 func (eh *EngineHandler) CreateEngineHandler(c *gin.Context) {
@@ -72,21 +75,19 @@ func (eh *EngineHandler) CreateEngineHandler(c *gin.Context) {
 	var engineRequest models.EngineRequest
 	err := json.NewDecoder(c.Request.Body).Decode(&engineRequest)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
-		log.Printf("Error decoding engine request: %v", err)
+		c.Error(apperrors.BadRequest(err.Error()))
 		return
 	}
 
 	createdEngine, err := eh.engineService.CreateEngine(ctx, &engineRequest)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
-		log.Printf("Error creating engine: %v", err)
+		c.Error(err)
 		return
 	}
+	c.Header("ETag", middleware.ETag(createdEngine.Version))
 	body, err := json.Marshal(createdEngine)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		log.Printf("Error marshalling created engine data: %v", err)
+		c.Error(err)
 		return
 	}
 	c.Data(http.StatusCreated, "application/json", body)
@@ -99,10 +100,16 @@ func (eh *EngineHandler) CreateEngineHandler(c *gin.Context) {
 // @Tags         engines
 // @Accept       json
 // @Produce      json
-// @Param        id      path      string              true  "Engine ID"
-// @Param        engine  body      models.EngineRequest  true  "Engine Request"
+// @Param        id        path      string                true   "Engine ID"
+// @Param        If-Match  header    string                false  "ETag the engine must still have"
+// @Param        engine    body      models.EngineRequest  true   "Engine Request"
 // @Success      200     {object}  models.Engine
-// @Failure      400     {object}  map[string]string
+// @Header       200     {string}  ETag  "Version of the updated engine"
+// @Failure      400     {object}  apperrors.ErrorResponse
+// @Failure      404     {object}  apperrors.ErrorResponse
+// @Failure      412     {object}  apperrors.ErrorResponse
+// @Failure      422     {object}  apperrors.ErrorResponse
+// @Router       /engines/{id} [put]
 // @Security     BearerAuth
 func (eh *EngineHandler) UpdateEngineHandler(c *gin.Context) {
 
@@ -113,21 +120,76 @@ func (eh *EngineHandler) UpdateEngineHandler(c *gin.Context) {
 	id := c.Param("id")
 	err := json.NewDecoder(c.Request.Body).Decode(&engineRequest)
 	if err != nil {
+		c.Error(apperrors.BadRequest(err.Error()))
+		return
+	}
 
-		log.Printf("Error decoding engine request: %v", err)
+	version, err := middleware.IfMatchVersion(c)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	updatedEngine, err := eh.engineService.UpdateEngine(ctx, id, &engineRequest)
+	updatedEngine, err := eh.engineService.UpdateEngine(ctx, id, &engineRequest, version)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
-		log.Printf("Error updating engine: %v", err)
+		c.Error(err)
 		return
 	}
+	c.Header("ETag", middleware.ETag(updatedEngine.Version))
 	body, err := json.Marshal(updatedEngine)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		log.Printf("Error marshalling updated engine data: %v", err)
+		c.Error(err)
+		return
+	}
+	c.Data(http.StatusOK, "application/json", body)
+}
+
+// PatchEngineHandler godoc
+// @Summary      Patch engine
+// @Description  partially update an engine with a JSON Merge Patch (RFC 7396) or JSON Patch (RFC 6902) applied to its request fields
+// @Tags         engines
+// @Accept       application/merge-patch+json
+// @Accept       application/json-patch+json
+// @Produce      json
+// @Param        id        path      string  true   "Engine ID"
+// @Param        If-Match  header    string  false  "ETag the engine must still have"
+// @Param        patch     body      object  true   "Merge patch object or array of JSON Patch operations"
+// @Success      200  {object}  models.Engine
+// @Header       200  {string}  ETag  "Version of the patched engine"
+// @Failure      400  {object}  apperrors.ErrorResponse
+// @Failure      404  {object}  apperrors.ErrorResponse
+// @Failure      409  {object}  apperrors.ErrorResponse
+// @Failure      412  {object}  apperrors.ErrorResponse
+// @Failure      415  {object}  apperrors.ErrorResponse
+// @Failure      422  {object}  apperrors.ErrorResponse
+// @Router       /engines/{id} [patch]
+// @Security     BearerAuth
+func (eh *EngineHandler) PatchEngineHandler(c *gin.Context) {
+	ctx, span := otel.Tracer("engineservice").Start(c.Request.Context(), "PatchEngineHandler")
+	defer span.End()
+	id := c.Param("id")
+
+	patch, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.Error(apperrors.BadRequest(err.Error()))
+		return
+	}
+
+	version, err := middleware.IfMatchVersion(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	patchedEngine, err := eh.engineService.PatchEngine(ctx, id, c.ContentType(), patch, version)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.Header("ETag", middleware.ETag(patchedEngine.Version))
+	body, err := json.Marshal(patchedEngine)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 	c.Data(http.StatusOK, "application/json", body)
@@ -137,9 +199,17 @@ func (eh *EngineHandler) UpdateEngineHandler(c *gin.Context) {
 // @Summary      Delete engine
 // @Description  delete engine
 // @Tags         engines
-// @Param        id   path      string  true  "Engine ID"
+// @Description  Deleting an engine that cars still use fails with 409 and the
+// @Description  IDs of those cars, unless cascade=reassign moves them to the engine "to".
+// @Param        id        path      string  true   "Engine ID"
+// @Param        If-Match  header    string  false  "ETag the engine must still have"
+// @Param        cascade  query     string  false  "What to do with the cars of the engine"  Enums(reassign)
+// @Param        to       query     string  false  "Engine to reassign the cars to"
 // @Success      200  {object}  models.Engine
-// @Failure      404  {object}  map[string]string
+// @Failure      404  {object}  apperrors.ErrorResponse
+// @Failure      409  {object}  apperrors.ErrorResponse
+// @Failure      412  {object}  apperrors.ErrorResponse
+// @Failure      422  {object}  apperrors.ErrorResponse
 // @Router       /engines/{id} [delete]
 // @Security     BearerAuth
 func (eh *EngineHandler) DeleteEngineHandler(c *gin.Context) {
@@ -147,20 +217,54 @@ func (eh *EngineHandler) DeleteEngineHandler(c *gin.Context) {
 	defer span.End()
 	id := c.Param("id")
 
-	deletedEngine, err := eh.engineService.DeleteEngine(ctx, id)
+	var options models.DeleteEngineOptions
+	if err := c.ShouldBindQuery(&options); err != nil {
+		c.Error(apperrors.BadRequest(err.Error()))
+		return
+	}
+
+	version, err := middleware.IfMatchVersion(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Engine ID"})
-		log.Printf("Error deleting engine: %v", err)
+		c.Error(err)
 		return
 	}
-	if deletedEngine.EngineID.String() == "" {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Engine not found"})
+
+	deletedEngine, err := eh.engineService.DeleteEngine(ctx, id, version, &options)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 	body, err := json.Marshal(deletedEngine)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		log.Printf("Error marshalling deleted engine data: %v", err)
+		c.Error(err)
+		return
+	}
+	c.Data(http.StatusOK, "application/json", body)
+
+}
+
+// RestoreEngineHandler godoc
+// @Summary      Restore engine
+// @Description  restore a soft deleted engine
+// @Tags         engines
+// @Param        id   path      string  true  "Engine ID"
+// @Success      200  {object}  models.Engine
+// @Failure      404  {object}  apperrors.ErrorResponse
+// @Router       /engines/{id}/restore [post]
+// @Security     BearerAuth
+func (eh *EngineHandler) RestoreEngineHandler(c *gin.Context) {
+	ctx, span := otel.Tracer("engineservice").Start(c.Request.Context(), "RestoreEngineHandler")
+	defer span.End()
+	id := c.Param("id")
+
+	restoredEngine, err := eh.engineService.RestoreEngine(ctx, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	body, err := json.Marshal(restoredEngine)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 	c.Data(http.StatusOK, "application/json", body)
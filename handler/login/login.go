@@ -1,69 +1,291 @@
 package handler
 
 import (
+	"errors"
+	"log/slog"
 	"net/http"
 	"os"
 	"strconv"
 	"time"
 
+	"github.com/Tushar456/go-carzone/apperrors"
+	"github.com/Tushar456/go-carzone/auth"
+	"github.com/Tushar456/go-carzone/middleware"
 	"github.com/Tushar456/go-carzone/models"
+	"github.com/Tushar456/go-carzone/service"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
 	"go.opentelemetry.io/otel"
 )
 
+type AuthHandler struct {
+	userService  service.UserServiceInterface
+	tokenService service.TokenServiceInterface
+	keys         *auth.KeySet
+	logger       *slog.Logger
+}
+
+func NewAuthHandler(userService service.UserServiceInterface, tokenService service.TokenServiceInterface, keys *auth.KeySet, logger *slog.Logger) *AuthHandler {
+	return &AuthHandler{
+		userService:  userService,
+		tokenService: tokenService,
+		keys:         keys,
+		logger:       logger,
+	}
+}
+
 // LoginHandler godoc
 // @Summary      Login
-// @Description  Authenticates user and returns a JWT token
+// @Description  Authenticates user and returns a JWT access token and a refresh token
 // @Tags         auth
 // @Accept       json
 // @Produce      json
 // @Param        credentials  body      models.Credentials  true  "User credentials"
-// @Success      200  {object}  map[string]string
-// @Failure      400  {object}  map[string]string
-// @Failure      401  {object}  map[string]string
+// @Success      200  {object}  models.TokenResponse
+// @Failure      400  {object}  apperrors.ErrorResponse
+// @Failure      401  {object}  apperrors.ErrorResponse
 // @Router       /login [post]
-func LoginHandler(c *gin.Context) {
-	_, span := otel.Tracer("loginservice").Start(c.Request.Context(), "LoginHandler")
+func (ah *AuthHandler) LoginHandler(c *gin.Context) {
+	ctx, span := otel.Tracer("loginservice").Start(c.Request.Context(), "LoginHandler")
 	defer span.End()
 
 	var credentials models.Credentials
 	if err := c.ShouldBindJSON(&credentials); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(apperrors.BadRequest(err.Error()))
+		return
+	}
+
+	user, err := ah.userService.Authenticate(ctx, &credentials)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	token, err := GenerateToken(ah.keys, user.Username, user.Role)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	refreshToken, err := ah.tokenService.IssueRefreshToken(ctx, user)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	ah.logger.InfoContext(ctx, "Logged in", "username", user.Username, "role", user.Role)
+
+	c.JSON(http.StatusOK, models.TokenResponse{Token: token, RefreshToken: refreshToken})
+
+}
+
+// RefreshHandler godoc
+// @Summary      Refresh tokens
+// @Description  Exchanges a refresh token for a new access token and a new refresh token. Each refresh token can only be used once; reusing one revokes the whole session.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.RefreshRequest  true  "Refresh token"
+// @Success      200      {object}  models.TokenResponse
+// @Failure      400      {object}  apperrors.ErrorResponse
+// @Failure      401      {object}  apperrors.ErrorResponse
+// @Router       /auth/refresh [post]
+func (ah *AuthHandler) RefreshHandler(c *gin.Context) {
+	ctx, span := otel.Tracer("loginservice").Start(c.Request.Context(), "RefreshHandler")
+	defer span.End()
+
+	var request models.RefreshRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.Error(apperrors.BadRequest(err.Error()))
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		c.Error(err)
+		return
+	}
+
+	user, refreshToken, err := ah.tokenService.RotateRefreshToken(ctx, request.RefreshToken)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	token, err := GenerateToken(ah.keys, user.Username, user.Role)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.TokenResponse{Token: token, RefreshToken: refreshToken})
+}
+
+// LogoutHandler godoc
+// @Summary      Logout
+// @Description  Revokes the access token used for this request and, when given, the session of the refresh token
+// @Tags         auth
+// @Accept       json
+// @Param        request  body  models.RefreshRequest  false  "Refresh token"
+// @Success      204
+// @Failure      401  {object}  apperrors.ErrorResponse
+// @Router       /auth/logout [post]
+// @Security     BearerAuth
+func (ah *AuthHandler) LogoutHandler(c *gin.Context) {
+	ctx, span := otel.Tracer("loginservice").Start(c.Request.Context(), "LogoutHandler")
+	defer span.End()
+
+	claims := c.MustGet("claims").(*middleware.Claims)
+
+	var request models.RefreshRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.Error(apperrors.BadRequest(err.Error()))
+			return
+		}
+	}
+
+	if request.RefreshToken != "" {
+		if err := ah.tokenService.RevokeRefreshToken(ctx, request.RefreshToken); err != nil && !errors.Is(err, service.ErrInvalidRefreshToken) {
+			c.Error(err)
+			return
+		}
+	}
+
+	if err := ah.tokenService.RevokeAccessToken(ctx, claims.Id, time.Unix(claims.ExpiresAt, 0)); err != nil {
+		c.Error(err)
 		return
 	}
-	if credentials.Username != "admin" || credentials.Password != "password" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+	ah.logger.InfoContext(ctx, "Logged out", "username", claims.Subject)
+
+	c.Status(http.StatusNoContent)
+}
+
+// RegisterHandler godoc
+// @Summary      Register
+// @Description  Creates a new user account
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        user  body      models.RegisterRequest  true  "New user"
+// @Success      201   {object}  models.User
+// @Failure      400   {object}  apperrors.ErrorResponse
+// @Failure      409   {object}  apperrors.ErrorResponse
+// @Router       /register [post]
+func (ah *AuthHandler) RegisterHandler(c *gin.Context) {
+	ctx, span := otel.Tracer("loginservice").Start(c.Request.Context(), "RegisterHandler")
+	defer span.End()
+
+	var request models.RegisterRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.Error(apperrors.BadRequest(err.Error()))
 		return
 	}
 
-	token, err := GenerateToken(credentials.Username)
+	user, err := ah.userService.Register(ctx, &request)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, user)
+}
+
+// ChangePasswordHandler godoc
+// @Summary      Change password
+// @Description  Changes the password of the authenticated user and revokes their refresh tokens
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body  models.ChangePasswordRequest  true  "Current and new password"
+// @Success      204
+// @Failure      400  {object}  apperrors.ErrorResponse
+// @Failure      401  {object}  apperrors.ErrorResponse
+// @Router       /users/me/password [put]
+// @Security     BearerAuth
+func (ah *AuthHandler) ChangePasswordHandler(c *gin.Context) {
+	ctx, span := otel.Tracer("loginservice").Start(c.Request.Context(), "ChangePasswordHandler")
+	defer span.End()
+
+	var request models.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.Error(apperrors.BadRequest(err.Error()))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"token": token})
+	if err := ah.userService.ChangePassword(ctx, c.GetString("username"), &request); err != nil {
+		c.Error(err)
+		return
+	}
 
+	c.Status(http.StatusNoContent)
 }
 
-func GenerateToken(username string) (string, error) {
+// UpdateRoleHandler godoc
+// @Summary      Update user role
+// @Description  Grants a role to a user and revokes their refresh tokens. Admin only.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        username  path      string                    true  "Username"
+// @Param        request   body      models.UpdateRoleRequest  true  "New role"
+// @Success      200       {object}  models.User
+// @Failure      400       {object}  apperrors.ErrorResponse
+// @Failure      403       {object}  apperrors.ErrorResponse
+// @Failure      404       {object}  apperrors.ErrorResponse
+// @Router       /users/{username}/role [put]
+// @Security     BearerAuth
+func (ah *AuthHandler) UpdateRoleHandler(c *gin.Context) {
+	ctx, span := otel.Tracer("loginservice").Start(c.Request.Context(), "UpdateRoleHandler")
+	defer span.End()
 
-	jwtSecretKey := os.Getenv("JWT_SECRET")
-	if jwtSecretKey == "" {
-		return "", jwt.ErrInvalidKey
+	var request models.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.Error(apperrors.BadRequest(err.Error()))
+		return
 	}
+
+	user, err := ah.userService.UpdateRole(ctx, c.Param("username"), &request)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// JWKSHandler godoc
+// @Summary      JSON Web Key Set
+// @Description  Public keys that verify carzone access tokens, selected by the kid token header
+// @Tags         auth
+// @Produce      json
+// @Success      200  {object}  auth.JWKS
+// @Router       /.well-known/jwks.json [get]
+func (ah *AuthHandler) JWKSHandler(c *gin.Context) {
+	_, span := otel.Tracer("loginservice").Start(c.Request.Context(), "JWKSHandler")
+	defer span.End()
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, ah.keys.JWKS())
+}
+
+func GenerateToken(keys *auth.KeySet, username string, role string) (string, error) {
+
 	expiryTime, err := strconv.Atoi(os.Getenv("JWT_EXPIRY_TIME"))
 	if err != nil || expiryTime <= 0 {
 		expiryTime = 24 // default to 24 hours if not set or invalid
 	}
-	token := jwt.StandardClaims{
-		ExpiresAt: time.Now().Add(time.Minute * time.Duration(expiryTime)).Unix(),
-		IssuedAt:  time.Now().Unix(),
-		Subject:   username,
+	token := middleware.Claims{
+		UserName: username,
+		Role:     role,
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: time.Now().Add(time.Minute * time.Duration(expiryTime)).Unix(),
+			Id:        uuid.NewString(),
+			IssuedAt:  time.Now().Unix(),
+			Subject:   username,
+		},
 	}
 
-	tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, token).SignedString([]byte(jwtSecretKey))
+	tokenString, err := keys.Sign(token)
 
 	if err != nil {
 		return "", err
@@ -0,0 +1,217 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Tushar456/go-carzone/apperrors"
+	"github.com/Tushar456/go-carzone/models"
+	"github.com/Tushar456/go-carzone/service"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+)
+
+type WebhookHandler struct {
+	webhookService service.WebhookServiceInterface
+}
+
+func NewWebhookHandler(webhookService service.WebhookServiceInterface) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+// ListSubscriptionsHandler godoc
+// @Summary      List webhook subscriptions
+// @Description  list the webhook subscriptions, oldest first
+// @Tags         webhooks
+// @Produce      json
+// @Success      200  {array}   models.WebhookSubscription
+// @Failure      403  {object}  apperrors.ErrorResponse
+// @Router       /webhooks [get]
+// @Security     BearerAuth
+func (wh *WebhookHandler) ListSubscriptionsHandler(c *gin.Context) {
+	ctx, span := otel.Tracer("webhookservice").Start(c.Request.Context(), "ListSubscriptionsHandler")
+	defer span.End()
+
+	subscriptions, err := wh.webhookService.ListSubscriptions(ctx)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, subscriptions)
+}
+
+// GetSubscriptionByIdHandler godoc
+// @Summary      Get webhook subscription by ID
+// @Description  get webhook subscription by ID; the secret is not returned
+// @Tags         webhooks
+// @Produce      json
+// @Param        id   path      string  true  "Subscription ID"
+// @Success      200  {object}  models.WebhookSubscription
+// @Failure      400  {object}  apperrors.ErrorResponse
+// @Failure      404  {object}  apperrors.ErrorResponse
+// @Router       /webhooks/{id} [get]
+// @Security     BearerAuth
+func (wh *WebhookHandler) GetSubscriptionByIdHandler(c *gin.Context) {
+	ctx, span := otel.Tracer("webhookservice").Start(c.Request.Context(), "GetSubscriptionByIdHandler")
+	defer span.End()
+
+	subscription, err := wh.webhookService.GetSubscriptionById(ctx, c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+// CreateSubscriptionHandler godoc
+// @Summary      Create webhook subscription
+// @Description  subscribe a URL to events, optionally limited to some types and brands; deliveries are signed with the secret returned here, and only here
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        subscription  body      models.WebhookSubscriptionRequest  true  "Subscription Request"
+// @Success      201           {object}  models.CreatedWebhookSubscription
+// @Failure      400           {object}  apperrors.ErrorResponse
+// @Failure      409           {object}  apperrors.ErrorResponse
+// @Failure      422           {object}  apperrors.ErrorResponse
+// @Router       /webhooks [post]
+// @Security     BearerAuth
+func (wh *WebhookHandler) CreateSubscriptionHandler(c *gin.Context) {
+	ctx, span := otel.Tracer("webhookservice").Start(c.Request.Context(), "CreateSubscriptionHandler")
+	defer span.End()
+
+	var request models.WebhookSubscriptionRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&request); err != nil {
+		c.Error(apperrors.BadRequest(err.Error()))
+		return
+	}
+
+	subscription, err := wh.webhookService.CreateSubscription(ctx, &request)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, subscription)
+}
+
+// UpdateSubscriptionHandler godoc
+// @Summary      Update webhook subscription
+// @Description  replace a subscription's settings; the secret and active flag are kept when left out
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        id            path      string                             true  "Subscription ID"
+// @Param        subscription  body      models.WebhookSubscriptionRequest  true  "Subscription Request"
+// @Success      200           {object}  models.WebhookSubscription
+// @Failure      400           {object}  apperrors.ErrorResponse
+// @Failure      404           {object}  apperrors.ErrorResponse
+// @Failure      409           {object}  apperrors.ErrorResponse
+// @Failure      422           {object}  apperrors.ErrorResponse
+// @Router       /webhooks/{id} [put]
+// @Security     BearerAuth
+func (wh *WebhookHandler) UpdateSubscriptionHandler(c *gin.Context) {
+	ctx, span := otel.Tracer("webhookservice").Start(c.Request.Context(), "UpdateSubscriptionHandler")
+	defer span.End()
+
+	var request models.WebhookSubscriptionRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&request); err != nil {
+		c.Error(apperrors.BadRequest(err.Error()))
+		return
+	}
+
+	subscription, err := wh.webhookService.UpdateSubscription(ctx, c.Param("id"), &request)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+// DeleteSubscriptionHandler godoc
+// @Summary      Delete webhook subscription
+// @Description  delete a subscription and its delivery log
+// @Tags         webhooks
+// @Produce      json
+// @Param        id   path      string  true  "Subscription ID"
+// @Success      200  {object}  models.WebhookSubscription
+// @Failure      400  {object}  apperrors.ErrorResponse
+// @Failure      404  {object}  apperrors.ErrorResponse
+// @Router       /webhooks/{id} [delete]
+// @Security     BearerAuth
+func (wh *WebhookHandler) DeleteSubscriptionHandler(c *gin.Context) {
+	ctx, span := otel.Tracer("webhookservice").Start(c.Request.Context(), "DeleteSubscriptionHandler")
+	defer span.End()
+
+	subscription, err := wh.webhookService.DeleteSubscription(ctx, c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+// ListDeliveriesHandler godoc
+// @Summary      List webhook deliveries
+// @Description  list the deliveries to a subscription with the outcome of their last attempt, most recent first
+// @Tags         webhooks
+// @Produce      json
+// @Param        id      path      string  true   "Subscription ID"
+// @Param        status  query     string  false  "Delivery status"  Enums(pending, delivered, dead)
+// @Param        limit   query     int     false  "Page size (max 100)"
+// @Param        offset  query     int     false  "Number of deliveries to skip"
+// @Success      200  {object}  models.Page[models.WebhookDelivery]
+// @Failure      400  {object}  apperrors.ErrorResponse
+// @Failure      404  {object}  apperrors.ErrorResponse
+// @Failure      422  {object}  apperrors.ErrorResponse
+// @Router       /webhooks/{id}/deliveries [get]
+// @Security     BearerAuth
+func (wh *WebhookHandler) ListDeliveriesHandler(c *gin.Context) {
+	ctx, span := otel.Tracer("webhookservice").Start(c.Request.Context(), "ListDeliveriesHandler")
+	defer span.End()
+
+	var filter models.WebhookDeliveryFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Error(apperrors.BadRequest(err.Error()))
+		return
+	}
+
+	page, err := wh.webhookService.ListDeliveries(ctx, c.Param("id"), &filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// RedeliverHandler godoc
+// @Summary      Redeliver webhook delivery
+// @Description  queue a delivery to be sent again at once, with a fresh allowance of attempts; dead deliveries come back to life
+// @Tags         webhooks
+// @Produce      json
+// @Param        id           path      string  true  "Subscription ID"
+// @Param        delivery_id  path      string  true  "Delivery ID"
+// @Success      202  {object}  models.WebhookDelivery
+// @Failure      400  {object}  apperrors.ErrorResponse
+// @Failure      404  {object}  apperrors.ErrorResponse
+// @Router       /webhooks/{id}/deliveries/{delivery_id}/redeliver [post]
+// @Security     BearerAuth
+func (wh *WebhookHandler) RedeliverHandler(c *gin.Context) {
+	ctx, span := otel.Tracer("webhookservice").Start(c.Request.Context(), "RedeliverHandler")
+	defer span.End()
+
+	delivery, err := wh.webhookService.Redeliver(ctx, c.Param("id"), c.Param("delivery_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, delivery)
+}
@@ -0,0 +1,66 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// SlowQueryThreshold is how long a query may take before it is logged as
+// slow.
+const SlowQueryThreshold = 200 * time.Millisecond
+
+// GORM adapts logger for GORM. Every statement is logged at debug level and
+// slow ones at warn. Failed statements are logged at debug only: most are
+// constraint violations the repositories turn into client errors, and the
+// rest reach the error middleware, which logs them with the request.
+func GORM(logger *slog.Logger) gormlogger.Interface {
+	return &gormLogger{logger: logger}
+}
+
+type gormLogger struct {
+	logger *slog.Logger
+}
+
+// LogMode is ignored; the level is the logger's.
+func (l *gormLogger) LogMode(gormlogger.LogLevel) gormlogger.Interface {
+	return l
+}
+
+func (l *gormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	l.logger.InfoContext(ctx, fmt.Sprintf(msg, args...))
+}
+
+func (l *gormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	l.logger.WarnContext(ctx, fmt.Sprintf(msg, args...))
+}
+
+func (l *gormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	l.logger.ErrorContext(ctx, fmt.Sprintf(msg, args...))
+}
+
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	elapsed := time.Since(begin)
+
+	level, msg := slog.LevelDebug, "query"
+	if elapsed > SlowQueryThreshold {
+		level, msg = slog.LevelWarn, "slow query"
+	}
+	if !l.logger.Enabled(ctx, level) {
+		return
+	}
+
+	sql, rows := fc()
+	attrs := []slog.Attr{
+		slog.String("sql", sql),
+		slog.Int64("rows", rows),
+		slog.Float64("duration_ms", float64(elapsed.Microseconds())/1000),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	l.logger.LogAttrs(ctx, level, msg, attrs...)
+}
@@ -0,0 +1,133 @@
+// Package logging builds the structured loggers the server writes with.
+// Records are JSON lines carrying the trace and span IDs of the request
+// context they are logged with, along with its request ID and user, so
+// that a log line can be found from a trace and the other way round. Each
+// package gets its own logger, whose level can be set separately.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/Tushar456/go-carzone/auth"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// Config holds the level of every logger and the levels of the packages
+// that differ from it.
+type Config struct {
+	Level  slog.Level
+	Levels map[string]slog.Level
+}
+
+// ConfigFromEnv reads LOG_LEVEL, the level of every logger, info by
+// default, and LOG_LEVELS, a comma separated list of package=level pairs
+// overriding it, such as "carService=debug,driver=warn".
+func ConfigFromEnv() (Config, error) {
+	config := Config{Level: slog.LevelInfo, Levels: map[string]slog.Level{}}
+
+	if value := os.Getenv("LOG_LEVEL"); value != "" {
+		if err := config.Level.UnmarshalText([]byte(value)); err != nil {
+			return Config{}, fmt.Errorf("invalid LOG_LEVEL %q: %w", value, err)
+		}
+	}
+
+	for _, pair := range strings.Split(os.Getenv("LOG_LEVELS"), ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return Config{}, fmt.Errorf("invalid LOG_LEVELS entry %q, expected package=level", pair)
+		}
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(strings.TrimSpace(value))); err != nil {
+			return Config{}, fmt.Errorf("invalid LOG_LEVELS entry %q: %w", pair, err)
+		}
+		config.Levels[strings.TrimSpace(name)] = level
+	}
+	return config, nil
+}
+
+// Loggers hands out the logger of each package. All of them write to the
+// same output.
+type Loggers struct {
+	handler slog.Handler
+	config  Config
+}
+
+func New(w io.Writer, config Config) *Loggers {
+	return &Loggers{
+		// The JSON handler lets everything through; each package's logger
+		// filters by its own level first.
+		handler: slog.NewJSONHandler(w, &slog.HandlerOptions{Level: slog.LevelDebug}),
+		config:  config,
+	}
+}
+
+// For returns the logger of the named package. Its records carry the name
+// as "logger".
+func (l *Loggers) For(name string) *slog.Logger {
+	level, ok := l.config.Levels[name]
+	if !ok {
+		level = l.config.Level
+	}
+	return slog.New(&contextHandler{
+		next:  l.handler.WithAttrs([]slog.Attr{slog.String("logger", name)}),
+		level: level,
+	})
+}
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the ID of the request it
+// belongs to.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID recorded in ctx by WithRequestID.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// contextHandler filters records by level and adds the correlation fields
+// found in the context they are logged with.
+type contextHandler struct {
+	next  slog.Handler
+	level slog.Level
+}
+
+func (h *contextHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if span := oteltrace.SpanContextFromContext(ctx); span.IsValid() {
+		record.AddAttrs(
+			slog.String("trace_id", span.TraceID().String()),
+			slog.String("span_id", span.SpanID().String()),
+		)
+	}
+	if id := RequestID(ctx); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	if user, ok := auth.User(ctx); ok {
+		record.AddAttrs(slog.String("user", user))
+	}
+	return h.next.Handle(ctx, record)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{next: h.next.WithAttrs(attrs), level: h.level}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{next: h.next.WithGroup(name), level: h.level}
+}
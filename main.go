@@ -2,23 +2,36 @@ package main
 
 import (
 	"context"
-	"database/sql"
-	"fmt"
 	"log"
+	"log/slog"
 	"os"
 	"time"
 
+	"github.com/Tushar456/go-carzone/auth"
 	_ "github.com/Tushar456/go-carzone/docs"
 	"github.com/Tushar456/go-carzone/driver"
+	auditHandler "github.com/Tushar456/go-carzone/handler/audit"
+	brandHandler "github.com/Tushar456/go-carzone/handler/brand"
 	carHandler "github.com/Tushar456/go-carzone/handler/car"
 	engineHandler "github.com/Tushar456/go-carzone/handler/engine"
-	loginHanler "github.com/Tushar456/go-carzone/handler/login"
+	loginHandler "github.com/Tushar456/go-carzone/handler/login"
+	webhookHandler "github.com/Tushar456/go-carzone/handler/webhook"
+	"github.com/Tushar456/go-carzone/logging"
+	"github.com/Tushar456/go-carzone/metrics"
 	"github.com/Tushar456/go-carzone/middleware"
+	"github.com/Tushar456/go-carzone/migrations"
 	"github.com/Tushar456/go-carzone/models"
-	carRepository "github.com/Tushar456/go-carzone/repository/car-repository"
-	engineRepository "github.com/Tushar456/go-carzone/repository/engine-repository"
+	"github.com/Tushar456/go-carzone/outbox"
+	"github.com/Tushar456/go-carzone/repository"
+	"github.com/Tushar456/go-carzone/repository/backend"
+	"github.com/Tushar456/go-carzone/service/auditService"
+	"github.com/Tushar456/go-carzone/service/brandService"
 	"github.com/Tushar456/go-carzone/service/carService"
 	"github.com/Tushar456/go-carzone/service/engineService"
+	"github.com/Tushar456/go-carzone/service/tokenService"
+	"github.com/Tushar456/go-carzone/service/userService"
+	"github.com/Tushar456/go-carzone/service/webhookService"
+	"github.com/Tushar456/go-carzone/webhook"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 	swaggerFiles "github.com/swaggo/files"
@@ -49,6 +62,16 @@ func main() {
 		log.Fatal("Error loading .env file")
 	}
 
+	// LOG_LEVEL sets the level of every package's logger and LOG_LEVELS
+	// overrides it per package, e.g. "carService=debug,driver=warn".
+	logConfig, err := logging.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Error configuring logging: %v", err)
+	}
+	logs := logging.New(os.Stdout, logConfig)
+	logger := logs.For("main")
+	slog.SetDefault(logger)
+
 	traceProvider, err := startTracing()
 	if err != nil {
 		log.Fatalf("Error starting tracing: %v", err)
@@ -62,40 +85,153 @@ func main() {
 
 	otel.SetTracerProvider(traceProvider)
 
-	db, err := driver.InitDB()
+	// DB_DRIVER picks the database: postgres (the default), sqlite for a
+	// single file named by DB_PATH, or memory.
+	dbDriver, err := driver.Name()
+	if err != nil {
+		log.Fatalf("Error initializing DB: %v", err)
+	}
+
+	db, err := driver.InitDB(logs.For("driver"))
 
 	if err != nil {
 		log.Fatalf("Error initializing DB: %v", err)
 	}
 
-	fmt.Println("Migrating database...")
-	err = db.AutoMigrate(&models.Engine{})
+	sqlDB, err := db.DB()
 	if err != nil {
-		log.Fatalf("Error migrating engine table: %v", err)
+		log.Fatalf("Error getting generic DB: %v", err)
 	}
-	err = db.AutoMigrate(&models.Car{})
+
+	if dbDriver == driver.Postgres {
+		migrator, err := migrations.New(sqlDB)
+		if err != nil {
+			log.Fatalf("Error loading migrations: %v", err)
+		}
+
+		if len(os.Args) > 1 && os.Args[1] == "migrate" {
+			if err := runMigrate(migrator, os.Args[2:]); err != nil {
+				log.Fatalf("Error running migrations: %v", err)
+			}
+			return
+		}
+
+		// Replicas may all start at once; the migrator serialises them with an
+		// advisory lock. Set DB_MIGRATE_ON_START=false to run `carzone migrate up`
+		// as a separate deployment step instead.
+		if os.Getenv("DB_MIGRATE_ON_START") != "false" {
+			logger.Info("Migrating database")
+			if err := migrator.Up(context.Background()); err != nil {
+				log.Fatalf("Error migrating database: %v", err)
+			}
+			logger.Info("Migration successful")
+		}
+	} else {
+		// The SQL migrations are written for Postgres. SQLite databases get
+		// their schema from the models on every start instead.
+		if len(os.Args) > 1 && os.Args[1] == "migrate" {
+			log.Fatalf("Error running migrations: migrations only run on %s, the %s schema is created on start", driver.Postgres, dbDriver)
+		}
+
+		logger.Info("Migrating database", "driver", dbDriver)
+		if err := migrations.AutoMigrate(db); err != nil {
+			log.Fatalf("Error migrating database: %v", err)
+		}
+		logger.Info("Migration successful")
+	}
+
+	// DB_QUERIES=sql serves the hottest car and engine reads with
+	// hand-written SQL instead of GORM.
+	var sqlQueries bool
+	switch queries := os.Getenv("DB_QUERIES"); queries {
+	case "", "gorm":
+	case "sql":
+		sqlQueries = true
+	default:
+		log.Fatalf("Error initializing DB: unknown DB_QUERIES %q, expected gorm or sql", queries)
+	}
+
+	m := metrics.New(logs.For("metrics"))
+	m.RegisterDB(sqlDB)
+
+	repos := backend.New(backend.Config{Driver: dbDriver, SQLQueries: sqlQueries}, db, logs, m)
+	m.RegisterCatalog(repos.Cars, repos.Engines)
+	transactor := repos.Transactor
+
+	auditService := auditService.NewAuditService(repos.Audit, logs.For("auditService"))
+
+	// EXCHANGE_RATES configures the currencies car listings can be converted
+	// into, as CODE=RATE pairs against a common base, e.g. "USD=1,EUR=1.08".
+	exchangeRates, err := models.ParseExchangeRates(os.Getenv("EXCHANGE_RATES"))
 	if err != nil {
-		log.Fatalf("Error migrating car table: %v", err)
+		log.Fatalf("Error reading exchange rates: %v", err)
 	}
-	fmt.Println("Migration successful!")
 
-	// schemaFile := "store/schema.sql"
-	// if err = executeSchemaFile(db, schemaFile); err != nil {
-	// 	log.Fatalf("Error executing schema file: %v", err)
-	// }
+	carService := carService.NewCarService(repos.Cars, transactor, auditService, exchangeRates, logs.For("carService"))
 
-	carRepository := carRepository.NewCarRepository(db)
-	carService := carService.NewCarService(carRepository)
+	engineService := engineService.NewEngineService(repos.Engines, transactor, auditService, logs.For("engineService"))
 
-	engineRepository := engineRepository.NewEngineRepository(db)
-	engineService := engineService.NewEngineService(engineRepository)
+	brandService := brandService.NewBrandService(repos.Brands, transactor, auditService, logs.For("brandService"))
 
-	carHandler := carHandler.NewCarHandler(carService)
+	webhookService := webhookService.NewWebhookService(repos.Webhooks, repos.Brands, transactor, logs.For("webhookService"))
+
+	userService := userService.NewUserService(repos.Users, repos.Tokens, transactor, logs.For("userService"))
+
+	keys, err := auth.LoadKeySet()
+	if err != nil {
+		log.Fatalf("Error loading JWT signing keys: %v", err)
+	}
+
+	tokenService := tokenService.NewTokenService(repos.Tokens, repos.Users, logs.For("tokenService"))
+
+	carHandler := carHandler.NewCarHandler(carService, logs.For("carHandler"))
 	engineHandler := engineHandler.NewEngineHandler(engineService)
+	brandHandler := brandHandler.NewBrandHandler(brandService)
+	auditHandler := auditHandler.NewAuditHandler(auditService)
+	webhookHandler := webhookHandler.NewWebhookHandler(webhookService)
+	authHandler := loginHandler.NewAuthHandler(userService, tokenService, keys, logs.For("loginHandler"))
+
+	if adminUsername := os.Getenv("ADMIN_USERNAME"); adminUsername != "" {
+		if err := userService.EnsureAdmin(context.Background(), adminUsername, os.Getenv("ADMIN_PASSWORD")); err != nil {
+			log.Fatalf("Error creating admin user: %v", err)
+		}
+	}
+
+	purgeRetention, err := softDeleteRetention()
+	if err != nil {
+		log.Fatalf("Error reading soft delete retention: %v", err)
+	}
+	go runPurgeJob(context.Background(), logs.For("purge"), purgeRetention, carService, engineService, tokenService)
 
-	router := gin.Default()
+	sink, err := outboxSink()
+	if err != nil {
+		log.Fatalf("Error configuring the outbox sink: %v", err)
+	}
+	sinks := outbox.Sinks{webhook.NewDispatcher(repos.Webhooks)}
+	if sink != nil {
+		sinks = append(sinks, sink)
+	}
+	// The outbox and the webhooks are always kept in db, so the relay and the
+	// deliverer need not lock the in-memory store while they send.
+	dbTransactor := repository.NewTransactor(db)
+	relay := outbox.NewRelay(repos.Outbox, dbTransactor, sinks)
+	relay.Logger = logs.For("outbox")
+	go relay.Run(context.Background())
+	deliverer := webhook.NewDeliverer(repos.Webhooks, dbTransactor)
+	deliverer.Logger = logs.For("webhook")
+	go deliverer.Run(context.Background())
+
+	router := gin.New()
+
+	router.Use(middleware.Recovery(logs.For("http")))
+
+	// Routes only run the middleware added before them, so scrapes are
+	// neither traced, logged nor counted in the request metrics.
+	router.GET("/metrics", gin.WrapH(m.Handler()))
 
 	router.Use(otelgin.Middleware("carzone"))
+	router.Use(middleware.AccessLog(logs.For("http")))
+	router.Use(middleware.Metrics(m))
 
 	// Middleware to add TraceID to response header
 	router.Use(func(c *gin.Context) {
@@ -106,6 +242,8 @@ func main() {
 		c.Next()
 	})
 
+	router.Use(middleware.ErrorHandler(logs.For("http")))
+
 	// Swagger endpoint
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
@@ -123,68 +261,159 @@ func main() {
 	// router.HandleFunc("/engines/{id}", engineHandler.DeleteEngineHandler).Methods("DELETE")
 
 	router.POST("/login", func(c *gin.Context) {
-		loginHanler.LoginHandler(c)
+		authHandler.LoginHandler(c)
+	})
+	router.POST("/register", func(c *gin.Context) {
+		authHandler.RegisterHandler(c)
+	})
+	router.GET("/.well-known/jwks.json", func(c *gin.Context) {
+		authHandler.JWKSHandler(c)
+	})
+	router.POST("/auth/refresh", func(c *gin.Context) {
+		authHandler.RefreshHandler(c)
+	})
+	router.POST("/auth/logout", middleware.AuthMiddleware(keys, tokenService), func(c *gin.Context) {
+		authHandler.LogoutHandler(c)
 	})
 
-	carRouter := router.Group("/cars").Use(middleware.AuthMiddleware())
+	userRouter := router.Group("/users").Use(middleware.AuthMiddleware(keys, tokenService))
 
-	carRouter.GET("/:id", func(c *gin.Context) {
+	// Roles allowed to call each group of routes.
+	readers := middleware.RequireRoles(models.RoleViewer, models.RoleEditor, models.RoleAdmin)
+	editors := middleware.RequireRoles(models.RoleEditor, models.RoleAdmin)
+	admins := middleware.RequireRoles(models.RoleAdmin)
+
+	userRouter.PUT("/me/password", func(c *gin.Context) {
+		authHandler.ChangePasswordHandler(c)
+	})
+	userRouter.PUT("/:username/role", admins, func(c *gin.Context) {
+		authHandler.UpdateRoleHandler(c)
+	})
+
+	carRouter := router.Group("/cars").Use(middleware.AuthMiddleware(keys, tokenService))
+
+	carRouter.GET("", readers, func(c *gin.Context) {
+		carHandler.ListCarsHandler(c)
+	})
+	carRouter.GET("/search", readers, func(c *gin.Context) {
+		carHandler.SearchCarsHandler(c)
+	})
+	carRouter.GET("/export", readers, func(c *gin.Context) {
+		carHandler.ExportCarsHandler(c)
+	})
+	carRouter.GET("/:id", readers, func(c *gin.Context) {
 		carHandler.GetCarByIdHandler(c)
 	})
-	carRouter.GET("/brand/:brand", func(c *gin.Context) {
+	carRouter.GET("/:id/price-history", readers, func(c *gin.Context) {
+		carHandler.GetCarPriceHistoryHandler(c)
+	})
+	carRouter.GET("/brand/:brand", readers, func(c *gin.Context) {
 		carHandler.GetCarByBrandHandler(c)
 	})
-	carRouter.POST("", func(c *gin.Context) {
+	carRouter.POST("", editors, func(c *gin.Context) {
 		carHandler.CreateCarHandler(c)
 	})
-	carRouter.PUT("/:id", func(c *gin.Context) {
+	carRouter.POST("/import", editors, func(c *gin.Context) {
+		carHandler.ImportCarsHandler(c)
+	})
+	carRouter.POST("/bulk", editors, func(c *gin.Context) {
+		carHandler.BulkCarsHandler(c)
+	})
+	carRouter.PUT("/:id", editors, func(c *gin.Context) {
 		carHandler.UpdateCarHandler(c)
 	})
-	carRouter.DELETE("/:id", func(c *gin.Context) {
+	carRouter.PATCH("/:id", editors, func(c *gin.Context) {
+		carHandler.PatchCarHandler(c)
+	})
+	carRouter.DELETE("/:id", admins, func(c *gin.Context) {
 		carHandler.DeleteCarHandler(c)
 	})
+	carRouter.POST("/:id/restore", admins, func(c *gin.Context) {
+		carHandler.RestoreCarHandler(c)
+	})
+
+	brandRouter := router.Group("/brands").Use(middleware.AuthMiddleware(keys, tokenService))
 
-	engineRouter := router.Group("/engines").Use(middleware.AuthMiddleware())
+	brandRouter.GET("", readers, func(c *gin.Context) {
+		brandHandler.ListBrandsHandler(c)
+	})
+	brandRouter.GET("/resolve", readers, func(c *gin.Context) {
+		brandHandler.ResolveBrandHandler(c)
+	})
+	brandRouter.GET("/:id", readers, func(c *gin.Context) {
+		brandHandler.GetBrandByIdHandler(c)
+	})
+	brandRouter.POST("", editors, func(c *gin.Context) {
+		brandHandler.CreateBrandHandler(c)
+	})
+	brandRouter.PUT("/:id", editors, func(c *gin.Context) {
+		brandHandler.UpdateBrandHandler(c)
+	})
+	brandRouter.DELETE("/:id", admins, func(c *gin.Context) {
+		brandHandler.DeleteBrandHandler(c)
+	})
+
+	auditRouter := router.Group("/audit").Use(middleware.AuthMiddleware(keys, tokenService))
+
+	auditRouter.GET("", admins, func(c *gin.Context) {
+		auditHandler.ListAuditEntriesHandler(c)
+	})
 
-	engineRouter.GET("/:id", func(c *gin.Context) {
+	webhookRouter := router.Group("/webhooks").Use(middleware.AuthMiddleware(keys, tokenService))
+
+	webhookRouter.GET("", admins, func(c *gin.Context) {
+		webhookHandler.ListSubscriptionsHandler(c)
+	})
+	webhookRouter.POST("", admins, func(c *gin.Context) {
+		webhookHandler.CreateSubscriptionHandler(c)
+	})
+	webhookRouter.GET("/:id", admins, func(c *gin.Context) {
+		webhookHandler.GetSubscriptionByIdHandler(c)
+	})
+	webhookRouter.PUT("/:id", admins, func(c *gin.Context) {
+		webhookHandler.UpdateSubscriptionHandler(c)
+	})
+	webhookRouter.DELETE("/:id", admins, func(c *gin.Context) {
+		webhookHandler.DeleteSubscriptionHandler(c)
+	})
+	webhookRouter.GET("/:id/deliveries", admins, func(c *gin.Context) {
+		webhookHandler.ListDeliveriesHandler(c)
+	})
+	webhookRouter.POST("/:id/deliveries/:delivery_id/redeliver", admins, func(c *gin.Context) {
+		webhookHandler.RedeliverHandler(c)
+	})
+
+	engineRouter := router.Group("/engines").Use(middleware.AuthMiddleware(keys, tokenService))
+
+	engineRouter.GET("/:id", readers, func(c *gin.Context) {
 		engineHandler.GetEngineByIdHandler(c)
 	})
-	engineRouter.POST("", func(c *gin.Context) {
+	engineRouter.POST("", editors, func(c *gin.Context) {
 		engineHandler.CreateEngineHandler(c)
 	})
-	engineRouter.PUT("/:id", func(c *gin.Context) {
+	engineRouter.PUT("/:id", editors, func(c *gin.Context) {
 		engineHandler.UpdateEngineHandler(c)
 	})
-	engineRouter.DELETE("/:id", func(c *gin.Context) {
+	engineRouter.PATCH("/:id", editors, func(c *gin.Context) {
+		engineHandler.PatchEngineHandler(c)
+	})
+	engineRouter.DELETE("/:id", admins, func(c *gin.Context) {
 		engineHandler.DeleteEngineHandler(c)
 	})
+	engineRouter.POST("/:id/restore", admins, func(c *gin.Context) {
+		engineHandler.RestoreEngineHandler(c)
+	})
 
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080" // Default port if not specified
 	}
 
-	log.Printf("Server is running on port %s", port)
+	logger.Info("Server is running", "port", port)
 	log.Fatal(router.Run(":" + port))
 
 }
 
-func executeSchemaFile(db *sql.DB, schemaFile string) error {
-	sqlFile, err := os.ReadFile(schemaFile)
-	if err != nil {
-		return err
-	}
-
-	_, err = db.Exec(string(sqlFile))
-
-	if err != nil {
-		return err
-	}
-
-	return nil
-
-}
-
 func startTracing() (*trace.TracerProvider, error) {
 
 	headers := map[string]string{
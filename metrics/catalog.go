@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/Tushar456/go-carzone/repository"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// catalogTimeout bounds the queries made for one scrape.
+const catalogTimeout = 5 * time.Second
+
+var (
+	carsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "catalog", "cars"),
+		"Cars in the catalog, soft deleted ones excluded, by brand.",
+		[]string{"brand"}, nil,
+	)
+	enginesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "catalog", "engines"),
+		"Engines in the catalog, soft deleted ones excluded.",
+		nil, nil,
+	)
+)
+
+// catalogCollector counts the cars and engines when scraped, so the gauges
+// are always current and nothing has to update them as the catalog changes.
+type catalogCollector struct {
+	cars    repository.CarRepositoryInterface
+	engines repository.EngineRepositoryInterface
+}
+
+// RegisterCatalog adds gauges of the number of cars of each brand and of
+// engines, counted through the given repositories on every scrape.
+func (m *Metrics) RegisterCatalog(cars repository.CarRepositoryInterface, engines repository.EngineRepositoryInterface) {
+	m.registry.MustRegister(&catalogCollector{cars: cars, engines: engines})
+}
+
+func (c *catalogCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- carsDesc
+	ch <- enginesDesc
+}
+
+func (c *catalogCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), catalogTimeout)
+	defer cancel()
+
+	if counts, err := c.cars.CountCarsByBrand(ctx); err != nil {
+		ch <- prometheus.NewInvalidMetric(carsDesc, err)
+	} else {
+		for brand, count := range counts {
+			ch <- prometheus.MustNewConstMetric(carsDesc, prometheus.GaugeValue, float64(count), brand)
+		}
+	}
+
+	if count, err := c.engines.CountEngines(ctx); err != nil {
+		ch <- prometheus.NewInvalidMetric(enginesDesc, err)
+	} else {
+		ch <- prometheus.MustNewConstMetric(enginesDesc, prometheus.GaugeValue, float64(count))
+	}
+}
@@ -0,0 +1,96 @@
+// Package metrics collects the server's Prometheus metrics: the rate,
+// errors and duration of HTTP requests by route and status, the latency of
+// every repository method, the database connection pool and a few gauges
+// about the catalog. They are served from a registry of their own rather
+// than the global one, so only what is registered here is exposed.
+package metrics
+
+import (
+	"database/sql"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "carzone"
+
+type Metrics struct {
+	registry *prometheus.Registry
+	logger   *slog.Logger
+
+	requests           *prometheus.CounterVec
+	requestDuration    *prometheus.HistogramVec
+	repositoryDuration *prometheus.HistogramVec
+}
+
+func New(logger *slog.Logger) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		logger:   logger,
+
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "HTTP requests handled, by method, route and status.",
+		}, []string{"method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Time taken to handle HTTP requests, by method, route and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		repositoryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "repository",
+			Name:      "duration_seconds",
+			Help:      "Time taken by repository methods, by repository and method.",
+			// From half a millisecond to about four seconds.
+			Buckets: prometheus.ExponentialBuckets(0.0005, 2, 14),
+		}, []string{"repository", "method"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.requestDuration,
+		m.repositoryDuration,
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format. A
+// collector that fails is logged and left out rather than failing the
+// whole scrape.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{
+		ErrorLog:      slog.NewLogLogger(m.logger.Handler(), slog.LevelError),
+		ErrorHandling: promhttp.ContinueOnError,
+		Registry:      m.registry,
+	})
+}
+
+// ObserveRequest records an HTTP request handled by the given route.
+func (m *Metrics) ObserveRequest(method, route string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	m.requests.WithLabelValues(method, route, code).Inc()
+	m.requestDuration.WithLabelValues(method, route, code).Observe(duration.Seconds())
+}
+
+// ObserveRepository records a call to a repository method.
+func (m *Metrics) ObserveRepository(repository, method string, duration time.Duration) {
+	m.repositoryDuration.WithLabelValues(repository, method).Observe(duration.Seconds())
+}
+
+// RegisterDB adds the connection pool statistics of db, as reported by
+// sql.DB.Stats, as the go_sql_* metrics labelled db_name="carzone".
+func (m *Metrics) RegisterDB(db *sql.DB) {
+	m.registry.MustRegister(collectors.NewDBStatsCollector(db, namespace))
+}
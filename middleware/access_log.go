@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Tushar456/go-carzone/logging"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader carries the ID of a request. A client or proxy may set it
+// to correlate its own logs; otherwise one is generated. It is echoed in the
+// response either way.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds the request IDs taken from clients, which end
+// up in every log line of the request.
+const maxRequestIDLength = 128
+
+// AccessLog assigns each request an ID, puts it in the request context for
+// everything logged while handling the request, and logs the request once
+// it is done: at error level for 5xx responses, warn for 4xx and info
+// otherwise.
+func AccessLog(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = uuid.NewString()
+		}
+		c.Header(RequestIDHeader, requestID)
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), requestID))
+
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+
+		// c.Request is the request as the last handler left it, so the
+		// context holds the user once authenticated.
+		logger.LogAttrs(c.Request.Context(), level, "Handled request",
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("route", c.FullPath()),
+			slog.Int("status", status),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int("bytes", c.Writer.Size()),
+			slog.String("client_ip", c.ClientIP()),
+			slog.String("user_agent", c.Request.UserAgent()),
+		)
+	}
+}
+
+// Recovery turns a panic in a handler into a 500 response and logs it with
+// the request's correlation fields.
+func Recovery(logger *slog.Logger) gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(nil, func(c *gin.Context, recovered interface{}) {
+		logger.ErrorContext(c.Request.Context(), "Recovered from panic",
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"panic", recovered,
+		)
+		c.AbortWithStatus(http.StatusInternalServerError)
+	})
+}
@@ -1,11 +1,12 @@
 package middleware
 
 import (
-	"net/http"
-	"os"
+	"context"
 	"strings"
 	"time"
 
+	"github.com/Tushar456/go-carzone/apperrors"
+	"github.com/Tushar456/go-carzone/auth"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt"
 	"go.opentelemetry.io/otel"
@@ -13,23 +14,28 @@ import (
 
 type Claims struct {
 	UserName string `json:"username"`
+	Role     string `json:"role"`
 	jwt.StandardClaims
 }
 
-func AuthMiddleware() gin.HandlerFunc {
+// RevocationChecker reports whether an access token was revoked before it expired.
+type RevocationChecker interface {
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+func AuthMiddleware(keys *auth.KeySet, revocations RevocationChecker) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		_, span := otel.Tracer("authservice").Start(c.Request.Context(), "AuthMiddleware")
+		ctx, span := otel.Tracer("authservice").Start(c.Request.Context(), "AuthMiddleware")
 		defer span.End()
 
-		jwtSecretKey := os.Getenv("JWT_SECRET")
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing authorization header"})
+			abortWithError(c, apperrors.Unauthorized("missing authorization header"))
 			return
 		}
 
 		if !strings.HasPrefix(authHeader, "Bearer ") {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+			abortWithError(c, apperrors.Unauthorized("invalid token"))
 			return
 		}
 
@@ -37,25 +43,45 @@ func AuthMiddleware() gin.HandlerFunc {
 		tokenString = strings.TrimSpace(tokenString)
 
 		if tokenString == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+			abortWithError(c, apperrors.Unauthorized("invalid token"))
+			return
 		}
 
 		claims := &Claims{}
 
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			return []byte(jwtSecretKey), nil
-		})
+		token, err := jwt.ParseWithClaims(tokenString, claims, keys.Keyfunc)
 
 		if err != nil || !token.Valid {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+			abortWithError(c, apperrors.Unauthorized("invalid token"))
 			return
 		}
 		if claims.ExpiresAt < time.Now().Unix() {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "token expired"})
+			abortWithError(c, apperrors.Unauthorized("token expired"))
+			return
+		}
+
+		// Tokens without an ID cannot be revoked, so they are not accepted.
+		if claims.Id == "" {
+			abortWithError(c, apperrors.Unauthorized("invalid token"))
+			return
+		}
+
+		revoked, err := revocations.IsRevoked(ctx, claims.Id)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+		if revoked {
+			abortWithError(c, apperrors.Unauthorized("token revoked"))
 			return
 		}
 
+		c.Set("claims", claims)
 		c.Set("username", claims.StandardClaims.Subject)
+		c.Set("role", claims.Role)
+		// Services see the request context only, so the user is also put
+		// there for the audit log.
+		c.Request = c.Request.WithContext(auth.WithActor(c.Request.Context(), claims.StandardClaims.Subject))
 		c.Next()
 
 	}
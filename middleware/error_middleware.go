@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/Tushar456/go-carzone/apperrors"
+	"github.com/gin-gonic/gin"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// ErrorHandler renders the last error a handler attached with c.Error as the
+// common JSON error envelope, choosing the status code from its kind.
+// Internal errors are logged, since their details are not sent; other
+// errors are logged at debug level, as the access log only has their status.
+func ErrorHandler(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 {
+			return
+		}
+
+		err := c.Errors.Last().Err
+		if c.Writer.Written() {
+			// A streamed response failed part way; all that can be done
+			// is to record it.
+			logger.ErrorContext(c.Request.Context(), "Error after response was sent",
+				"method", c.Request.Method,
+				"path", c.Request.URL.Path,
+				"error", err,
+			)
+			return
+		}
+
+		status, body := apperrors.HTTPStatus(err)
+		level := slog.LevelDebug
+		if status == http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		logger.Log(c.Request.Context(), level, "Error handling request",
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"status", status,
+			"error", err,
+		)
+
+		span := oteltrace.SpanFromContext(c.Request.Context())
+		if span.SpanContext().HasTraceID() {
+			body.TraceID = span.SpanContext().TraceID().String()
+		}
+
+		c.JSON(status, apperrors.ErrorResponse{Error: body})
+	}
+}
+
+// abortWithError stops the chain and leaves err for ErrorHandler to render.
+func abortWithError(c *gin.Context, err error) {
+	c.Error(err)
+	c.Abort()
+}
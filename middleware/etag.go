@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Tushar456/go-carzone/apperrors"
+	"github.com/gin-gonic/gin"
+)
+
+// ETag returns the entity tag of a record at the given version. A response
+// that embeds other records, such as a car and its engine, passes their
+// versions after its own, so that it is tagged "<version>-<version>" and
+// changes when any of them does.
+func ETag(version int64, embedded ...int64) string {
+	tag := strconv.FormatInt(version, 10)
+	for _, v := range embedded {
+		tag += "-" + strconv.FormatInt(v, 10)
+	}
+	return `"` + tag + `"`
+}
+
+// NotModified sets the ETag header and, when the request's If-None-Match
+// already names it, answers 304 Not Modified and reports true.
+func NotModified(c *gin.Context, version int64, embedded ...int64) bool {
+	etag := ETag(version, embedded...)
+	c.Header("ETag", etag)
+
+	header := c.GetHeader("If-None-Match")
+	if header == "" {
+		return false
+	}
+	for _, tag := range strings.Split(header, ",") {
+		// If-None-Match uses the weak comparison, so W/ prefixes are ignored.
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			c.Status(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
+
+// IfMatchVersion returns the version named by the request's If-Match header,
+// or 0 when the header is absent or "*" and any version may be changed. The
+// versions of embedded records in the ETag are ignored: a write only
+// depends on the version of the record it changes.
+func IfMatchVersion(c *gin.Context) (int64, error) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+	if strings.Contains(header, ",") {
+		return 0, apperrors.BadRequest("If-Match must name a single ETag")
+	}
+
+	tag, _, _ := strings.Cut(strings.Trim(header, `"`), "-")
+	version, err := strconv.ParseInt(tag, 10, 64)
+	if err != nil || version <= 0 || !strings.HasPrefix(header, `"`) {
+		return 0, apperrors.BadRequest("If-Match is not an ETag returned by this API")
+	}
+	return version, nil
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Tushar456/go-carzone/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute labels the requests no route matched, so that arbitrary
+// paths do not each become a time series.
+const unmatchedRoute = "unmatched"
+
+// otherMethod labels the requests with a method outside knownMethods, for
+// the same reason.
+const otherMethod = "other"
+
+var knownMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+}
+
+// Metrics records the rate, status and duration of requests by route.
+func Metrics(m *metrics.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		method := c.Request.Method
+		if !knownMethods[method] {
+			method = otherMethod
+		}
+		m.ObserveRequest(method, route, c.Writer.Status(), time.Since(start))
+	}
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"github.com/Tushar456/go-carzone/apperrors"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+)
+
+// RequireRoles only lets a request through when the role set by
+// AuthMiddleware is one of the given roles, and answers 403 otherwise.
+// It must be registered after AuthMiddleware.
+func RequireRoles(roles ...string) gin.HandlerFunc {
+	allowed := make(map[string]bool, len(roles))
+	for _, role := range roles {
+		allowed[role] = true
+	}
+
+	return func(c *gin.Context) {
+		_, span := otel.Tracer("authservice").Start(c.Request.Context(), "RequireRoles")
+		defer span.End()
+
+		role := c.GetString("role")
+		if !allowed[role] {
+			abortWithError(c, apperrors.Forbidden("role '"+role+"' is not allowed to "+c.Request.Method+" "+c.FullPath()))
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	"github.com/Tushar456/go-carzone/models"
+	"gorm.io/gorm"
+)
+
+// AutoMigrate creates the schema from the models, for SQLite databases the
+// SQL migrations, written for Postgres, cannot run on. It adds missing
+// tables, columns and indexes but never migrates data, which suits the demo
+// and development databases SQLite is used for. The full-text search column
+// and triggers are Postgres only and are not created.
+func AutoMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&models.Engine{},
+		&models.Brand{},
+		&models.BrandAlias{},
+		&models.Car{},
+		&models.CarPriceHistory{},
+		&models.User{},
+		&models.RefreshToken{},
+		&models.RevokedToken{},
+		&models.AuditEntry{},
+		&models.OutboxEvent{},
+		&models.WebhookSubscription{},
+		&models.WebhookDelivery{},
+	)
+}
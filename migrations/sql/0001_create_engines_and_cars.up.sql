@@ -0,0 +1,23 @@
+-- Adopts the tables previously created by GORM AutoMigrate, so every
+-- statement tolerates them already existing.
+CREATE TABLE IF NOT EXISTS engines (
+    engine_id UUID PRIMARY KEY,
+    displacement BIGINT NOT NULL DEFAULT 0,
+    no_of_cylinders BIGINT NOT NULL DEFAULT 0,
+    car_range BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS cars (
+    id UUID PRIMARY KEY,
+    name TEXT NOT NULL DEFAULT '',
+    year TEXT NOT NULL DEFAULT '',
+    brand TEXT NOT NULL DEFAULT '',
+    fuel_type TEXT NOT NULL DEFAULT '',
+    engine_id UUID,
+    price NUMERIC NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_cars_brand ON cars (brand);
+CREATE INDEX IF NOT EXISTS idx_cars_engine_id ON cars (engine_id);
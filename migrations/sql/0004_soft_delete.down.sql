@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_engines_deleted_at;
+DROP INDEX IF EXISTS idx_cars_deleted_at;
+
+-- Rows that were soft deleted would come back to life without the column.
+DELETE FROM cars WHERE deleted_at IS NOT NULL;
+DELETE FROM engines WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE cars DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE engines DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,5 @@
+ALTER TABLE cars ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE engines ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_cars_deleted_at ON cars (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_engines_deleted_at ON engines (deleted_at);
@@ -0,0 +1 @@
+ALTER TABLE cars DROP CONSTRAINT IF EXISTS fk_cars_engine;
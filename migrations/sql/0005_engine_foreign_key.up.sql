@@ -0,0 +1,23 @@
+-- Cars may already point at engines that were deleted before this constraint
+-- existed. The constraint is added NOT VALID so that it applies to new writes
+-- straight away, and is only validated when no such car exists. Otherwise fix
+-- the dangling cars and run:
+--   ALTER TABLE cars VALIDATE CONSTRAINT fk_cars_engine;
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_cars_engine') THEN
+        ALTER TABLE cars
+            ADD CONSTRAINT fk_cars_engine FOREIGN KEY (engine_id)
+            REFERENCES engines (engine_id)
+            ON UPDATE CASCADE ON DELETE RESTRICT
+            NOT VALID;
+    END IF;
+
+    IF NOT EXISTS (
+        SELECT 1 FROM cars
+        WHERE NOT EXISTS (SELECT 1 FROM engines WHERE engines.engine_id = cars.engine_id)
+    ) THEN
+        ALTER TABLE cars VALIDATE CONSTRAINT fk_cars_engine;
+    END IF;
+END
+$$;
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_cars_brand_trgm;
+DROP INDEX IF EXISTS idx_cars_name_trgm;
+DROP INDEX IF EXISTS idx_cars_search_vector;
+
+ALTER TABLE cars DROP COLUMN IF EXISTS search_vector;
+
+-- pg_trgm is left installed: dropping an extension needs more privileges than
+-- creating it and other schemas may rely on it.
@@ -0,0 +1,16 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- The 'simple' configuration neither stems nor drops stop words, which suits
+-- model and brand names. Hyphenated names are indexed whole and in parts, so
+-- "mercedes" matches "Mercedes-Benz".
+ALTER TABLE cars ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', coalesce(name, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(brand, '')), 'B')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_cars_search_vector ON cars USING GIN (search_vector);
+
+-- Trigram indexes serve the typo tolerant word similarity operator (<%).
+CREATE INDEX IF NOT EXISTS idx_cars_name_trgm ON cars USING GIN (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_cars_brand_trgm ON cars USING GIN (brand gin_trgm_ops);
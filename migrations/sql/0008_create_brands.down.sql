@@ -0,0 +1,5 @@
+-- Cars keep the canonical brand names they were given.
+ALTER TABLE cars DROP COLUMN IF EXISTS brand_id;
+
+DROP TABLE IF EXISTS brand_aliases;
+DROP TABLE IF EXISTS brands;
@@ -0,0 +1,43 @@
+CREATE TABLE IF NOT EXISTS brands (
+    id UUID PRIMARY KEY,
+    name TEXT NOT NULL,
+    normalized_name TEXT NOT NULL,
+    country TEXT NOT NULL DEFAULT '',
+    logo_url TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_brands_normalized_name ON brands (normalized_name);
+
+CREATE TABLE IF NOT EXISTS brand_aliases (
+    normalized_name TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    brand_id UUID NOT NULL REFERENCES brands (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_brand_aliases_brand_id ON brand_aliases (brand_id);
+
+ALTER TABLE cars ADD COLUMN IF NOT EXISTS brand_id UUID REFERENCES brands (id) ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS idx_cars_brand_id ON cars (brand_id);
+
+-- Fold the free-text brands of existing cars into canonical records. Brands
+-- that normalise the same (lower case letters and digits only, as in
+-- models.NormalizeBrandName) become one record named after their most
+-- common spelling.
+INSERT INTO brands (id, name, normalized_name, created_at, updated_at)
+SELECT gen_random_uuid(), mode() WITHIN GROUP (ORDER BY brand), normalized_name, now(), now()
+FROM (
+    SELECT brand, lower(regexp_replace(brand, '[^[:alnum:]]', '', 'g')) AS normalized_name
+    FROM cars
+) AS spellings
+WHERE normalized_name <> ''
+GROUP BY normalized_name
+ON CONFLICT (normalized_name) DO NOTHING;
+
+UPDATE cars
+SET brand_id = brands.id, brand = brands.name
+FROM brands
+WHERE brands.normalized_name = lower(regexp_replace(cars.brand, '[^[:alnum:]]', '', 'g'))
+  AND cars.brand_id IS NULL;
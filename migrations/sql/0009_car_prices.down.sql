@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS car_price_history;
+
+ALTER TABLE cars DROP COLUMN IF EXISTS currency;
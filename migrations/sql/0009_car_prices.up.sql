@@ -0,0 +1,13 @@
+ALTER TABLE cars ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+CREATE TABLE IF NOT EXISTS car_price_history (
+    id UUID PRIMARY KEY,
+    car_id UUID NOT NULL REFERENCES cars (id) ON DELETE CASCADE,
+    price NUMERIC NOT NULL,
+    currency CHAR(3) NOT NULL,
+    previous_price NUMERIC NOT NULL,
+    previous_currency CHAR(3) NOT NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_car_price_history_car_id ON car_price_history (car_id, changed_at);
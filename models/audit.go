@@ -0,0 +1,132 @@
+package models
+
+import (
+	"database/sql/driver"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/Tushar456/go-carzone/apperrors"
+	"github.com/google/uuid"
+)
+
+const (
+	AuditActionCreate  = "create"
+	AuditActionUpdate  = "update"
+	AuditActionDelete  = "delete"
+	AuditActionRestore = "restore"
+)
+
+const (
+	AuditEntityCar    = "car"
+	AuditEntityEngine = "engine"
+)
+
+// AuditEntry records one change made to a car or engine. Entries are only
+// ever appended.
+type AuditEntry struct {
+	ID         uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	Actor      string    `json:"actor" gorm:"not null"`
+	Action     string    `json:"action" gorm:"not null"`
+	EntityType string    `json:"entity_type" gorm:"not null"`
+	EntityID   uuid.UUID `json:"entity_id" gorm:"type:uuid;not null"`
+	// Before and After hold the entity as returned by the API, and Diff the
+	// top-level fields that changed, as {"field": {"before": .., "after": ..}}.
+	Before    JSON      `json:"before" gorm:"type:jsonb" swaggertype:"object"`
+	After     JSON      `json:"after" gorm:"type:jsonb" swaggertype:"object"`
+	Diff      JSON      `json:"diff" gorm:"type:jsonb" swaggertype:"object"`
+	TraceID   string    `json:"trace_id,omitempty"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (AuditEntry) TableName() string {
+	return "audit_log"
+}
+
+// AuditFilter holds the query parameters accepted by the audit log endpoint.
+type AuditFilter struct {
+	Entity string    `form:"entity"`
+	ID     string    `form:"id"`
+	Actor  string    `form:"actor"`
+	Action string    `form:"action"`
+	From   time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To     time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Limit  int       `form:"limit"`
+	Offset int       `form:"offset"`
+}
+
+func (f *AuditFilter) Validate() error {
+	if f.Entity != "" && f.Entity != AuditEntityCar && f.Entity != AuditEntityEngine {
+		return apperrors.Validation("entity", "entity must be car or engine")
+	}
+
+	if f.ID != "" {
+		if _, err := uuid.Parse(f.ID); err != nil {
+			return apperrors.Validation("id", "id must be a valid UUID")
+		}
+	}
+
+	switch f.Action {
+	case "", AuditActionCreate, AuditActionUpdate, AuditActionDelete, AuditActionRestore:
+	default:
+		return apperrors.Validation("action", "action must be one of create, update, delete, restore")
+	}
+
+	if !f.From.IsZero() && !f.To.IsZero() && f.From.After(f.To) {
+		return apperrors.Validation("from", "from cannot be after to")
+	}
+
+	if f.Limit < 0 || f.Limit > MaxPageLimit {
+		return apperrors.Validation("limit", "limit must be at most "+strconv.Itoa(MaxPageLimit))
+	}
+
+	if f.Offset < 0 {
+		return apperrors.Validation("offset", "offset cannot be negative")
+	}
+
+	return nil
+}
+
+// ApplyDefaults fills in the paging defaults for unset fields.
+func (f *AuditFilter) ApplyDefaults() {
+	if f.Limit == 0 {
+		f.Limit = DefaultPageLimit
+	}
+}
+
+// JSON is a JSON document stored in a json or jsonb column. A nil document
+// is stored as NULL and written to JSON as null.
+type JSON []byte
+
+func (j JSON) MarshalJSON() ([]byte, error) {
+	if len(j) == 0 {
+		return []byte("null"), nil
+	}
+	return j, nil
+}
+
+func (j *JSON) UnmarshalJSON(data []byte) error {
+	*j = append((*j)[:0], data...)
+	return nil
+}
+
+func (j JSON) Value() (driver.Value, error) {
+	if len(j) == 0 {
+		return nil, nil
+	}
+	return string(j), nil
+}
+
+func (j *JSON) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*j = nil
+	case []byte:
+		*j = append(JSON(nil), v...)
+	case string:
+		*j = JSON(v)
+	default:
+		return errors.New("models: cannot scan JSON from a non-text value")
+	}
+	return nil
+}
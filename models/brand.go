@@ -0,0 +1,109 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Tushar456/go-carzone/apperrors"
+	"github.com/google/uuid"
+)
+
+// Brand is the canonical record of a car brand. Cars reference it by ID and
+// keep its name in Car.Brand. Brand names given by clients are resolved by
+// their normalised form against the canonical name and the aliases, so
+// "BMW", "bmw" and "B.M.W." are all the same brand.
+type Brand struct {
+	ID             uuid.UUID    `json:"id" gorm:"type:uuid;primaryKey"`
+	Name           string       `json:"name" gorm:"not null"`
+	NormalizedName string       `json:"-" gorm:"uniqueIndex;not null"`
+	Country        string       `json:"country"`
+	LogoURL        string       `json:"logo_url"`
+	Aliases        []BrandAlias `json:"aliases" gorm:"foreignKey:BrandID;constraint:OnDelete:CASCADE"`
+	CreatedAt      time.Time    `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time    `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// BrandAlias is another name a brand is known by, such as "VW" for
+// Volkswagen. Spelling variants need no alias as they normalise the same.
+type BrandAlias struct {
+	NormalizedName string    `json:"-" gorm:"primaryKey"`
+	Name           string    `json:"name" gorm:"not null"`
+	BrandID        uuid.UUID `json:"-" gorm:"type:uuid;index;not null"`
+}
+
+// RenamedCar is a car before and after the rename of its brand.
+type RenamedCar struct {
+	Before Car
+	After  Car
+}
+
+type BrandRequest struct {
+	Name    string   `json:"name" validate:"required,max=100" example:"Mercedes-Benz"`
+	Aliases []string `json:"aliases" example:"Mercedes,Benz"`
+	Country string   `json:"country" validate:"max=100" example:"Germany"`
+	LogoURL string   `json:"logo_url" validate:"url" example:"https://example.com/logos/mercedes-benz.png"`
+}
+
+// NormalizeBrandName reduces a brand name to the key it is matched by:
+// lower case letters and digits only.
+func NormalizeBrandName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+}
+
+func (r *BrandRequest) Validate() error {
+
+	r.Name = strings.TrimSpace(r.Name)
+	if err := validateStruct(r); err != nil {
+		return err
+	}
+
+	key := NormalizeBrandName(r.Name)
+	if key == "" {
+		return apperrors.Validation("name", "name must contain a letter or digit")
+	}
+
+	seen := map[string]bool{key: true}
+	for i, alias := range r.Aliases {
+		r.Aliases[i] = strings.TrimSpace(alias)
+		aliasKey := NormalizeBrandName(alias)
+		if aliasKey == "" {
+			return apperrors.Validation("aliases", "aliases must contain a letter or digit")
+		}
+		if seen[aliasKey] {
+			return apperrors.Validation("aliases", "alias "+alias+" duplicates the name or another alias")
+		}
+		seen[aliasKey] = true
+	}
+
+	return nil
+}
+
+// BrandFilter holds the query parameters accepted by the brand listing endpoint.
+type BrandFilter struct {
+	Limit  int `form:"limit"`
+	Offset int `form:"offset"`
+}
+
+func (f *BrandFilter) Validate() error {
+	if f.Limit < 0 || f.Limit > MaxPageLimit {
+		return apperrors.Validation("limit", "limit must be at most "+strconv.Itoa(MaxPageLimit))
+	}
+	if f.Offset < 0 {
+		return apperrors.Validation("offset", "offset cannot be negative")
+	}
+	return nil
+}
+
+// ApplyDefaults fills in the paging defaults for unset fields.
+func (f *BrandFilter) ApplyDefaults() {
+	if f.Limit == 0 {
+		f.Limit = DefaultPageLimit
+	}
+}
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"github.com/Tushar456/go-carzone/apperrors"
+)
+
+// Operations of a bulk car request.
+const (
+	BulkOpCreate = "create"
+	BulkOpUpdate = "update"
+	BulkOpDelete = "delete"
+)
+
+// Bulk modes. Atomic applies every operation or none of them; best effort
+// applies the operations that succeed and reports the others.
+const (
+	BulkModeAtomic     = "atomic"
+	BulkModeBestEffort = "best_effort"
+)
+
+const (
+	MaxBulkOperations = 10000
+	// BulkBatchSize is the number of cars inserted per INSERT statement.
+	BulkBatchSize = 500
+)
+
+// BulkOptions are the query parameters of bulk writes. A dry run validates
+// every operation and checks every engine but writes nothing.
+type BulkOptions struct {
+	Mode   string `form:"mode"`
+	DryRun bool   `form:"dry_run"`
+}
+
+// BulkCarOperation is one item of a bulk car request. Op defaults to create;
+// update and delete name the car by ID and may pass the version it must have.
+type BulkCarOperation struct {
+	Op      string      `json:"op" validate:"oneof=create update delete" example:"create"`
+	ID      string      `json:"id,omitempty" format:"uuid"`
+	Version int64       `json:"version,omitempty"`
+	Car     *CarRequest `json:"car,omitempty"`
+}
+
+// BulkItemResult reports the outcome of one bulk operation. Status is the
+// HTTP status the operation would have had on its own.
+type BulkItemResult struct {
+	Index   int                  `json:"index"`
+	Op      string               `json:"op"`
+	ID      string               `json:"id,omitempty"`
+	Version int64                `json:"version,omitempty"`
+	Status  int                  `json:"status"`
+	Error   *apperrors.ErrorBody `json:"error,omitempty"`
+}
+
+type BulkCarResult struct {
+	Mode      string           `json:"mode"`
+	DryRun    bool             `json:"dry_run"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Items     []BulkItemResult `json:"items"`
+}
+
+func (o *BulkCarOperation) Validate() error {
+
+	if o.Op == "" {
+		o.Op = BulkOpCreate
+	}
+	if err := validateStruct(o); err != nil {
+		return err
+	}
+
+	if o.Op != BulkOpCreate && o.ID == "" {
+		return apperrors.Validation("id", "id is required for "+o.Op)
+	}
+	if o.Op == BulkOpCreate && o.ID != "" {
+		return apperrors.Validation("id", "id cannot be set when creating a car")
+	}
+	if o.Op == BulkOpDelete {
+		return nil
+	}
+
+	if o.Car == nil {
+		return apperrors.Validation("car", "car is required for "+o.Op)
+	}
+	return o.Car.Validate()
+}
+
+// ValidateBulkMode checks the mode of a bulk request and returns the default
+// mode when none was given.
+func ValidateBulkMode(mode string) (string, error) {
+	switch mode {
+	case "":
+		return BulkModeBestEffort, nil
+	case BulkModeAtomic, BulkModeBestEffort:
+		return mode, nil
+	default:
+		return "", apperrors.Validation("mode", "mode must be one of atomic best_effort")
+	}
+}
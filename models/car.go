@@ -1,11 +1,10 @@
 package models
 
 import (
-	"errors"
-	"strconv"
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // type Car struct {
@@ -22,122 +21,51 @@ import (
 
 // GORM-compatible Car model
 type Car struct {
-	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
-	Name      string    `json:"name"`
-	Year      string    `json:"year"`
-	Brand     string    `json:"brand"`
-	FuelType  string    `json:"fuel_type"`
-	EngineID  uuid.UUID `json:"engine_id" gorm:"type:uuid"`
-	Engine    Engine    `json:"engine" gorm:"foreignKey:EngineID;references:ID"`
-	Price     float64   `json:"price"`
-	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	ID       uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	Name     string    `json:"name"`
+	Year     string    `json:"year"`
+	Brand    string    `json:"brand"`
+	BrandID  uuid.UUID `json:"brand_id" gorm:"type:uuid;index"`
+	FuelType string    `json:"fuel_type"`
+	EngineID uuid.UUID `json:"engine_id" gorm:"type:uuid"`
+	Engine   Engine    `json:"engine" gorm:"foreignKey:EngineID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Price    Money     `json:"price" gorm:"embedded"`
+	// OriginalPrice is the stored price when Price has been converted into
+	// another currency for the response.
+	OriginalPrice *Money         `json:"original_price,omitempty" gorm:"-"`
+	Version       int64          `json:"version" gorm:"not null;default:1"`
+	CreatedAt     time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt     gorm.DeletedAt `json:"deleted_at" gorm:"index" swaggertype:"string" format:"date-time"`
 }
 
 type CarRequest struct {
-	Name     string  `json:"name"`
-	Year     string  `json:"year"`
-	Brand    string  `json:"brand"`
-	FuelType string  `json:"fuel_type"`
-	EngineID string  `json:"engine_id"`
-	Price    float64 `json:"price"`
+	Name string `json:"name" validate:"required"`
+	// Four digit year, from 1886 up to the current year.
+	Year     string `json:"year" validate:"required,numeric,year" example:"2023"`
+	Brand    string `json:"brand" validate:"required"`
+	FuelType string `json:"fuel_type" validate:"required,oneof=Petrol Diesel Electric Hybrid"`
+	EngineID string `json:"engine_id" validate:"required" format:"uuid"`
+	// Price in the given currency. Without one, a new car is priced in
+	// DefaultCurrency and an updated car keeps its currency.
+	Price Money `json:"price" validate:"min=0,currency"`
 }
 
+// Validate checks the request against the rules in its struct tags and
+// reports every violation.
 func (c *CarRequest) Validate() error {
-
-	if err := validateName(c.Name); err != nil {
-		return err
-	}
-
-	if err := validateYear(c.Year); err != nil {
-		return err
-	}
-
-	if err := validateBrand(c.Brand); err != nil {
-		return err
-	}
-
-	if err := validateFuelType(c.FuelType); err != nil {
-		return err
-	}
-
-	if err := validateEngine(c.EngineID); err != nil {
-		return err
-	}
-
-	if err := validatePrice(c.Price); err != nil {
-		return err
-	}
-
-	return nil
-}
-
-func validateName(name string) error {
-
-	if name == "" {
-		return errors.New("name cannot be empty")
-	}
-	return nil
-}
-
-func validateYear(year string) error {
-
-	if year == "" {
-		return errors.New("year cannot be empty")
-	}
-
-	yearint, err := strconv.Atoi(year)
-	if err != nil {
-		return errors.New("year must be a number")
-	}
-
-	currentYear := time.Now().Year()
-	if yearint < 1886 || yearint > currentYear {
-		return errors.New("year must be between 1886 and " + strconv.Itoa(currentYear))
-	}
-	return nil
+	c.Price.normalize()
+	return validateStruct(c)
 }
 
-func validateBrand(brand string) error {
-
-	if brand == "" {
-		return errors.New("brand cannot be empty")
-	}
-
-	return nil
-}
-func validateFuelType(fuelType string) error {
-	validateFuelTYpes := []string{"Petrol", "Diesel", "Electric", "Hybrid"}
-
-	if fuelType == "" {
-		return errors.New("fuel type cannot be empty")
-	}
-
-	for _, validTfuelType := range validateFuelTYpes {
-		if fuelType == validTfuelType {
-			return nil
-		}
-	}
-	return errors.New("fuel type must be one of Petrol, Diesel, Electric, Hybrid")
-}
-
-func validatePrice(price float64) error {
-
-	if price < 0 {
-		return errors.New("price cannot be negative")
-	}
-	return nil
-}
-
-func validateEngine(engineId string) error {
-
-	if engineId == "" {
-		return errors.New("engine id cannot be empty")
-	}
-
-	_, err := uuid.Parse(engineId)
-	if err != nil {
-		return errors.New("engine id must be a valid UUID")
+// ToRequest returns the request that would recreate the car as it is now.
+func (c *Car) ToRequest() CarRequest {
+	return CarRequest{
+		Name:     c.Name,
+		Year:     c.Year,
+		Brand:    c.Brand,
+		FuelType: c.FuelType,
+		EngineID: c.EngineID.String(),
+		Price:    c.Price,
 	}
-	return nil
 }
@@ -1,9 +1,9 @@
 package models
 
 import (
-	"errors"
-
+	"github.com/Tushar456/go-carzone/apperrors"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // type Engine struct {
@@ -13,52 +13,63 @@ import (
 // 	CarRange      int       `json:"car_range"`
 // }
 
+// The primary key is called ID rather than EngineID so that gorm does not
+// mistake Car.EngineID for a has-one foreign key pointing from engines to cars.
 type Engine struct {
-	EngineID      uuid.UUID `json:"engine_id" gorm:"type:uuid;primaryKey"`
-	Displacement  int       `json:"displacement"`
-	NoOfCylinders int       `json:"no_of_cylinders"`
-	CarRange      int       `json:"car_range"`
+	ID            uuid.UUID      `json:"engine_id" gorm:"column:engine_id;type:uuid;primaryKey"`
+	Displacement  int            `json:"displacement"`
+	NoOfCylinders int            `json:"no_of_cylinders"`
+	CarRange      int            `json:"car_range"`
+	Version       int64          `json:"version" gorm:"not null;default:1"`
+	DeletedAt     gorm.DeletedAt `json:"deleted_at" gorm:"index" swaggertype:"string" format:"date-time"`
 }
 
 type EngineRequest struct {
-	Displacement  int `json:"displacement"`
-	NoOfCylinders int `json:"no_of_cylinders"`
-	CarRange      int `json:"car_range"`
+	Displacement  int `json:"displacement" validate:"required,min=1"`
+	NoOfCylinders int `json:"no_of_cylinders" validate:"required,min=1"`
+	CarRange      int `json:"car_range" validate:"min=0"`
 }
 
+// Validate checks the request against the rules in its struct tags and
+// reports every violation.
 func (e *EngineRequest) Validate() error {
-	if err := validateDisplacement(e.Displacement); err != nil {
-		return err
-	}
+	return validateStruct(e)
+}
 
-	if err := validateNoOfCylinders(e.NoOfCylinders); err != nil {
-		return err
+// ToRequest returns the request that would recreate the engine as it is now.
+func (e *Engine) ToRequest() EngineRequest {
+	return EngineRequest{
+		Displacement:  e.Displacement,
+		NoOfCylinders: e.NoOfCylinders,
+		CarRange:      e.CarRange,
 	}
+}
 
-	if err := validateCarRange(e.CarRange); err != nil {
-		return err
-	}
+// Cascade modes for deleting an engine that is still used by cars.
+const (
+	CascadeReassign = "reassign"
+)
 
-	return nil
+// DeleteEngineOptions controls what happens to the cars of an engine being
+// deleted. By default the delete is refused while any car uses the engine;
+// with cascade=reassign the cars are first moved to the engine To.
+type DeleteEngineOptions struct {
+	Cascade string `json:"cascade" form:"cascade" validate:"oneof=reassign"`
+	To      string `json:"to" form:"to" format:"uuid"`
 }
 
-func validateDisplacement(displacement int) error {
-	if displacement <= 0 {
-		return errors.New("displacement must be greater than 0")
-	}
-	return nil
-}
+func (o *DeleteEngineOptions) Validate() error {
 
-func validateNoOfCylinders(noOfCylinders int) error {
-	if noOfCylinders <= 0 {
-		return errors.New("number of cylinders must be greater than 0")
+	if err := validateStruct(o); err != nil {
+		return err
 	}
-	return nil
-}
 
-func validateCarRange(carRange int) error {
-	if carRange < 0 {
-		return errors.New("car range cannot be negative")
+	if o.Cascade == CascadeReassign && o.To == "" {
+		return apperrors.Validation("to", "to is required when cascade is reassign")
 	}
+	if o.Cascade == "" && o.To != "" {
+		return apperrors.Validation("to", "to can only be used with cascade=reassign")
+	}
+
 	return nil
 }
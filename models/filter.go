@@ -0,0 +1,179 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+
+	"github.com/Tushar456/go-carzone/apperrors"
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+// CarSortColumns lists the car columns a listing may be sorted by.
+var CarSortColumns = []string{"id", "name", "year", "brand", "fuel_type", "engine_id", "price", "created_at", "updated_at"}
+
+// CarFilter holds the query parameters accepted by the car listing endpoint.
+type CarFilter struct {
+	Brand    string   `form:"brand"`
+	FuelType string   `form:"fuel_type"`
+	YearFrom int      `form:"year_from"`
+	YearTo   int      `form:"year_to"`
+	PriceMin *float64 `form:"price_min"`
+	PriceMax *float64 `form:"price_max"`
+	Currency string   `form:"currency"`
+	// ConvertTo is a currency to convert the listed prices into.
+	ConvertTo string `form:"convert_to"`
+	EngineID  string `form:"engine_id"`
+	SortBy    string `form:"sort_by"`
+	SortOrder string `form:"sort_order"`
+	Limit     int    `form:"limit"`
+	Offset    int    `form:"offset"`
+	Cursor    string `form:"cursor"`
+	IsEngine  bool   `form:"isEngine"`
+	// IncludeDeleted also lists soft deleted cars. Only admins may set it.
+	IncludeDeleted bool `form:"include_deleted"`
+}
+
+// Page is a single page of a listing together with the information needed to fetch the next one.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	Total      int64  `json:"total"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// PageCursor is the decoded form of an opaque keyset cursor. It records the
+// sort column value and ID of the last item of the previous page.
+type PageCursor struct {
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+func (f *CarFilter) Validate() error {
+
+	if f.YearFrom != 0 && f.YearTo != 0 && f.YearFrom > f.YearTo {
+		return apperrors.Validation("year_from", "year_from cannot be greater than year_to")
+	}
+
+	if f.PriceMin != nil && *f.PriceMin < 0 {
+		return apperrors.Validation("price_min", "price_min cannot be negative")
+	}
+
+	if f.PriceMax != nil && *f.PriceMax < 0 {
+		return apperrors.Validation("price_max", "price_max cannot be negative")
+	}
+
+	if f.PriceMin != nil && f.PriceMax != nil && *f.PriceMin > *f.PriceMax {
+		return apperrors.Validation("price_min", "price_min cannot be greater than price_max")
+	}
+
+	if f.Currency != "" && !IsCurrency(f.Currency) {
+		return apperrors.Validation("currency", "currency must be an ISO 4217 code such as USD or EUR")
+	}
+
+	// Amounts in different currencies do not compare, so prices are only
+	// filtered and sorted among the cars of one currency.
+	if f.Currency == "" {
+		if f.PriceMin != nil {
+			return apperrors.Validation("currency", "currency is required with price_min")
+		}
+		if f.PriceMax != nil {
+			return apperrors.Validation("currency", "currency is required with price_max")
+		}
+		if f.SortBy == "price" {
+			return apperrors.Validation("currency", "currency is required to sort by price")
+		}
+	}
+
+	if f.ConvertTo != "" && !IsCurrency(f.ConvertTo) {
+		return apperrors.Validation("convert_to", "convert_to must be an ISO 4217 code such as USD or EUR")
+	}
+
+	if f.FuelType != "" {
+		if err := validateStructField(&CarRequest{}, "FuelType", f.FuelType); err != nil {
+			return err
+		}
+	}
+
+	if f.EngineID != "" {
+		if _, err := uuid.Parse(f.EngineID); err != nil {
+			return apperrors.Validation("engine_id", "engine_id must be a valid UUID")
+		}
+	}
+
+	if f.SortBy != "" && !isCarSortColumn(f.SortBy) {
+		return apperrors.Validation("sort_by", "sort_by must be one of id, name, year, brand, fuel_type, engine_id, price, created_at, updated_at")
+	}
+
+	if f.SortOrder != "" && f.SortOrder != "asc" && f.SortOrder != "desc" {
+		return apperrors.Validation("sort_order", "sort_order must be asc or desc")
+	}
+
+	if f.Limit < 0 || f.Limit > MaxPageLimit {
+		return apperrors.Validation("limit", "limit must be at most "+strconv.Itoa(MaxPageLimit))
+	}
+
+	if f.Offset < 0 {
+		return apperrors.Validation("offset", "offset cannot be negative")
+	}
+
+	if f.Cursor != "" {
+		if _, err := DecodeCursor(f.Cursor); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ApplyDefaults fills in the paging and sorting defaults for unset fields.
+func (f *CarFilter) ApplyDefaults() {
+	if f.Limit == 0 {
+		f.Limit = DefaultPageLimit
+	}
+	if f.SortBy == "" {
+		f.SortBy = "created_at"
+	}
+	if f.SortOrder == "" {
+		f.SortOrder = "asc"
+	}
+}
+
+func isCarSortColumn(column string) bool {
+	for _, c := range CarSortColumns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+// EncodeCursor turns a cursor into the opaque string handed out to clients.
+func EncodeCursor(cursor PageCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a cursor previously produced by EncodeCursor.
+func DecodeCursor(s string) (*PageCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, apperrors.Validation("cursor", "cursor is malformed")
+	}
+
+	var cursor PageCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, apperrors.Validation("cursor", "cursor is malformed")
+	}
+
+	if _, err := uuid.Parse(cursor.ID); err != nil {
+		return nil, apperrors.Validation("cursor", "cursor is malformed")
+	}
+	return &cursor, nil
+}
@@ -0,0 +1,134 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Tushar456/go-carzone/apperrors"
+	"github.com/shopspring/decimal"
+)
+
+// DefaultCurrency is the currency of new prices given without one.
+const DefaultCurrency = "USD"
+
+// Money is an exact decimal amount in an ISO 4217 currency. Amounts are
+// written to JSON as strings so that no precision is lost; a bare number is
+// accepted on input, as is a price without a currency, which is left empty
+// for FillCurrency to fill in.
+type Money struct {
+	Amount   decimal.Decimal `json:"amount" gorm:"column:price;type:numeric;not null;default:0" swaggertype:"string" example:"24999.99"`
+	Currency string          `json:"currency" gorm:"column:currency;type:char(3);not null;default:'USD'" example:"USD"`
+}
+
+// NewMoney returns an amount in the given currency.
+func NewMoney(amount decimal.Decimal, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+func (m Money) Equal(other Money) bool {
+	return m.Currency == other.Currency && m.Amount.Equal(other.Amount)
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] != '{' {
+		// A plain number, or a number in a string, as prices were before
+		// they carried a currency.
+		m.Currency = ""
+		return m.Amount.UnmarshalJSON(data)
+	}
+
+	type money Money
+	var v money
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*m = Money(v)
+	return nil
+}
+
+// normalize upper-cases the currency.
+func (m *Money) normalize() {
+	m.Currency = strings.ToUpper(strings.TrimSpace(m.Currency))
+}
+
+// FillCurrency sets the currency of an amount given without one.
+func (m *Money) FillCurrency(currency string) {
+	if m.Currency == "" {
+		m.Currency = currency
+	}
+}
+
+// ExchangeRates holds, for each currency it knows, the value of one unit of
+// that currency in a common base. Only the ratios matter, so any currency
+// can serve as the base.
+type ExchangeRates map[string]decimal.Decimal
+
+// ParseExchangeRates reads rates written as comma separated CODE=RATE pairs,
+// such as "USD=1,EUR=1.08,INR=0.012". An empty string gives no rates.
+func ParseExchangeRates(s string) (ExchangeRates, error) {
+	rates := ExchangeRates{}
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		code, value, ok := strings.Cut(pair, "=")
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if !ok || !IsCurrency(code) {
+			return nil, fmt.Errorf("invalid exchange rate %q", pair)
+		}
+		rate, err := decimal.NewFromString(strings.TrimSpace(value))
+		if err != nil || !rate.IsPositive() {
+			return nil, fmt.Errorf("invalid exchange rate %q", pair)
+		}
+		rates[code] = rate
+	}
+	return rates, nil
+}
+
+// Has reports whether amounts can be converted to and from the currency.
+func (r ExchangeRates) Has(currency string) bool {
+	_, ok := r[currency]
+	return ok
+}
+
+// Convert returns m in the currency to, rounded to cents.
+func (r ExchangeRates) Convert(m Money, to string) (Money, error) {
+	if m.Currency == to {
+		return m, nil
+	}
+	from, ok := r[m.Currency]
+	if !ok {
+		return Money{}, apperrors.Validation("convert_to", "no exchange rate for "+m.Currency)
+	}
+	rate, ok := r[to]
+	if !ok {
+		return Money{}, apperrors.Validation("convert_to", "no exchange rate for "+to)
+	}
+	return NewMoney(m.Amount.Mul(from).Div(rate).Round(2), to), nil
+}
+
+// IsCurrency reports whether code is an active ISO 4217 currency code.
+func IsCurrency(code string) bool {
+	return currencies[code]
+}
+
+var currencies = func() map[string]bool {
+	codes := map[string]bool{}
+	for _, code := range strings.Fields(`
+		AED AFN ALL AMD ANG AOA ARS AUD AWG AZN BAM BBD BDT BGN BHD BIF BMD BND
+		BOB BRL BSD BTN BWP BYN BZD CAD CDF CHF CLP CNY COP CRC CUP CVE CZK DJF
+		DKK DOP DZD EGP ERN ETB EUR FJD FKP GBP GEL GHS GIP GMD GNF GTQ GYD HKD
+		HNL HTG HUF IDR ILS INR IQD IRR ISK JMD JOD JPY KES KGS KHR KMF KPW KRW
+		KWD KYD KZT LAK LBP LKR LRD LSL LYD MAD MDL MGA MKD MMK MNT MOP MRU MUR
+		MVR MWK MXN MYR MZN NAD NGN NIO NOK NPR NZD OMR PAB PEN PGK PHP PKR PLN
+		PYG QAR RON RSD RUB RWF SAR SBD SCR SDG SEK SGD SHP SLE SOS SRD SSP STN
+		SVC SYP SZL THB TJS TMT TND TOP TRY TTD TWD TZS UAH UGX USD UYU UZS VES
+		VND VUV WST XAF XCD XCG XOF XPF YER ZAR ZMW ZWG`) {
+		codes[code] = true
+	}
+	return codes
+}()
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Domain event types written to the outbox.
+const (
+	EventCarCreated    = "CarCreated"
+	EventCarUpdated    = "CarUpdated"
+	EventCarDeleted    = "CarDeleted"
+	EventCarRestored   = "CarRestored"
+	EventEngineUpdated = "EngineUpdated"
+)
+
+const (
+	AggregateCar    = "car"
+	AggregateEngine = "engine"
+)
+
+// OutboxEvent is a domain event waiting in the outbox to be published. It is
+// written in the same transaction as the change it describes, so an event
+// exists exactly when its change was committed. The relay publishes it at
+// least once; consumers can use the ID to drop duplicates.
+type OutboxEvent struct {
+	ID            uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	Type          string    `json:"type" gorm:"not null"`
+	AggregateType string    `json:"aggregate_type" gorm:"not null"`
+	AggregateID   uuid.UUID `json:"aggregate_id" gorm:"type:uuid;not null"`
+	// Payload is the car or engine after the change, as returned by the API.
+	Payload       JSON       `json:"payload" gorm:"type:jsonb;not null" swaggertype:"object"`
+	CreatedAt     time.Time  `json:"occurred_at" gorm:"autoCreateTime"`
+	Attempts      int        `json:"-" gorm:"not null;default:0"`
+	NextAttemptAt time.Time  `json:"-" gorm:"not null"`
+	LastError     string     `json:"-" gorm:"not null;default:''"`
+	PublishedAt   *time.Time `json:"-"`
+}
+
+func (OutboxEvent) TableName() string {
+	return "outbox"
+}
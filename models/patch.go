@@ -0,0 +1,60 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Tushar456/go-carzone/apperrors"
+	jsonpatch "github.com/evanphx/json-patch/v5"
+)
+
+// Media types accepted by the PATCH endpoints.
+const (
+	MergePatchType = "application/merge-patch+json" // RFC 7396
+	JSONPatchType  = "application/json-patch+json"  // RFC 6902
+)
+
+// ApplyPatch applies a JSON Merge Patch or JSON Patch document, depending on
+// contentType, to the JSON form of current and decodes the result into dest.
+// Fields that dest does not know are rejected rather than silently dropped.
+func ApplyPatch(contentType string, patch []byte, current interface{}, dest interface{}) error {
+	doc, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+
+	var patched []byte
+	switch contentType {
+	case MergePatchType:
+		if !json.Valid(patch) || !bytes.HasPrefix(bytes.TrimSpace(patch), []byte("{")) {
+			return apperrors.BadRequest("merge patch must be a JSON object")
+		}
+		patched, err = jsonpatch.MergePatch(doc, patch)
+		if err != nil {
+			return apperrors.Wrap(err, apperrors.BadRequest("invalid merge patch: "+err.Error()))
+		}
+	case JSONPatchType:
+		operations, err := jsonpatch.DecodePatch(patch)
+		if err != nil {
+			return apperrors.Wrap(err, apperrors.BadRequest("invalid JSON patch: "+err.Error()))
+		}
+		patched, err = operations.Apply(doc)
+		if errors.Is(err, jsonpatch.ErrTestFailed) {
+			return apperrors.Wrap(err, apperrors.Conflict(err.Error()))
+		}
+		if err != nil {
+			return apperrors.Wrap(err, apperrors.Validation("", "patch cannot be applied: "+err.Error()))
+		}
+	default:
+		return apperrors.UnsupportedMediaType(fmt.Sprintf("content type must be %s or %s", MergePatchType, JSONPatchType))
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(dest); err != nil {
+		return apperrors.Wrap(err, apperrors.Validation("", "patched document is invalid: "+err.Error()))
+	}
+	return nil
+}
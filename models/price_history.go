@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CarPriceHistory records one change of a car's price.
+type CarPriceHistory struct {
+	ID            uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	CarID         uuid.UUID `json:"car_id" gorm:"type:uuid;index;not null"`
+	Price         Money     `json:"price" gorm:"embedded"`
+	PreviousPrice Money     `json:"previous_price" gorm:"embedded;embeddedPrefix:previous_"`
+	ChangedAt     time.Time `json:"changed_at" gorm:"autoCreateTime"`
+}
+
+func (CarPriceHistory) TableName() string {
+	return "car_price_history"
+}
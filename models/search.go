@@ -0,0 +1,52 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Tushar456/go-carzone/apperrors"
+)
+
+// CarSearchQuery holds the query parameters of the car search endpoint.
+type CarSearchQuery struct {
+	Q        string `form:"q"`
+	Limit    int    `form:"limit"`
+	Offset   int    `form:"offset"`
+	IsEngine bool   `form:"isEngine"`
+}
+
+// CarSearchResult is a car matched by a search. Highlight holds the name and
+// brand as HTML, escaped, with the matched terms wrapped in <mark> tags.
+type CarSearchResult struct {
+	Car       Car               `json:"car"`
+	Rank      float64           `json:"rank"`
+	Highlight map[string]string `json:"highlight"`
+}
+
+func (q *CarSearchQuery) Validate() error {
+
+	q.Q = strings.TrimSpace(q.Q)
+	if q.Q == "" {
+		return apperrors.Validation("q", "q cannot be empty")
+	}
+	if len(q.Q) > 200 {
+		return apperrors.Validation("q", "q must be at most 200 characters")
+	}
+
+	if q.Limit < 0 || q.Limit > MaxPageLimit {
+		return apperrors.Validation("limit", "limit must be at most "+strconv.Itoa(MaxPageLimit))
+	}
+
+	if q.Offset < 0 {
+		return apperrors.Validation("offset", "offset cannot be negative")
+	}
+
+	return nil
+}
+
+// ApplyDefaults fills in the page size when it is unset.
+func (q *CarSearchQuery) ApplyDefaults() {
+	if q.Limit == 0 {
+		q.Limit = DefaultPageLimit
+	}
+}
@@ -0,0 +1,180 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Tushar456/go-carzone/apperrors"
+	"github.com/shopspring/decimal"
+)
+
+// CarExportHeader names the columns of an inventory export. Engine columns
+// are flattened into the car row.
+var CarExportHeader = []interface{}{
+	"id", "name", "year", "brand", "fuel_type", "price", "currency", "engine_id",
+	"engine_displacement", "engine_no_of_cylinders", "engine_car_range",
+	"created_at", "updated_at",
+}
+
+// ExportRow returns the car as a row matching CarExportHeader. Free text is
+// escaped with escapeFormula.
+func (c *Car) ExportRow() []interface{} {
+	return []interface{}{
+		c.ID.String(), escapeFormula(c.Name), c.Year, escapeFormula(c.Brand), c.FuelType, c.Price.Amount.String(), c.Price.Currency, c.EngineID.String(),
+		c.Engine.Displacement, c.Engine.NoOfCylinders, c.Engine.CarRange,
+		c.CreatedAt.Format(time.RFC3339), c.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// carImportAliases maps normalised column headers to CarRequest fields. An
+// export can be imported back as is: its extra columns are ignored.
+var carImportAliases = map[string]string{
+	"name":      "name",
+	"model":     "name",
+	"year":      "year",
+	"brand":     "brand",
+	"make":      "brand",
+	"fueltype":  "fuel_type",
+	"fuel":      "fuel_type",
+	"engineid":  "engine_id",
+	"engine":    "engine_id",
+	"price":     "price",
+	"listprice": "price",
+	"currency":  "currency",
+}
+
+var carImportRequired = []string{"name", "year", "brand", "fuel_type", "engine_id"}
+
+// MapCarImportHeader works out which CarRequest field each column of an
+// import holds. Headers are matched case-insensitively, ignoring spaces,
+// dashes and underscores, against known aliases; mapping, from header to
+// field name, overrides them; mapping a header to "" ignores the column.
+// Columns that map to nothing get "".
+func MapCarImportHeader(header []string, mapping map[string]string) ([]string, error) {
+	fields := make([]string, len(header))
+	seen := map[string]bool{}
+
+	for i, column := range header {
+		field, ok := mapping[column]
+		if !ok {
+			field = carImportAliases[normaliseHeader(column)]
+		} else if field != "" && !isCarImportField(field) {
+			return nil, apperrors.Validation("mapping", fmt.Sprintf("column %q is mapped to unknown field %q", column, field))
+		}
+		if field == "" {
+			continue
+		}
+		if seen[field] {
+			return nil, apperrors.Validation("header", fmt.Sprintf("more than one column maps to %s", field))
+		}
+		seen[field] = true
+		fields[i] = field
+	}
+
+	var missing []string
+	for _, field := range carImportRequired {
+		if !seen[field] {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, apperrors.Validation("header", "missing columns: "+strings.Join(missing, ", "))
+	}
+	return fields, nil
+}
+
+// CarRequestFromRow builds a request from a row whose columns hold the given
+// fields, as returned by MapCarImportHeader.
+func CarRequestFromRow(fields []string, row []string) (*CarRequest, error) {
+	var request CarRequest
+	for i, field := range fields {
+		if field == "" || i >= len(row) {
+			continue
+		}
+		value := strings.TrimSpace(row[i])
+
+		switch field {
+		case "name":
+			request.Name = unescapeFormula(value)
+		case "year":
+			request.Year = value
+		case "brand":
+			request.Brand = unescapeFormula(value)
+		case "fuel_type":
+			request.FuelType = value
+		case "engine_id":
+			request.EngineID = value
+		case "price":
+			if value == "" {
+				continue
+			}
+			price, err := decimal.NewFromString(value)
+			if err != nil {
+				return nil, apperrors.Validation("price", "price must be a number")
+			}
+			request.Price.Amount = price
+		case "currency":
+			request.Price.Currency = value
+		}
+	}
+	return &request, nil
+}
+
+// formulaPrefixes are the characters that make spreadsheet applications
+// read a cell as a formula.
+const formulaPrefixes = "=+-@\t\r"
+
+// escapeFormula prefixes text that a spreadsheet application would run as a
+// formula with an apostrophe, which makes it read as plain text.
+func escapeFormula(text string) string {
+	if text != "" && strings.ContainsRune(formulaPrefixes, rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
+
+// unescapeFormula undoes escapeFormula, so that an export imports back as
+// it was.
+func unescapeFormula(text string) string {
+	if len(text) > 1 && text[0] == '\'' && strings.ContainsRune(formulaPrefixes, rune(text[1])) {
+		return text[1:]
+	}
+	return text
+}
+
+func isCarImportField(field string) bool {
+	for _, f := range carImportAliases {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+func normaliseHeader(column string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '_':
+			return -1
+		}
+		return r
+	}, strings.ToLower(strings.TrimSpace(column)))
+}
+
+// ImportRowResult reports the outcome of one data row of an import. Row is
+// the line number in the file, counting the header as line 1.
+type ImportRowResult struct {
+	Row    int                  `json:"row"`
+	ID     string               `json:"id,omitempty"`
+	Status int                  `json:"status"`
+	Error  *apperrors.ErrorBody `json:"error,omitempty"`
+}
+
+type ImportResult struct {
+	Mode     string            `json:"mode"`
+	DryRun   bool              `json:"dry_run"`
+	Accepted int               `json:"accepted"`
+	Rejected int               `json:"rejected"`
+	Rows     []ImportRowResult `json:"rows"`
+}
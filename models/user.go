@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"github.com/Tushar456/go-carzone/apperrors"
+	"github.com/google/uuid"
+)
+
+// Roles a user can hold. Viewers can only read inventory, editors can also
+// create and update it, and admins can additionally delete and manage users.
+const (
+	RoleViewer = "viewer"
+	RoleEditor = "editor"
+	RoleAdmin  = "admin"
+)
+
+type User struct {
+	ID           uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	Username     string    `json:"username" gorm:"uniqueIndex;not null"`
+	PasswordHash string    `json:"-" gorm:"not null"`
+	Role         string    `json:"role" gorm:"not null;default:viewer"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// Passwords are capped at 72 bytes because bcrypt ignores anything longer.
+type RegisterRequest struct {
+	Username string `json:"username" validate:"required,min=3,max=50"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=8,max=72"`
+}
+
+type UpdateRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=viewer editor admin"`
+}
+
+func (r *RegisterRequest) Validate() error {
+	return validateStruct(r)
+}
+
+func (r *ChangePasswordRequest) Validate() error {
+
+	if err := validateStruct(r); err != nil {
+		return err
+	}
+
+	if r.CurrentPassword == r.NewPassword {
+		return apperrors.ValidationErrors{{
+			Field:   "new_password",
+			Rule:    "different",
+			Message: "new_password must differ from current_password",
+		}}
+	}
+
+	return nil
+}
+
+func (r *UpdateRoleRequest) Validate() error {
+	return validateStruct(r)
+}
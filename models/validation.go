@@ -0,0 +1,188 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Tushar456/go-carzone/apperrors"
+	"github.com/google/uuid"
+)
+
+// MinYear is the year the first car was built.
+const MinYear = 1886
+
+// Request validation is driven by struct tags, which swag also reads when
+// generating docs/, so the documented constraints are the enforced ones:
+//
+//	validate:"required"        the field cannot be its zero value
+//	validate:"min=N" / "max=N" numbers must lie within the bound, strings within the length
+//	validate:"oneof=A B C"     the value must be one of the listed words
+//	validate:"numeric"         the string must hold an integer
+//	validate:"year"            the string must hold a year from MinYear to the current year
+//	validate:"url"             the string must be an absolute http or https URL
+//	validate:"currency"        the string, or the currency of the Money if it has one, must be an ISO 4217 code
+//	format:"uuid"              the string must be a UUID
+//
+// Rules run in tag order and stop at the first failure of each field, but
+// every field is checked.
+
+// validateStruct checks each field of the struct v points to against its tags
+// and returns all violations, or nil.
+func validateStruct(v interface{}) error {
+	val := reflect.ValueOf(v).Elem()
+	typ := val.Type()
+
+	var errs apperrors.ValidationErrors
+	for i := 0; i < typ.NumField(); i++ {
+		if fe := validateField(typ.Field(i), val.Field(i)); fe != nil {
+			errs = append(errs, *fe)
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// validateStructField checks a single value against the rules declared on
+// the named field of the struct v points to. It lets other inputs, such as
+// list filters, reuse a request's rules instead of restating them.
+func validateStructField(v interface{}, name string, value interface{}) error {
+	field, ok := reflect.TypeOf(v).Elem().FieldByName(name)
+	if !ok {
+		panic("models: no field " + name)
+	}
+
+	if fe := validateField(field, reflect.ValueOf(value)); fe != nil {
+		return apperrors.ValidationErrors{*fe}
+	}
+	return nil
+}
+
+func validateField(field reflect.StructField, value reflect.Value) *apperrors.FieldError {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "" {
+		name = field.Name
+	}
+
+	var rules []string
+	if tag := field.Tag.Get("validate"); tag != "" {
+		rules = strings.Split(tag, ",")
+	}
+	if field.Tag.Get("format") == "uuid" {
+		rules = append(rules, "uuid")
+	}
+
+	for _, rule := range rules {
+		key, param, _ := strings.Cut(rule, "=")
+
+		if key != "required" && value.IsZero() && value.Kind() == reflect.String {
+			// Empty optional strings are only checked for presence.
+			continue
+		}
+
+		if message := checkRule(key, param, name, value); message != "" {
+			return &apperrors.FieldError{Field: name, Rule: key, Message: message}
+		}
+	}
+	return nil
+}
+
+// checkRule returns a message describing why value breaks the rule, or ""
+// when it satisfies it.
+func checkRule(rule, param, name string, value reflect.Value) string {
+	switch rule {
+	case "required":
+		if value.IsZero() {
+			return name + " cannot be empty"
+		}
+
+	case "min", "max":
+		bound, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			panic("models: invalid " + rule + " bound " + param)
+		}
+
+		n, unit := measure(value)
+		if rule == "min" && n < bound {
+			return fmt.Sprintf("%s must be at least %s%s", name, param, unit)
+		}
+		if rule == "max" && n > bound {
+			return fmt.Sprintf("%s must be at most %s%s", name, param, unit)
+		}
+
+	case "oneof":
+		allowed := strings.Fields(param)
+		for _, a := range allowed {
+			if value.String() == a {
+				return ""
+			}
+		}
+		return name + " must be one of " + strings.Join(allowed, ", ")
+
+	case "numeric":
+		if _, err := strconv.Atoi(value.String()); err != nil {
+			return name + " must be a number"
+		}
+
+	case "year":
+		year, err := strconv.Atoi(value.String())
+		currentYear := time.Now().Year()
+		if err != nil || year < MinYear || year > currentYear {
+			return fmt.Sprintf("%s must be between %d and %d", name, MinYear, currentYear)
+		}
+
+	case "url":
+		u, err := url.Parse(value.String())
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return name + " must be an http or https URL"
+		}
+
+	case "currency":
+		code := value.String()
+		if m, ok := value.Interface().(Money); ok {
+			if m.Currency == "" {
+				break
+			}
+			code = m.Currency
+		}
+		if !IsCurrency(code) {
+			return name + " must be in an ISO 4217 currency such as USD or EUR"
+		}
+
+	case "uuid":
+		if _, err := uuid.Parse(value.String()); err != nil {
+			return name + " must be a valid UUID"
+		}
+
+	default:
+		panic("models: unknown validation rule " + rule)
+	}
+
+	return ""
+}
+
+// measure returns the number a min or max rule compares, and the unit to
+// mention in the message.
+func measure(value reflect.Value) (float64, string) {
+	switch value.Kind() {
+	case reflect.String:
+		return float64(len(value.String())), " characters"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), ""
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), ""
+	case reflect.Struct:
+		if m, ok := value.Interface().(Money); ok {
+			return m.Amount.InexactFloat64(), ""
+		}
+		panic("models: min and max do not apply to " + value.Type().String())
+	default:
+		panic("models: min and max do not apply to " + value.Kind().String())
+	}
+}
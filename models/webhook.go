@@ -0,0 +1,178 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Tushar456/go-carzone/apperrors"
+	"github.com/google/uuid"
+)
+
+// EventTypes lists the event types webhooks can subscribe to.
+var EventTypes = []string{EventCarCreated, EventCarUpdated, EventCarDeleted, EventCarRestored, EventEngineUpdated}
+
+// Webhook delivery states. A delivery is pending until the receiver accepts
+// it, or until MaxWebhookAttempts attempts have failed, when it is dead.
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusDelivered = "delivered"
+	DeliveryStatusDead      = "dead"
+)
+
+const MaxWebhookAttempts = 10
+
+// WebhookSubscription asks for the events of the given types to be POSTed to
+// a URL, signed with the subscription's secret. An empty list of event types
+// means every type; a non-empty list of brands limits car events to cars of
+// those brands, and leaves out engine events.
+type WebhookSubscription struct {
+	ID         uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey"`
+	URL        string     `json:"url" gorm:"not null"`
+	Secret     string     `json:"-" gorm:"not null"`
+	EventTypes StringList `json:"event_types" gorm:"type:jsonb;not null" swaggertype:"array,string"`
+	BrandIDs   StringList `json:"brand_ids" gorm:"type:jsonb;not null" swaggertype:"array,string"`
+	Active     bool       `json:"active" gorm:"not null;default:true"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// CreatedWebhookSubscription is the response to creating a subscription,
+// the only time its secret is shown.
+type CreatedWebhookSubscription struct {
+	WebhookSubscription
+	Secret string `json:"secret"`
+}
+
+type WebhookSubscriptionRequest struct {
+	URL        string   `json:"url" validate:"required,url" example:"https://partner.example.com/carzone/events"`
+	EventTypes []string `json:"event_types" example:"CarCreated,CarUpdated"`
+	// Brands are names or aliases from the brand catalog.
+	Brands []string `json:"brands" example:"Volkswagen"`
+	// Secret signs the deliveries. One is generated when it is left out on
+	// creation; on update, leaving it out keeps the current one.
+	Secret string `json:"secret" validate:"max=200"`
+	Active *bool  `json:"active"`
+}
+
+func (r *WebhookSubscriptionRequest) Validate() error {
+	r.URL = strings.TrimSpace(r.URL)
+	if err := validateStruct(r); err != nil {
+		return err
+	}
+
+	for _, eventType := range r.EventTypes {
+		if !isEventType(eventType) {
+			return apperrors.Validation("event_types", "event_types must be among "+strings.Join(EventTypes, ", "))
+		}
+	}
+
+	if r.Secret != "" && len(r.Secret) < 16 {
+		return apperrors.Validation("secret", "secret must be at least 16 characters")
+	}
+
+	return nil
+}
+
+// Matches reports whether an event of the given type, about a car of the
+// given brand or an engine, is delivered to the subscription.
+func (s *WebhookSubscription) Matches(eventType string, brandID uuid.UUID) bool {
+	if len(s.EventTypes) > 0 && !s.EventTypes.Contains(eventType) {
+		return false
+	}
+	if len(s.BrandIDs) > 0 && (brandID == uuid.Nil || !s.BrandIDs.Contains(brandID.String())) {
+		return false
+	}
+	return true
+}
+
+// WebhookDelivery is the delivery of one event to one subscription, and its
+// log.
+type WebhookDelivery struct {
+	ID             uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey"`
+	SubscriptionID uuid.UUID  `json:"subscription_id" gorm:"type:uuid;not null;uniqueIndex:idx_webhook_deliveries_event"`
+	EventID        uuid.UUID  `json:"event_id" gorm:"type:uuid;not null;uniqueIndex:idx_webhook_deliveries_event"`
+	EventType      string     `json:"event_type" gorm:"not null"`
+	Payload        JSON       `json:"payload" gorm:"type:jsonb;not null" swaggertype:"object"`
+	Status         string     `json:"status" gorm:"not null;index"`
+	Attempts       int        `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at" gorm:"not null"`
+	LastAttemptAt  *time.Time `json:"last_attempt_at"`
+	LastStatusCode int        `json:"last_status_code" gorm:"not null;default:0"`
+	LastError      string     `json:"last_error" gorm:"not null;default:''"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// WebhookDeliveryFilter holds the query parameters accepted by the delivery
+// log endpoint.
+type WebhookDeliveryFilter struct {
+	Status string `form:"status"`
+	Limit  int    `form:"limit"`
+	Offset int    `form:"offset"`
+}
+
+func (f *WebhookDeliveryFilter) Validate() error {
+	switch f.Status {
+	case "", DeliveryStatusPending, DeliveryStatusDelivered, DeliveryStatusDead:
+	default:
+		return apperrors.Validation("status", "status must be one of pending, delivered, dead")
+	}
+	if f.Limit < 0 || f.Limit > MaxPageLimit {
+		return apperrors.Validation("limit", "limit must be at most "+strconv.Itoa(MaxPageLimit))
+	}
+	if f.Offset < 0 {
+		return apperrors.Validation("offset", "offset cannot be negative")
+	}
+	return nil
+}
+
+// ApplyDefaults fills in the paging defaults for unset fields.
+func (f *WebhookDeliveryFilter) ApplyDefaults() {
+	if f.Limit == 0 {
+		f.Limit = DefaultPageLimit
+	}
+}
+
+func isEventType(eventType string) bool {
+	for _, t := range EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// StringList is a list of strings stored as a JSON array.
+type StringList []string
+
+func (l StringList) Contains(s string) bool {
+	for _, item := range l {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		l = StringList{}
+	}
+	data, err := json.Marshal([]string(l))
+	return string(data), err
+}
+
+func (l *StringList) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, (*[]string)(l))
+	case string:
+		return json.Unmarshal([]byte(v), (*[]string)(l))
+	default:
+		return errors.New("models: cannot scan StringList from a non-text value")
+	}
+}
@@ -0,0 +1,148 @@
+// Package outbox publishes the domain events that the repositories write to
+// the outbox table. A Relay polls the table and hands each event to a Sink;
+// an event is marked published only once the sink has accepted it, so every
+// event is delivered at least once, and failed deliveries are retried with
+// exponential backoff.
+package outbox
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Tushar456/go-carzone/models"
+	"github.com/Tushar456/go-carzone/repository"
+	"go.opentelemetry.io/otel"
+)
+
+const (
+	DefaultBatchSize    = 100
+	DefaultPollInterval = time.Second
+	// DefaultLease outlasts publishing a full batch to sinks that all time
+	// out.
+	DefaultLease = 30 * time.Minute
+
+	// PublishedRetention is how long published events are kept before the
+	// relay deletes them.
+	PublishedRetention = 7 * 24 * time.Hour
+
+	minBackoff    = time.Second
+	maxBackoff    = time.Hour
+	purgeInterval = time.Hour
+)
+
+// Sink is where a relay publishes events. Publish must return an error
+// unless the event was accepted; it may be called again for an event it
+// already accepted.
+type Sink interface {
+	Publish(ctx context.Context, event *models.OutboxEvent) error
+}
+
+// Relay publishes the events of an outbox to a sink. Events are claimed in a
+// short transaction that leases them for Lease and are published outside any
+// transaction, so a slow sink holds neither locks nor a database connection.
+// An event whose relay dies is claimed again once its lease runs out.
+type Relay struct {
+	store      repository.OutboxRepositoryInterface
+	transactor repository.TransactorInterface
+	sink       Sink
+
+	BatchSize    int
+	PollInterval time.Duration
+	Lease        time.Duration
+	Logger       *slog.Logger
+}
+
+func NewRelay(store repository.OutboxRepositoryInterface, transactor repository.TransactorInterface, sink Sink) *Relay {
+	return &Relay{
+		store:        store,
+		transactor:   transactor,
+		sink:         sink,
+		BatchSize:    DefaultBatchSize,
+		PollInterval: DefaultPollInterval,
+		Lease:        DefaultLease,
+		Logger:       slog.Default(),
+	}
+}
+
+// Run publishes events until ctx is cancelled. It polls again at once while
+// it finds full batches and waits PollInterval otherwise.
+func (r *Relay) Run(ctx context.Context) {
+	lastPurge := time.Time{}
+	for {
+		if time.Since(lastPurge) >= purgeInterval {
+			if n, err := r.store.PurgePublished(ctx, time.Now().Add(-PublishedRetention)); err != nil {
+				r.Logger.ErrorContext(ctx, "Error purging published events", "error", err)
+			} else if n > 0 {
+				r.Logger.InfoContext(ctx, "Purged published events", "count", n)
+			}
+			lastPurge = time.Now()
+		}
+
+		n, err := r.RelayOnce(ctx)
+		if err != nil {
+			r.Logger.ErrorContext(ctx, "Error relaying outbox events", "error", err)
+		}
+		if err == nil && n == r.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(r.PollInterval):
+		}
+	}
+}
+
+// RelayOnce publishes one batch of due events in order and reports how many
+// it handled. It stops at the first event the sink rejects, which is
+// scheduled for a retry, and releases the rest of the batch, so later events
+// are not overtaken more than necessary.
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	ctx, span := otel.Tracer("outbox").Start(ctx, "RelayOnce")
+	defer span.End()
+
+	var events []models.OutboxEvent
+	err := r.transactor.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		events, err = r.store.ClaimEvents(ctx, r.BatchSize, time.Now(), r.Lease)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range events {
+		event := &events[i]
+		if cause := r.sink.Publish(ctx, event); cause != nil {
+			r.Logger.WarnContext(ctx, "Error publishing event",
+				"event_type", event.Type, "event_id", event.ID, "attempt", event.Attempts+1, "error", cause)
+			err := r.transactor.Transaction(ctx, func(ctx context.Context) error {
+				if err := r.store.MarkFailed(ctx, event, time.Now().Add(Backoff(event.Attempts+1)), cause); err != nil {
+					return err
+				}
+				return r.store.ReleaseEvents(ctx, events[i+1:], time.Now())
+			})
+			return i + 1, err
+		}
+		err := r.transactor.Transaction(ctx, func(ctx context.Context) error {
+			return r.store.MarkPublished(ctx, event, time.Now())
+		})
+		if err != nil {
+			return i + 1, err
+		}
+		r.Logger.DebugContext(ctx, "Published event", "event_type", event.Type, "event_id", event.ID)
+	}
+	return len(events), nil
+}
+
+// Backoff returns how long to wait before another attempt after the given
+// number of failed ones: a second after the first, doubling up to an hour.
+func Backoff(attempts int) time.Duration {
+	backoff := minBackoff
+	for i := 1; i < attempts && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxBackoff)
+}
@@ -0,0 +1,178 @@
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Tushar456/go-carzone/models"
+	"github.com/Tushar456/go-carzone/outbox"
+	"github.com/Tushar456/go-carzone/repository"
+	brandRepository "github.com/Tushar456/go-carzone/repository/brand-repository"
+	carRepository "github.com/Tushar456/go-carzone/repository/car-repository"
+	"github.com/Tushar456/go-carzone/repository/contract"
+	engineRepository "github.com/Tushar456/go-carzone/repository/engine-repository"
+	outboxRepository "github.com/Tushar456/go-carzone/repository/outbox-repository"
+	"gorm.io/gorm"
+)
+
+// blockingSink announces each event on started and returns the next error
+// sent on release, so a test controls how long publishing takes and whether
+// it succeeds.
+type blockingSink struct {
+	started chan *models.OutboxEvent
+	release chan error
+}
+
+func newBlockingSink() *blockingSink {
+	return &blockingSink{started: make(chan *models.OutboxEvent, 100), release: make(chan error, 100)}
+}
+
+func (s *blockingSink) Publish(ctx context.Context, event *models.OutboxEvent) error {
+	s.started <- event
+	select {
+	case err := <-s.release:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type fixture struct {
+	db    *gorm.DB
+	cars  *carRepository.CarRepository
+	relay *outbox.Relay
+	sink  *blockingSink
+	// request is a car that can be created in the catalog of the fixture.
+	request *models.CarRequest
+}
+
+func newFixture(t *testing.T) *fixture {
+	ctx := context.Background()
+	db, logger := contract.OpenSQLite(t), contract.Logger(t)
+	if _, err := brandRepository.NewBrandRepository(db, logger).CreateBrand(ctx, &models.BrandRequest{Name: "Toyota"}); err != nil {
+		t.Fatalf("CreateBrand: %v", err)
+	}
+	engine, err := engineRepository.NewEngineRepository(db, logger).CreateEngine(ctx, &models.EngineRequest{Displacement: 1998, NoOfCylinders: 4})
+	if err != nil {
+		t.Fatalf("CreateEngine: %v", err)
+	}
+
+	sink := newBlockingSink()
+	relay := outbox.NewRelay(outboxRepository.NewOutboxRepository(db, logger), repository.NewTransactor(db), sink)
+	relay.Logger = logger
+	return &fixture{
+		db:      db,
+		cars:    carRepository.NewCarRepository(db, logger),
+		relay:   relay,
+		sink:    sink,
+		request: &models.CarRequest{Name: "Corolla", Year: "2020", Brand: "Toyota", FuelType: "Petrol", EngineID: engine.ID.String()},
+	}
+}
+
+func (f *fixture) createCar(t *testing.T) {
+	t.Helper()
+	if _, err := f.cars.CreateCar(context.Background(), f.request); err != nil {
+		t.Fatalf("CreateCar: %v", err)
+	}
+}
+
+func (f *fixture) unpublished(t *testing.T) int64 {
+	t.Helper()
+	var count int64
+	if err := f.db.Model(&models.OutboxEvent{}).Where("published_at IS NULL").Count(&count).Error; err != nil {
+		t.Fatalf("counting unpublished events: %v", err)
+	}
+	return count
+}
+
+type result struct {
+	n   int
+	err error
+}
+
+func (f *fixture) relayOnce() <-chan result {
+	done := make(chan result, 1)
+	go func() {
+		n, err := f.relay.RelayOnce(context.Background())
+		done <- result{n, err}
+	}()
+	return done
+}
+
+func TestRelayDoesNotBlockWrites(t *testing.T) {
+	f := newFixture(t)
+	f.createCar(t)
+
+	relayed := f.relayOnce()
+	select {
+	case <-f.sink.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the relay did not publish the event")
+	}
+
+	// The only connection to the database must be free while the sink is
+	// busy.
+	written := make(chan error, 1)
+	go func() {
+		_, err := f.cars.CreateCar(context.Background(), f.request)
+		written <- err
+	}()
+	select {
+	case err := <-written:
+		if err != nil {
+			t.Fatalf("CreateCar: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("CreateCar blocked while the sink was publishing")
+	}
+
+	f.sink.release <- nil
+	if r := <-relayed; r.err != nil || r.n != 1 {
+		t.Fatalf("RelayOnce = %d, %v; want 1, nil", r.n, r.err)
+	}
+	if got := f.unpublished(t); got != 1 {
+		t.Errorf("%d unpublished events, want the one written meanwhile", got)
+	}
+}
+
+func TestRelayLeasesClaimedEvents(t *testing.T) {
+	f := newFixture(t)
+	f.createCar(t)
+
+	relayed := f.relayOnce()
+	<-f.sink.started
+
+	// Another relay finds nothing while the event is leased.
+	other := outbox.NewRelay(outboxRepository.NewOutboxRepository(f.db, contract.Logger(t)), repository.NewTransactor(f.db), outbox.Sinks{})
+	if n, err := other.RelayOnce(context.Background()); err != nil || n != 0 {
+		t.Errorf("RelayOnce of another relay = %d, %v; want 0, nil", n, err)
+	}
+
+	f.sink.release <- nil
+	if r := <-relayed; r.err != nil || r.n != 1 {
+		t.Fatalf("RelayOnce = %d, %v; want 1, nil", r.n, r.err)
+	}
+}
+
+func TestRelayReleasesTheRestOfTheBatch(t *testing.T) {
+	f := newFixture(t)
+	f.createCar(t)
+	f.createCar(t)
+
+	f.sink.release <- errors.New("sink is down")
+	if n, err := f.relay.RelayOnce(context.Background()); err != nil || n != 1 {
+		t.Fatalf("RelayOnce = %d, %v; want 1, nil", n, err)
+	}
+
+	// The failed event waits for its backoff; the one after it is due again
+	// at once.
+	f.sink.release <- nil
+	if n, err := f.relay.RelayOnce(context.Background()); err != nil || n != 1 {
+		t.Fatalf("RelayOnce = %d, %v; want 1, nil", n, err)
+	}
+	if got := f.unpublished(t); got != 1 {
+		t.Errorf("%d unpublished events, want the failed one", got)
+	}
+}
@@ -0,0 +1,133 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Tushar456/go-carzone/models"
+)
+
+// WebhookSink POSTs each event as JSON to a URL. Any response other than a
+// 2xx status counts as a failure. The event ID is sent in the
+// Idempotency-Key header so that the receiver can drop redeliveries.
+type WebhookSink struct {
+	URL    string
+	Client *http.Client
+}
+
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{
+		URL:    url,
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (s *WebhookSink) Publish(ctx context.Context, event *models.OutboxEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", event.ID.String())
+	req.Header.Set("X-Event-Type", event.Type)
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return nil
+}
+
+// Publisher is the publishing side of a message broker client. A NATS
+// connection satisfies it as is; other clients, such as a Kafka writer, can
+// be adapted with PublisherFunc.
+type Publisher interface {
+	Publish(subject string, data []byte) error
+}
+
+// PublisherFunc adapts a function to the Publisher interface.
+type PublisherFunc func(subject string, data []byte) error
+
+func (f PublisherFunc) Publish(subject string, data []byte) error {
+	return f(subject, data)
+}
+
+// BrokerSink publishes each event as JSON to a message broker, on the
+// subject (or topic) Prefix + aggregate type + "." + event type, for
+// example "carzone.car.CarCreated".
+type BrokerSink struct {
+	Publisher Publisher
+	Prefix    string
+}
+
+func (s *BrokerSink) Publish(ctx context.Context, event *models.OutboxEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return s.Publisher.Publish(s.Prefix+event.AggregateType+"."+event.Type, data)
+}
+
+// FileSink appends each event as a line of JSON to a file. It is meant for
+// development and tests.
+type FileSink struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+func NewFileSink(path string) *FileSink {
+	return &FileSink{Path: path}
+}
+
+func (s *FileSink) Publish(ctx context.Context, event *models.OutboxEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Sinks publishes each event to every sink in turn. An event is accepted
+// only once all of them have accepted it, so a sink may see an event again
+// when a later one fails.
+type Sinks []Sink
+
+func (s Sinks) Publish(ctx context.Context, event *models.OutboxEvent) error {
+	for _, sink := range s {
+		if err := sink.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/Tushar456/go-carzone/service"
+)
+
+const (
+	defaultSoftDeleteRetention = 30 * 24 * time.Hour
+	purgeInterval              = time.Hour
+)
+
+// softDeleteRetention reads how long soft deleted rows are kept from
+// SOFT_DELETE_RETENTION, a Go duration such as "720h". Zero disables purging.
+func softDeleteRetention() (time.Duration, error) {
+	value := os.Getenv("SOFT_DELETE_RETENTION")
+	if value == "" {
+		return defaultSoftDeleteRetention, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// runPurgeJob permanently removes cars and engines that have been soft
+// deleted for longer than retention, and the tokens that have expired, once
+// at startup and then every hour. A retention of zero keeps the cars and
+// engines but still removes the tokens.
+func runPurgeJob(ctx context.Context, logger *slog.Logger, retention time.Duration, cars service.CarServiceInterface, engines service.EngineServiceInterface, tokens service.TokenServiceInterface) {
+	ticker := time.NewTicker(purgeInterval)
+	defer ticker.Stop()
+
+	for {
+		if retention > 0 {
+			before := time.Now().Add(-retention)
+
+			// Cars go first so that purged engines are no longer referenced.
+			if n, err := cars.PurgeDeletedCars(ctx, before); err != nil {
+				logger.ErrorContext(ctx, "Error purging deleted cars", "error", err)
+			} else if n > 0 {
+				logger.InfoContext(ctx, "Purged deleted cars", "count", n)
+			}
+			if n, err := engines.PurgeDeletedEngines(ctx, before); err != nil {
+				logger.ErrorContext(ctx, "Error purging deleted engines", "error", err)
+			} else if n > 0 {
+				logger.InfoContext(ctx, "Purged deleted engines", "count", n)
+			}
+		}
+
+		if n, err := tokens.PurgeExpiredTokens(ctx); err != nil {
+			logger.ErrorContext(ctx, "Error purging expired tokens", "error", err)
+		} else if n > 0 {
+			logger.InfoContext(ctx, "Purged expired tokens", "count", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
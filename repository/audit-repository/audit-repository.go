@@ -0,0 +1,88 @@
+package auditRepository
+
+import (
+	"context"
+
+	"github.com/Tushar456/go-carzone/models"
+	"github.com/Tushar456/go-carzone/repository"
+	"go.opentelemetry.io/otel"
+	"gorm.io/gorm"
+)
+
+type AuditRepository struct {
+	repo *repository.Repository[models.AuditEntry]
+}
+
+func NewAuditRepository(db *gorm.DB) *AuditRepository {
+	return &AuditRepository{
+		repo: repository.New[models.AuditEntry](db),
+	}
+}
+
+// CreateAuditEntry appends an entry to the audit log. Called with the
+// context of a transaction, the entry is only kept if the change it records
+// is.
+func (s *AuditRepository) CreateAuditEntry(ctx context.Context, entry *models.AuditEntry) error {
+	ctx, span := otel.Tracer("auditservice").Start(ctx, "CreateAuditEntry")
+	defer span.End()
+
+	return s.repo.Create(ctx, entry)
+}
+
+// ListAuditEntries returns a page of the entries matching the filter, most
+// recent first.
+func (s *AuditRepository) ListAuditEntries(ctx context.Context, filter *models.AuditFilter) (*models.Page[models.AuditEntry], error) {
+	ctx, span := otel.Tracer("auditservice").Start(ctx, "ListAuditEntries")
+	defer span.End()
+
+	scopes := auditFilterScopes(filter)
+
+	total, err := s.repo.Count(ctx, scopes...)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []models.AuditEntry{}
+	if err := s.repo.FindPage(ctx, &entries, nil, "created_at DESC, id DESC", filter.Limit, filter.Offset, scopes...); err != nil {
+		return nil, err
+	}
+
+	return &models.Page[models.AuditEntry]{
+		Items:  entries,
+		Total:  total,
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	}, nil
+}
+
+// auditFilterScopes translates the filter fields into query conditions.
+func auditFilterScopes(filter *models.AuditFilter) []repository.Scope {
+	var scopes []repository.Scope
+
+	where := func(query string, args ...interface{}) {
+		scopes = append(scopes, func(db *gorm.DB) *gorm.DB {
+			return db.Where(query, args...)
+		})
+	}
+
+	if filter.Entity != "" {
+		where("entity_type = ?", filter.Entity)
+	}
+	if filter.ID != "" {
+		where("entity_id = ?", filter.ID)
+	}
+	if filter.Actor != "" {
+		where("actor = ?", filter.Actor)
+	}
+	if filter.Action != "" {
+		where("action = ?", filter.Action)
+	}
+	if !filter.From.IsZero() {
+		where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		where("created_at <= ?", filter.To)
+	}
+
+	return scopes
+}
@@ -0,0 +1,94 @@
+// Package backend assembles the repositories the server runs on for the
+// database driver selected with DB_DRIVER, so that the services and
+// handlers only ever see the repository interfaces.
+package backend
+
+import (
+	"github.com/Tushar456/go-carzone/driver"
+	"github.com/Tushar456/go-carzone/logging"
+	"github.com/Tushar456/go-carzone/metrics"
+	"github.com/Tushar456/go-carzone/repository"
+	auditRepository "github.com/Tushar456/go-carzone/repository/audit-repository"
+	brandRepository "github.com/Tushar456/go-carzone/repository/brand-repository"
+	carRepository "github.com/Tushar456/go-carzone/repository/car-repository"
+	engineRepository "github.com/Tushar456/go-carzone/repository/engine-repository"
+	memoryRepository "github.com/Tushar456/go-carzone/repository/memory-repository"
+	metricsRepository "github.com/Tushar456/go-carzone/repository/metrics-repository"
+	outboxRepository "github.com/Tushar456/go-carzone/repository/outbox-repository"
+	sqlRepository "github.com/Tushar456/go-carzone/repository/sql-repository"
+	tokenRepository "github.com/Tushar456/go-carzone/repository/token-repository"
+	userRepository "github.com/Tushar456/go-carzone/repository/user-repository"
+	webhookRepository "github.com/Tushar456/go-carzone/repository/webhook-repository"
+	"gorm.io/gorm"
+)
+
+// Config selects the implementations New assembles.
+type Config struct {
+	// Driver is the driver db was opened with.
+	Driver string
+	// SQLQueries serves the hottest car and engine reads with hand-written
+	// SQL instead of GORM. It has no effect with driver.Memory.
+	SQLQueries bool
+}
+
+type Repositories struct {
+	// Transactor spans every store the repositories below write to.
+	Transactor repository.TransactorInterface
+
+	Cars     repository.CarRepositoryInterface
+	Engines  repository.EngineRepositoryInterface
+	Brands   repository.BrandRepositoryInterface
+	Audit    repository.AuditRepositoryInterface
+	Outbox   repository.OutboxRepositoryInterface
+	Webhooks repository.WebhookRepositoryInterface
+	Users    repository.UserRepositoryInterface
+	Tokens   repository.TokenRepositoryInterface
+}
+
+// New creates the repositories on db, the database driver.InitDB opened.
+// With driver.Memory, cars, engines and brands are kept in memory and only
+// the other records in db; their events still go to the outbox in db, and
+// transactions span both. Each repository logs with the logger of its
+// package from logs and has the latency of its methods recorded in m.
+func New(config Config, db *gorm.DB, logs *logging.Loggers, m *metrics.Metrics) *Repositories {
+	outbox := outboxRepository.NewOutboxRepository(db, logs.For("outboxRepository"))
+	repos := &Repositories{
+		Transactor: repository.NewTransactor(db),
+		Cars:       carRepository.NewCarRepository(db, logs.For("carRepository")),
+		Engines:    engineRepository.NewEngineRepository(db, logs.For("engineRepository")),
+		Brands:     brandRepository.NewBrandRepository(db, logs.For("brandRepository")),
+		Audit:      auditRepository.NewAuditRepository(db),
+		Outbox:     outbox,
+		Webhooks:   webhookRepository.NewWebhookRepository(db, logs.For("webhookRepository")),
+		Users:      userRepository.NewUserRepository(db),
+		Tokens:     tokenRepository.NewTokenRepository(db, logs.For("tokenRepository")),
+	}
+
+	switch {
+	case config.Driver == driver.Memory:
+		memDB := memoryRepository.NewDB()
+		memDB.RecordEvents(outbox)
+
+		// The in-memory transaction cannot fail to commit, so it goes first
+		// and is rolled back if the database commit fails.
+		repos.Transactor = repository.Transactors{memoryRepository.NewTransactor(memDB), repos.Transactor}
+		logger := logs.For("memoryRepository")
+		repos.Cars = memoryRepository.NewCarRepository(memDB, logger)
+		repos.Engines = memoryRepository.NewEngineRepository(memDB, logger)
+		repos.Brands = memoryRepository.NewBrandRepository(memDB, logger)
+	case config.SQLQueries:
+		logger := logs.For("sqlRepository")
+		repos.Cars = sqlRepository.NewCarRepository(db, logger)
+		repos.Engines = sqlRepository.NewEngineRepository(db, logger)
+	}
+
+	repos.Cars = metricsRepository.NewCarRepository(repos.Cars, m)
+	repos.Engines = metricsRepository.NewEngineRepository(repos.Engines, m)
+	repos.Brands = metricsRepository.NewBrandRepository(repos.Brands, m)
+	repos.Audit = metricsRepository.NewAuditRepository(repos.Audit, m)
+	repos.Outbox = metricsRepository.NewOutboxRepository(repos.Outbox, m)
+	repos.Webhooks = metricsRepository.NewWebhookRepository(repos.Webhooks, m)
+	repos.Users = metricsRepository.NewUserRepository(repos.Users, m)
+	repos.Tokens = metricsRepository.NewTokenRepository(repos.Tokens, m)
+	return repos
+}
@@ -0,0 +1,236 @@
+package brandRepository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/Tushar456/go-carzone/apperrors"
+	"github.com/Tushar456/go-carzone/models"
+	"github.com/Tushar456/go-carzone/repository"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BrandRepository struct {
+	repo      *repository.Repository[models.Brand]
+	aliasRepo *repository.Repository[models.BrandAlias]
+	carRepo   *repository.Repository[models.Car]
+
+	outboxRepo *repository.Repository[models.OutboxEvent]
+
+	logger *slog.Logger
+}
+
+func NewBrandRepository(db *gorm.DB, logger *slog.Logger) *BrandRepository {
+	return &BrandRepository{
+		repo:      repository.New[models.Brand](db),
+		aliasRepo: repository.New[models.BrandAlias](db),
+		carRepo:   repository.New[models.Car](db),
+
+		outboxRepo: repository.New[models.OutboxEvent](db),
+
+		logger: logger,
+	}
+}
+
+func (s *BrandRepository) GetBrandById(ctx context.Context, id string) (*models.Brand, error) {
+	ctx, span := otel.Tracer("brandservice").Start(ctx, "GetBrandById")
+	defer span.End()
+
+	var brand models.Brand
+	if err := s.repo.GetWithPreload(ctx, &brand, []string{"Aliases"}, "id = ?", id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound("brand not found")
+		}
+		return nil, err
+	}
+	return &brand, nil
+}
+
+func (s *BrandRepository) ListBrands(ctx context.Context, limit, offset int) (*models.Page[models.Brand], error) {
+	ctx, span := otel.Tracer("brandservice").Start(ctx, "ListBrands")
+	defer span.End()
+
+	total, err := s.repo.Count(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	brands := []models.Brand{}
+	if err := s.repo.FindPage(ctx, &brands, []string{"Aliases"}, "name ASC, id ASC", limit, offset); err != nil {
+		return nil, err
+	}
+
+	return &models.Page[models.Brand]{
+		Items:  brands,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}, nil
+}
+
+// ResolveBrand returns the brand whose name or alias matches name once both
+// are normalised.
+func (s *BrandRepository) ResolveBrand(ctx context.Context, name string) (*models.Brand, error) {
+	ctx, span := otel.Tracer("brandservice").Start(ctx, "ResolveBrand")
+	defer span.End()
+
+	resolved, err := repository.ResolveBrands(ctx, s.repo, []string{name})
+	if err != nil {
+		return nil, err
+	}
+	brand, ok := resolved[models.NormalizeBrandName(name)]
+	if !ok {
+		return nil, apperrors.NotFound("brand not found")
+	}
+	return brand, nil
+}
+
+func (s *BrandRepository) ResolveBrands(ctx context.Context, names []string) (map[string]*models.Brand, error) {
+	ctx, span := otel.Tracer("brandservice").Start(ctx, "ResolveBrands")
+	defer span.End()
+
+	return repository.ResolveBrands(ctx, s.repo, names)
+}
+
+func (s *BrandRepository) CreateBrand(ctx context.Context, brandRequest *models.BrandRequest) (*models.Brand, error) {
+	ctx, span := otel.Tracer("brandservice").Start(ctx, "CreateBrand")
+	defer span.End()
+
+	brand := &models.Brand{
+		ID:             uuid.New(),
+		Name:           brandRequest.Name,
+		NormalizedName: models.NormalizeBrandName(brandRequest.Name),
+		Country:        brandRequest.Country,
+		LogoURL:        brandRequest.LogoURL,
+		Aliases:        brandAliases(brandRequest.Aliases),
+	}
+
+	if err := s.repo.Create(ctx, brand); err != nil {
+		return nil, err
+	}
+	return s.GetBrandById(ctx, brand.ID.String())
+}
+
+// UpdateBrand replaces the fields and aliases of a brand and renames its
+// cars, which it returns before and after the rename. It should run in a
+// transaction.
+func (s *BrandRepository) UpdateBrand(ctx context.Context, id string, brandRequest *models.BrandRequest) (*models.Brand, []models.RenamedCar, error) {
+	ctx, span := otel.Tracer("brandservice").Start(ctx, "UpdateBrand")
+	defer span.End()
+
+	brand, err := s.GetBrandById(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	_, err = s.repo.UpdateColumns(ctx, map[string]interface{}{
+		"name":            brandRequest.Name,
+		"normalized_name": models.NormalizeBrandName(brandRequest.Name),
+		"country":         brandRequest.Country,
+		"logo_url":        brandRequest.LogoURL,
+	}, "id = ?", brand.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, err := s.aliasRepo.DeleteWhere(ctx, "brand_id = ?", brand.ID); err != nil {
+		return nil, nil, err
+	}
+	if aliases := brandAliases(brandRequest.Aliases); len(aliases) > 0 {
+		for i := range aliases {
+			aliases[i].BrandID = brand.ID
+		}
+		if err := s.aliasRepo.CreateInBatches(ctx, aliases, len(aliases)); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var renamed []models.RenamedCar
+	if brand.Name != brandRequest.Name {
+		// Cars keep a copy of the brand name for filtering, sorting and search.
+		var before []models.Car
+		err := s.carRepo.Unscoped().FindPage(ctx, &before, []string{"Engine"}, "id", 0, 0, func(db *gorm.DB) *gorm.DB {
+			return db.Where("brand_id = ?", brand.ID).Clauses(clause.Locking{Strength: "UPDATE"})
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		ids := make([]uuid.UUID, len(before))
+		for i := range before {
+			ids[i] = before[i].ID
+		}
+
+		if len(ids) > 0 {
+			_, err = s.carRepo.Unscoped().UpdateColumns(ctx, map[string]interface{}{
+				"brand":   brandRequest.Name,
+				"version": gorm.Expr("version + 1"),
+			}, "id IN ?", ids)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		var after []models.Car
+		if err := s.carRepo.Unscoped().FindPage(ctx, &after, []string{"Engine"}, "id", 0, 0, func(db *gorm.DB) *gorm.DB {
+			return db.Where("id IN ?", ids)
+		}); err != nil {
+			return nil, nil, err
+		}
+		for i := range after {
+			renamed = append(renamed, models.RenamedCar{Before: before[i], After: after[i]})
+			err := repository.AddEvent(ctx, s.outboxRepo, models.EventCarUpdated, models.AggregateCar, after[i].ID, &after[i])
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		s.logger.DebugContext(ctx, "Renamed brand on cars", "brand_id", brand.ID, "from", brand.Name, "to", brandRequest.Name, "cars", len(renamed))
+	}
+
+	brand, err = s.GetBrandById(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return brand, renamed, nil
+}
+
+// DeleteBrand deletes a brand that no car, deleted or not, references.
+func (s *BrandRepository) DeleteBrand(ctx context.Context, id string) (*models.Brand, error) {
+	ctx, span := otel.Tracer("brandservice").Start(ctx, "DeleteBrand")
+	defer span.End()
+
+	brand, err := s.GetBrandById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	cars, err := s.carRepo.Unscoped().Count(ctx, func(db *gorm.DB) *gorm.DB {
+		return db.Where("brand_id = ?", brand.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if cars > 0 {
+		return nil, apperrors.Conflict("brand is still used by cars").
+			WithDetails(map[string]interface{}{"car_count": cars})
+	}
+
+	if _, err := s.repo.DeleteWhere(ctx, "id = ?", brand.ID); err != nil {
+		return nil, err
+	}
+	return brand, nil
+}
+
+func brandAliases(names []string) []models.BrandAlias {
+	aliases := make([]models.BrandAlias, len(names))
+	for i, name := range names {
+		aliases[i] = models.BrandAlias{
+			NormalizedName: models.NormalizeBrandName(name),
+			Name:           name,
+		}
+	}
+	return aliases
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Tushar456/go-carzone/models"
+)
+
+// brandNameQuery selects the brands whose name, or one of whose aliases,
+// normalises to one of a list of keys. The list is passed twice.
+const brandNameQuery = "normalized_name IN ? OR id IN (SELECT brand_id FROM brand_aliases WHERE normalized_name IN ?)"
+
+// BrandIDByNameQuery is a condition on a brand_id column matching the brand
+// called, or aliased, by a normalised name. The name is passed twice.
+const BrandIDByNameQuery = "brand_id IN (SELECT id FROM brands WHERE normalized_name = ? UNION SELECT brand_id FROM brand_aliases WHERE normalized_name = ?)"
+
+// ResolveBrands looks the given brand names up by their normalised form in a
+// single query. The result maps each normalised name that matched a brand's
+// name or alias to that brand.
+func ResolveBrands(ctx context.Context, repo *Repository[models.Brand], names []string) (map[string]*models.Brand, error) {
+	resolved := map[string]*models.Brand{}
+
+	keys := make([]string, 0, len(names))
+	for _, name := range names {
+		if key := models.NormalizeBrandName(name); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return resolved, nil
+	}
+
+	var brands []models.Brand
+	if err := repo.FindWithPreload(ctx, &brands, []string{"Aliases"}, brandNameQuery, keys, keys); err != nil {
+		return nil, err
+	}
+	for i := range brands {
+		brand := &brands[i]
+		resolved[brand.NormalizedName] = brand
+		for _, alias := range brand.Aliases {
+			resolved[alias.NormalizedName] = brand
+		}
+	}
+	return resolved, nil
+}
@@ -3,23 +3,42 @@ package carRepository
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
 
+	"github.com/Tushar456/go-carzone/apperrors"
 	"github.com/Tushar456/go-carzone/models"
 	"github.com/Tushar456/go-carzone/repository"
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"go.opentelemetry.io/otel"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type CarRepository struct {
 	carRepo    *repository.Repository[models.Car]
 	engineRepo *repository.Repository[models.Engine]
+	brandRepo  *repository.Repository[models.Brand]
+
+	priceHistoryRepo *repository.Repository[models.CarPriceHistory]
+	outboxRepo       *repository.Repository[models.OutboxEvent]
+
+	logger *slog.Logger
 }
 
-func NewCarRepository(db *gorm.DB) *CarRepository {
+func NewCarRepository(db *gorm.DB, logger *slog.Logger) *CarRepository {
 	return &CarRepository{
 		carRepo:    repository.New[models.Car](db),
 		engineRepo: repository.New[models.Engine](db),
+		brandRepo:  repository.New[models.Brand](db),
+
+		priceHistoryRepo: repository.New[models.CarPriceHistory](db),
+		outboxRepo:       repository.New[models.OutboxEvent](db),
+
+		logger: logger,
 	}
 }
 
@@ -30,10 +49,9 @@ func (s *CarRepository) GetCarById(ctx context.Context, id string) (*models.Car,
 	var car models.Car
 	if err := s.carRepo.GetWithPreload(ctx, &car, []string{"Engine"}, "id = ?", id); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			// Note: Returning a non-nil empty struct on "not found" can be misleading.
-			return &car, nil
+			return nil, apperrors.NotFound("car not found")
 		}
-		return &car, err
+		return nil, err
 	}
 	return &car, nil
 }
@@ -45,10 +63,11 @@ func (s *CarRepository) GetCarByBrand(ctx context.Context, brand string, isEngin
 	var cars []models.Car
 	var err error
 
+	key := models.NormalizeBrandName(brand)
 	if isEngine {
-		err = s.carRepo.FindWithPreload(ctx, &cars, []string{"Engine"}, "brand = ?", brand)
+		err = s.carRepo.FindWithPreload(ctx, &cars, []string{"Engine"}, repository.BrandIDByNameQuery, key, key)
 	} else {
-		err = s.carRepo.Find(ctx, &cars, "brand = ?", brand)
+		err = s.carRepo.Find(ctx, &cars, repository.BrandIDByNameQuery, key, key)
 	}
 
 	if err != nil {
@@ -58,6 +77,85 @@ func (s *CarRepository) GetCarByBrand(ctx context.Context, brand string, isEngin
 	return cars, nil
 }
 
+func (s *CarRepository) ListCars(ctx context.Context, filter *models.CarFilter) (*models.Page[models.Car], error) {
+	ctx, span := otel.Tracer("carservice").Start(ctx, "ListCars")
+	defer span.End()
+
+	scopes := carFilterScopes(filter)
+
+	total, err := s.carRepo.Count(ctx, scopes...)
+	if err != nil {
+		return nil, err
+	}
+
+	direction, comparator := "ASC", ">"
+	if filter.SortOrder == "desc" {
+		direction, comparator = "DESC", "<"
+	}
+
+	order := fmt.Sprintf("%s %s", filter.SortBy, direction)
+	if filter.SortBy != "id" {
+		order += ", id " + direction
+	}
+
+	offset := filter.Offset
+	if filter.Cursor != "" {
+		cursor, err := models.DecodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		value, err := carCursorValue(filter.SortBy, cursor.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		// Keyset pagination: continue strictly after the last row of the previous page.
+		scopes = append(scopes, func(db *gorm.DB) *gorm.DB {
+			if filter.SortBy == "id" {
+				return db.Where("id "+comparator+" ?", cursor.ID)
+			}
+			return db.Where(fmt.Sprintf("(%s, id) %s (?, ?)", filter.SortBy, comparator), value, cursor.ID)
+		})
+		offset = 0
+	}
+
+	var preloads []string
+	if filter.IsEngine {
+		preloads = []string{"Engine"}
+	}
+
+	// One row past the page tells whether there is a next page to point to.
+	limit := filter.Limit
+	if limit > 0 {
+		limit++
+	}
+	cars := []models.Car{}
+	if err := s.carRepo.FindPage(ctx, &cars, preloads, order, limit, offset, scopes...); err != nil {
+		return nil, err
+	}
+	more := filter.Limit > 0 && len(cars) > filter.Limit
+	if more {
+		cars = cars[:filter.Limit]
+	}
+
+	page := &models.Page[models.Car]{
+		Items:  cars,
+		Total:  total,
+		Limit:  filter.Limit,
+		Offset: offset,
+	}
+
+	if more {
+		last := cars[len(cars)-1]
+		page.NextCursor = models.EncodeCursor(models.PageCursor{
+			Value: carSortValue(&last, filter.SortBy),
+			ID:    last.ID.String(),
+		})
+	}
+
+	return page, nil
+}
+
 func (s *CarRepository) CreateCar(ctx context.Context, carRequest *models.CarRequest) (*models.Car, error) {
 	ctx, span := otel.Tracer("carservice").Start(ctx, "CreateCar")
 	defer span.End()
@@ -65,19 +163,26 @@ func (s *CarRepository) CreateCar(ctx context.Context, carRequest *models.CarReq
 	var engine models.Engine
 	if err := s.engineRepo.Get(ctx, &engine, "engine_id = ?", carRequest.EngineID); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("engine not found")
+			return nil, apperrors.ForeignKey("engine_id", "engine not found")
 		}
 		return nil, err
 	}
 
+	brand, err := s.resolveBrand(ctx, carRequest.Brand)
+	if err != nil {
+		return nil, err
+	}
+
 	car := &models.Car{
 		ID:       uuid.New(),
 		Name:     carRequest.Name,
 		Year:     carRequest.Year,
-		Brand:    carRequest.Brand,
+		Brand:    brand.Name,
+		BrandID:  brand.ID,
 		FuelType: carRequest.FuelType,
-		EngineID: engine.EngineID, // Use the validated engine's ID
+		EngineID: engine.ID, // Use the validated engine's ID
 		Price:    carRequest.Price,
+		Version:  1,
 	}
 
 	if err := s.carRepo.Create(ctx, car); err != nil {
@@ -88,55 +193,216 @@ func (s *CarRepository) CreateCar(ctx context.Context, carRequest *models.CarReq
 	if err := s.carRepo.GetWithPreload(ctx, &createdCar, []string{"Engine"}, "id = ?", car.ID); err != nil {
 		return nil, err
 	}
+	if err := s.addEvent(ctx, models.EventCarCreated, &createdCar); err != nil {
+		return nil, err
+	}
 
 	return &createdCar, nil
 }
 
-func (s *CarRepository) UpdateCar(ctx context.Context, id string, updateCarRequest *models.CarRequest) (*models.Car, error) {
+// CreateCars inserts cars in batches of batchSize. It resolves the brands
+// in one query but does not look the engines up; callers check them
+// beforehand with ExistingEngineIDs and the foreign key catches engines
+// deleted in between.
+func (s *CarRepository) CreateCars(ctx context.Context, carRequests []*models.CarRequest, batchSize int) ([]models.Car, error) {
+	ctx, span := otel.Tracer("carservice").Start(ctx, "CreateCars")
+	defer span.End()
+
+	names := make([]string, len(carRequests))
+	for i, carRequest := range carRequests {
+		names[i] = carRequest.Brand
+	}
+	brands, err := repository.ResolveBrands(ctx, s.brandRepo, names)
+	if err != nil {
+		return nil, err
+	}
+
+	cars := make([]models.Car, len(carRequests))
+	for i, carRequest := range carRequests {
+		engineID, err := uuid.Parse(carRequest.EngineID)
+		if err != nil {
+			return nil, apperrors.Validation("engine_id", "engine id must be a valid UUID")
+		}
+		brand, ok := brands[models.NormalizeBrandName(carRequest.Brand)]
+		if !ok {
+			return nil, unknownBrand(carRequest.Brand)
+		}
+		cars[i] = models.Car{
+			ID:       uuid.New(),
+			Name:     carRequest.Name,
+			Year:     carRequest.Year,
+			Brand:    brand.Name,
+			BrandID:  brand.ID,
+			FuelType: carRequest.FuelType,
+			EngineID: engineID,
+			Price:    carRequest.Price,
+			Version:  1,
+		}
+	}
+
+	if err := s.carRepo.CreateInBatches(ctx, cars, batchSize); err != nil {
+		return nil, err
+	}
+	s.logger.DebugContext(ctx, "Inserted cars", "cars", len(cars), "batch_size", batchSize)
+	for i := range cars {
+		if err := s.addEvent(ctx, models.EventCarCreated, &cars[i]); err != nil {
+			return nil, err
+		}
+	}
+	return cars, nil
+}
+
+// ExistingEngineIDs reports which of the given engine IDs belong to engines
+// that exist, using a single query.
+func (s *CarRepository) ExistingEngineIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]bool, error) {
+	ctx, span := otel.Tracer("carservice").Start(ctx, "ExistingEngineIDs")
+	defer span.End()
+
+	existing := make(map[uuid.UUID]bool, len(ids))
+	if len(ids) == 0 {
+		return existing, nil
+	}
+
+	var engines []models.Engine
+	if err := s.engineRepo.Find(ctx, &engines, "engine_id IN ?", ids); err != nil {
+		return nil, err
+	}
+	for _, engine := range engines {
+		existing[engine.ID] = true
+	}
+	return existing, nil
+}
+
+// ResolveBrands maps the normalised form of each given brand name that
+// names or aliases a brand to that brand, using a single query.
+func (s *CarRepository) ResolveBrands(ctx context.Context, names []string) (map[string]*models.Brand, error) {
+	ctx, span := otel.Tracer("carservice").Start(ctx, "ResolveBrands")
+	defer span.End()
+
+	return repository.ResolveBrands(ctx, s.brandRepo, names)
+}
+
+// resolveBrand returns the brand a car request's brand name or alias refers to.
+func (s *CarRepository) resolveBrand(ctx context.Context, name string) (*models.Brand, error) {
+	brands, err := repository.ResolveBrands(ctx, s.brandRepo, []string{name})
+	if err != nil {
+		return nil, err
+	}
+	brand, ok := brands[models.NormalizeBrandName(name)]
+	if !ok {
+		return nil, unknownBrand(name)
+	}
+	return brand, nil
+}
+
+func unknownBrand(name string) error {
+	return apperrors.ForeignKey("brand", fmt.Sprintf("brand %q is not in the catalog", name))
+}
+
+// UpdateCar replaces the fields of a car and records a price change in the
+// price history, so it should run in a transaction. A non-zero version must
+// match the stored one; the update also fails if the car changes while it
+// runs.
+func (s *CarRepository) UpdateCar(ctx context.Context, id string, updateCarRequest *models.CarRequest, version int64) (*models.Car, error) {
 	ctx, span := otel.Tracer("carservice").Start(ctx, "UpdateCar")
 	defer span.End()
 
 	var car models.Car
 	if err := s.carRepo.Get(ctx, &car, "id = ?", id); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("car not found")
+			return nil, apperrors.NotFound("car not found")
 		}
 		return nil, err
 	}
+	if version != 0 && car.Version != version {
+		return nil, repository.ErrCarModified
+	}
 
 	// Validate that the new engine exists before updating.
 	engineID, err := uuid.Parse(updateCarRequest.EngineID)
 	if err != nil {
-		return nil, err
+		return nil, apperrors.Validation("engine_id", "engine id must be a valid UUID")
 	}
 	var engine models.Engine
 	if err := s.engineRepo.Get(ctx, &engine, "engine_id = ?", engineID); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("engine not found")
+			return nil, apperrors.ForeignKey("engine_id", "engine not found")
 		}
 		return nil, err
 	}
 
-	car.Name = updateCarRequest.Name
-	car.Year = updateCarRequest.Year
-	car.Brand = updateCarRequest.Brand
-	car.FuelType = updateCarRequest.FuelType
-	car.Price = updateCarRequest.Price
-	car.EngineID = engineID
+	brand, err := s.resolveBrand(ctx, updateCarRequest.Brand)
+	if err != nil {
+		return nil, err
+	}
 
-	if err := s.carRepo.Update(ctx, &car); err != nil {
+	updated, err := s.carRepo.UpdateColumns(ctx, map[string]interface{}{
+		"name":      updateCarRequest.Name,
+		"year":      updateCarRequest.Year,
+		"brand":     brand.Name,
+		"brand_id":  brand.ID,
+		"fuel_type": updateCarRequest.FuelType,
+		"price":     updateCarRequest.Price.Amount,
+		"currency":  updateCarRequest.Price.Currency,
+		"engine_id": engineID,
+		"version":   gorm.Expr("version + 1"),
+	}, "id = ? AND version = ?", car.ID, car.Version)
+	if err != nil {
 		return nil, err
 	}
+	if updated == 0 {
+		s.logger.DebugContext(ctx, "Car changed during update", "car_id", car.ID, "version", car.Version)
+		return nil, repository.ErrCarModified
+	}
+
+	if !car.Price.Equal(updateCarRequest.Price) {
+		err := s.priceHistoryRepo.Create(ctx, &models.CarPriceHistory{
+			ID:            uuid.New(),
+			CarID:         car.ID,
+			Price:         updateCarRequest.Price,
+			PreviousPrice: car.Price,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	// Reload the car with the engine association to return the full object.
 	if err := s.carRepo.GetWithPreload(ctx, &car, []string{"Engine"}, "id = ?", car.ID); err != nil {
 		return nil, err
 	}
+	if err := s.addEvent(ctx, models.EventCarUpdated, &car); err != nil {
+		return nil, err
+	}
 
 	return &car, nil
 }
 
-func (s *CarRepository) DeleteCar(ctx context.Context, id string) (*models.Car, error) {
+// GetPriceHistory returns the price changes of a car, most recent first.
+func (s *CarRepository) GetPriceHistory(ctx context.Context, id string) ([]models.CarPriceHistory, error) {
+	ctx, span := otel.Tracer("carservice").Start(ctx, "GetPriceHistory")
+	defer span.End()
+
+	var car models.Car
+	if err := s.carRepo.Get(ctx, &car, "id = ?", id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound("car not found")
+		}
+		return nil, err
+	}
+
+	history := []models.CarPriceHistory{}
+	err := s.priceHistoryRepo.FindPage(ctx, &history, nil, "changed_at DESC, id DESC", 0, 0, func(db *gorm.DB) *gorm.DB {
+		return db.Where("car_id = ?", car.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// DeleteCar soft deletes a car. A non-zero version must match the stored one.
+func (s *CarRepository) DeleteCar(ctx context.Context, id string, version int64) (*models.Car, error) {
 	ctx, span := otel.Tracer("carservice").Start(ctx, "DeleteCar")
 	defer span.End()
 
@@ -144,13 +410,214 @@ func (s *CarRepository) DeleteCar(ctx context.Context, id string) (*models.Car,
 	// First, find the car to return it after deletion.
 	if err := s.carRepo.GetWithPreload(ctx, &car, []string{"Engine"}, "id = ?", id); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("car not found")
+			return nil, apperrors.NotFound("car not found")
+		}
+		return nil, err
+	}
+	if version != 0 && car.Version != version {
+		return nil, repository.ErrCarModified
+	}
+
+	deleted, err := s.carRepo.DeleteWhere(ctx, "id = ? AND version = ?", car.ID, car.Version)
+	if err != nil {
+		return nil, err
+	}
+	if deleted == 0 {
+		s.logger.DebugContext(ctx, "Car changed during delete", "car_id", car.ID, "version", car.Version)
+		return nil, repository.ErrCarModified
+	}
+	if err := s.addEvent(ctx, models.EventCarDeleted, &car); err != nil {
+		return nil, err
+	}
+	return &car, nil
+}
+
+// RestoreCar restores a soft deleted car. A car whose engine has been
+// deleted since cannot be restored until the engine is.
+func (s *CarRepository) RestoreCar(ctx context.Context, id string) (*models.Car, error) {
+	ctx, span := otel.Tracer("carservice").Start(ctx, "RestoreCar")
+	defer span.End()
+
+	var deletedCar models.Car
+	if err := s.carRepo.Unscoped().Get(ctx, &deletedCar, "id = ? AND deleted_at IS NOT NULL", id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound("deleted car not found")
+		}
+		return nil, err
+	}
+	var engine models.Engine
+	if err := s.engineRepo.Get(ctx, &engine, "engine_id = ?", deletedCar.EngineID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrCarEngineDeleted
 		}
 		return nil, err
 	}
 
-	if err := s.carRepo.Delete(ctx, &car); err != nil {
+	restored, err := s.carRepo.Restore(ctx, "id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	if restored == 0 {
+		return nil, apperrors.NotFound("deleted car not found")
+	}
+
+	var car models.Car
+	if err := s.carRepo.GetWithPreload(ctx, &car, []string{"Engine"}, "id = ?", id); err != nil {
+		return nil, err
+	}
+	if err := s.addEvent(ctx, models.EventCarRestored, &car); err != nil {
 		return nil, err
 	}
 	return &car, nil
 }
+
+// addEvent writes an event about a car to the outbox. Like the rest of a
+// write, it only takes effect with the transaction the write runs in.
+func (s *CarRepository) addEvent(ctx context.Context, eventType string, car *models.Car) error {
+	return repository.AddEvent(ctx, s.outboxRepo, eventType, models.AggregateCar, car.ID, car)
+}
+
+// PurgeDeletedCars permanently removes the cars soft deleted before the
+// given time and returns them as they were. It should run in a transaction.
+func (s *CarRepository) PurgeDeletedCars(ctx context.Context, before time.Time) ([]models.Car, error) {
+	ctx, span := otel.Tracer("carservice").Start(ctx, "PurgeDeletedCars")
+	defer span.End()
+
+	var cars []models.Car
+	err := s.carRepo.Unscoped().FindPage(ctx, &cars, []string{"Engine"}, "id", 0, 0, func(db *gorm.DB) *gorm.DB {
+		return db.Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+			Clauses(clause.Locking{Strength: "UPDATE"})
+	})
+	if err != nil || len(cars) == 0 {
+		return cars, err
+	}
+
+	ids := make([]uuid.UUID, len(cars))
+	for i := range cars {
+		ids[i] = cars[i].ID
+	}
+	if _, err := s.carRepo.Purge(ctx, before, func(db *gorm.DB) *gorm.DB {
+		return db.Where("id IN ?", ids)
+	}); err != nil {
+		return nil, err
+	}
+	s.logger.DebugContext(ctx, "Purged deleted cars", "before", before, "count", len(cars))
+	return cars, nil
+}
+
+// carsByBrandQuery counts the cars of every brand in the catalog, those
+// with none included.
+const carsByBrandQuery = `SELECT b.name AS brand, count(c.id) AS cars
+FROM brands b
+LEFT JOIN cars c ON c.brand_id = b.id AND c.deleted_at IS NULL
+GROUP BY b.name`
+
+// CountCarsByBrand returns the number of cars of each brand, soft deleted
+// cars excluded.
+func (s *CarRepository) CountCarsByBrand(ctx context.Context) (map[string]int64, error) {
+	ctx, span := otel.Tracer("carservice").Start(ctx, "CountCarsByBrand")
+	defer span.End()
+
+	var rows []struct {
+		Brand string
+		Cars  int64
+	}
+	if err := s.carRepo.Raw(ctx, &rows, carsByBrandQuery); err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Brand] = row.Cars
+	}
+	return counts, nil
+}
+
+// carFilterScopes translates the filter fields into query conditions.
+func carFilterScopes(filter *models.CarFilter) []repository.Scope {
+	var scopes []repository.Scope
+
+	if filter.IncludeDeleted {
+		scopes = append(scopes, func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped()
+		})
+	}
+
+	where := func(query string, args ...interface{}) {
+		scopes = append(scopes, func(db *gorm.DB) *gorm.DB {
+			return db.Where(query, args...)
+		})
+	}
+
+	if filter.Brand != "" {
+		key := models.NormalizeBrandName(filter.Brand)
+		where(repository.BrandIDByNameQuery, key, key)
+	}
+	if filter.Currency != "" {
+		where("currency = ?", filter.Currency)
+	}
+	if filter.FuelType != "" {
+		where("fuel_type = ?", filter.FuelType)
+	}
+	if filter.EngineID != "" {
+		where("engine_id = ?", filter.EngineID)
+	}
+	// Years are stored as four digit strings, so they compare correctly as text.
+	if filter.YearFrom != 0 {
+		where("year >= ?", strconv.Itoa(filter.YearFrom))
+	}
+	if filter.YearTo != 0 {
+		where("year <= ?", strconv.Itoa(filter.YearTo))
+	}
+	if filter.PriceMin != nil {
+		where("price >= ?", *filter.PriceMin)
+	}
+	if filter.PriceMax != nil {
+		where("price <= ?", *filter.PriceMax)
+	}
+
+	return scopes
+}
+
+// carSortValue returns the value of the sort column of a car, formatted for a cursor.
+func carSortValue(car *models.Car, column string) string {
+	switch column {
+	case "name":
+		return car.Name
+	case "year":
+		return car.Year
+	case "brand":
+		return car.Brand
+	case "fuel_type":
+		return car.FuelType
+	case "engine_id":
+		return car.EngineID.String()
+	case "price":
+		return car.Price.Amount.String()
+	case "created_at":
+		return car.CreatedAt.Format(time.RFC3339Nano)
+	case "updated_at":
+		return car.UpdatedAt.Format(time.RFC3339Nano)
+	default:
+		return car.ID.String()
+	}
+}
+
+// carCursorValue parses a cursor value back into the type of its sort column.
+func carCursorValue(column, value string) (interface{}, error) {
+	switch column {
+	case "price":
+		price, err := decimal.NewFromString(value)
+		if err != nil {
+			return nil, apperrors.Validation("cursor", "cursor is malformed")
+		}
+		return price, nil
+	case "created_at", "updated_at":
+		t, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return nil, apperrors.Validation("cursor", "cursor is malformed")
+		}
+		return t, nil
+	default:
+		return value, nil
+	}
+}
@@ -0,0 +1,15 @@
+package carRepository_test
+
+import (
+	"testing"
+
+	carRepository "github.com/Tushar456/go-carzone/repository/car-repository"
+	"github.com/Tushar456/go-carzone/repository/contract"
+	engineRepository "github.com/Tushar456/go-carzone/repository/engine-repository"
+)
+
+var newFixture = contract.NewGORMFixture(carRepository.NewCarRepository, engineRepository.NewEngineRepository)
+
+func TestCarRepository(t *testing.T) {
+	contract.TestCarRepository(t, newFixture)
+}
@@ -0,0 +1,157 @@
+package carRepository
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/Tushar456/go-carzone/models"
+	"github.com/Tushar456/go-carzone/repository"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+)
+
+// carSearchMatch selects the cars matching the search text @q: full-text
+// matches on the name and brand, plus fuzzy matches of a word of either for
+// typo tolerance. websearch_to_tsquery accepts any user input without errors.
+const carSearchMatch = `
+FROM cars, websearch_to_tsquery('simple', @q) AS query
+WHERE cars.deleted_at IS NULL
+  AND (cars.search_vector @@ query OR @q <% cars.name OR @q <% cars.brand)`
+
+// Full-text matches outrank fuzzy ones; the closer the fuzzy match the better.
+// Names and brands are HTML-escaped before they are highlighted, so the
+// highlights carry no markup but the <mark> tags.
+var carSearchSelect = `
+SELECT cars.id,
+       ts_rank(cars.search_vector, query) * 2
+         + word_similarity(@q, cars.name) + word_similarity(@q, cars.brand) AS rank,
+       ts_headline('simple', ` + repository.HTMLEscapeSQL("cars.name") + `, query, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') AS name_highlight,
+       ts_headline('simple', ` + repository.HTMLEscapeSQL("cars.brand") + `, query, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') AS brand_highlight` +
+	carSearchMatch + `
+ORDER BY rank DESC, cars.id
+LIMIT @limit OFFSET @offset`
+
+type carSearchRow struct {
+	ID             uuid.UUID
+	Rank           float64
+	NameHighlight  string
+	BrandHighlight string
+}
+
+// SearchCars ranks the cars matching the search text. The matching IDs are
+// found with one query and the cars then loaded with their associations.
+func (s *CarRepository) SearchCars(ctx context.Context, query *models.CarSearchQuery) (*models.Page[models.CarSearchResult], error) {
+	ctx, span := otel.Tracer("carservice").Start(ctx, "SearchCars")
+	defer span.End()
+
+	if dialect := s.carRepo.Dialect(); dialect != "postgres" {
+		s.logger.DebugContext(ctx, "Searching cars without full-text search", "dialect", dialect)
+		return s.searchCarsApprox(ctx, query)
+	}
+
+	args := map[string]interface{}{"q": query.Q, "limit": query.Limit, "offset": query.Offset}
+
+	var total int64
+	if err := s.carRepo.Raw(ctx, &total, "SELECT count(*)"+carSearchMatch, args); err != nil {
+		return nil, err
+	}
+
+	var rows []carSearchRow
+	if err := s.carRepo.Raw(ctx, &rows, carSearchSelect, args); err != nil {
+		return nil, err
+	}
+
+	page := &models.Page[models.CarSearchResult]{
+		Items:  []models.CarSearchResult{},
+		Total:  total,
+		Limit:  query.Limit,
+		Offset: query.Offset,
+	}
+	if len(rows) == 0 {
+		return page, nil
+	}
+
+	ids := make([]uuid.UUID, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+	}
+
+	var preloads []string
+	if query.IsEngine {
+		preloads = []string{"Engine"}
+	}
+	var cars []models.Car
+	if err := s.carRepo.FindWithPreload(ctx, &cars, preloads, "id IN ?", ids); err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]models.Car, len(cars))
+	for _, car := range cars {
+		byID[car.ID] = car
+	}
+
+	for _, row := range rows {
+		car, ok := byID[row.ID]
+		if !ok {
+			// Deleted between the two queries.
+			continue
+		}
+		page.Items = append(page.Items, models.CarSearchResult{
+			Car:  car,
+			Rank: row.Rank,
+			Highlight: map[string]string{
+				"name":  row.NameHighlight,
+				"brand": row.BrandHighlight,
+			},
+		})
+	}
+	return page, nil
+}
+
+// searchCarsApprox searches databases without full-text search, such as
+// SQLite, by ranking every car with repository.SearchRank. It loads all the
+// cars, which suits the small databases SQLite is used for.
+func (s *CarRepository) searchCarsApprox(ctx context.Context, query *models.CarSearchQuery) (*models.Page[models.CarSearchResult], error) {
+	var preloads []string
+	if query.IsEngine {
+		preloads = []string{"Engine"}
+	}
+	var cars []models.Car
+	if err := s.carRepo.FindWithPreload(ctx, &cars, preloads); err != nil {
+		return nil, err
+	}
+
+	terms := strings.Fields(strings.ToLower(query.Q))
+	results := []models.CarSearchResult{}
+	for _, car := range cars {
+		rank := repository.SearchRank(terms, car.Name) + repository.SearchRank(terms, car.Brand)
+		if rank == 0 {
+			continue
+		}
+		results = append(results, models.CarSearchResult{
+			Car:  car,
+			Rank: rank,
+			Highlight: map[string]string{
+				"name":  repository.SearchHighlight(terms, car.Name),
+				"brand": repository.SearchHighlight(terms, car.Brand),
+			},
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Rank != results[j].Rank {
+			return results[i].Rank > results[j].Rank
+		}
+		return results[i].Car.ID.String() < results[j].Car.ID.String()
+	})
+
+	total := len(results)
+	start := min(query.Offset, total)
+	end := min(start+query.Limit, total)
+	return &models.Page[models.CarSearchResult]{
+		Items:  results[start:end],
+		Total:  int64(total),
+		Limit:  query.Limit,
+		Offset: query.Offset,
+	}, nil
+}
@@ -0,0 +1,129 @@
+// Package contract holds the behaviour every implementation of the car and
+// engine repository interfaces must share, as test suites that each
+// implementation runs from its own tests.
+package contract
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/Tushar456/go-carzone/models"
+	"github.com/Tushar456/go-carzone/repository"
+	brandRepository "github.com/Tushar456/go-carzone/repository/brand-repository"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// RepositoryFixture is a fresh, empty implementation of the repository
+// interfaces, sharing one database. AddBrand adds a brand to the catalog
+// the implementation checks cars against.
+type RepositoryFixture struct {
+	Cars       repository.CarRepositoryInterface
+	Engines    repository.EngineRepositoryInterface
+	Transactor repository.TransactorInterface
+	AddBrand   func(t *testing.T, brand *models.BrandRequest)
+}
+
+// storeFixture is the view of the repositories the store suites run
+// against: the create, read, update and delete operations, without
+// versions.
+type storeFixture struct {
+	Cars     carStore
+	Engines  engineStore
+	AddBrand func(t *testing.T, brand *models.BrandRequest)
+}
+
+func (f RepositoryFixture) stores() storeFixture {
+	return storeFixture{
+		Cars:     carStore{f.Cars},
+		Engines:  engineStore{f.Engines},
+		AddBrand: f.AddBrand,
+	}
+}
+
+// carStore updates and deletes cars whatever their current version is.
+type carStore struct {
+	repository.CarRepositoryInterface
+}
+
+func (s carStore) UpdateCar(ctx context.Context, id string, updateCar *models.CarRequest) (*models.Car, error) {
+	return s.CarRepositoryInterface.UpdateCar(ctx, id, updateCar, 0)
+}
+
+func (s carStore) DeleteCar(ctx context.Context, id string) (*models.Car, error) {
+	return s.CarRepositoryInterface.DeleteCar(ctx, id, 0)
+}
+
+// engineStore updates and deletes engines like carStore does.
+type engineStore struct {
+	repository.EngineRepositoryInterface
+}
+
+func (s engineStore) UpdateEngine(ctx context.Context, id string, updateEngine *models.EngineRequest) (*models.Engine, error) {
+	return s.EngineRepositoryInterface.UpdateEngine(ctx, id, updateEngine, 0)
+}
+
+func (s engineStore) DeleteEngine(ctx context.Context, id string) (*models.Engine, error) {
+	return s.EngineRepositoryInterface.DeleteEngine(ctx, id, 0)
+}
+
+// OpenSQLite returns a GORM handle on a private in-memory SQLite database
+// holding the car and engine tables, for running the suites against the
+// database implementations without a server. The database is closed when the
+// test ends.
+func OpenSQLite(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open("file::memory:?_pragma=foreign_keys(1)"), &gorm.Config{
+		TranslateError: true,
+		Logger:         logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("opening SQLite: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("opening SQLite: %v", err)
+	}
+	// Every connection to ":memory:" opens its own database.
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	err = db.AutoMigrate(&models.Engine{}, &models.Brand{}, &models.BrandAlias{}, &models.Car{}, &models.CarPriceHistory{}, &models.OutboxEvent{})
+	if err != nil {
+		t.Fatalf("creating tables: %v", err)
+	}
+	return db
+}
+
+// NewGORMFixture returns a fixture for the suites that builds the car and
+// engine repositories with the given constructors on a database from
+// OpenSQLite, checking cars against the GORM brand catalog.
+func NewGORMFixture[C repository.CarRepositoryInterface, E repository.EngineRepositoryInterface](
+	cars func(db *gorm.DB, logger *slog.Logger) C,
+	engines func(db *gorm.DB, logger *slog.Logger) E,
+) func(t *testing.T) RepositoryFixture {
+	return func(t *testing.T) RepositoryFixture {
+		db, logger := OpenSQLite(t), Logger(t)
+		brands := brandRepository.NewBrandRepository(db, logger)
+		return RepositoryFixture{
+			Cars:       cars(db, logger),
+			Engines:    engines(db, logger),
+			Transactor: repository.NewTransactor(db),
+			AddBrand: func(t *testing.T, brand *models.BrandRequest) {
+				if _, err := brands.CreateBrand(context.Background(), brand); err != nil {
+					t.Fatalf("CreateBrand: %v", err)
+				}
+			},
+		}
+	}
+}
+
+// Logger returns a logger for the repositories under test that writes
+// everything, debug records included, to the test's output, which is only
+// shown when the test fails or runs with -v.
+func Logger(t testing.TB) *slog.Logger {
+	return slog.New(slog.NewTextHandler(t.Output(), &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
@@ -0,0 +1,508 @@
+package contract
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Tushar456/go-carzone/apperrors"
+	"github.com/Tushar456/go-carzone/models"
+	"github.com/google/uuid"
+)
+
+// TestCarRepository runs the car repository suite, the car store suite
+// included, calling newFixture for a fresh implementation in each test.
+func TestCarRepository(t *testing.T, newFixture func(t *testing.T) RepositoryFixture) {
+	ctx := context.Background()
+
+	t.Run("store", func(t *testing.T) {
+		testCarStore(t, func(t *testing.T) storeFixture { return newFixture(t).stores() })
+	})
+
+	setup := func(t *testing.T) (RepositoryFixture, *models.Engine) {
+		f := newFixture(t)
+		f.AddBrand(t, &models.BrandRequest{Name: "Toyota"})
+		f.AddBrand(t, &models.BrandRequest{Name: "Volkswagen", Aliases: []string{"VW"}})
+		return f, createEngine(t, f)
+	}
+
+	t.Run("brand names and aliases", func(t *testing.T) {
+		f, engine := setup(t)
+		created := createCar(t, f, carRequest("Golf", "vw", engine.ID, "1"))
+		if created.Brand != "Volkswagen" {
+			t.Errorf("brand = %q, want the catalog name Volkswagen", created.Brand)
+		}
+
+		cars, err := f.Cars.GetCarByBrand(ctx, "V.W.", false)
+		if err != nil {
+			t.Fatalf("GetCarByBrand: %v", err)
+		}
+		expectCarIDs(t, cars, created.ID)
+
+		_, err = f.Cars.CreateCar(ctx, carRequest("Model 3", "Tesla", engine.ID, "1"))
+		expectKind(t, err, apperrors.ErrForeignKey)
+
+		brands, err := f.Cars.ResolveBrands(ctx, []string{"vw", "toyota", "tesla"})
+		if err != nil {
+			t.Fatalf("ResolveBrands: %v", err)
+		}
+		if brands["vw"] == nil || brands["vw"].Name != "Volkswagen" || brands["toyota"] == nil || brands["tesla"] != nil {
+			t.Errorf("ResolveBrands = %v, want vw and toyota resolved and tesla not", brands)
+		}
+	})
+
+	t.Run("versions", func(t *testing.T) {
+		f, engine := setup(t)
+		created := createCar(t, f, carRequest("Corolla", "Toyota", engine.ID, "1"))
+		id := created.ID.String()
+
+		_, err := f.Cars.UpdateCar(ctx, id, carRequest("Corolla", "Toyota", engine.ID, "2"), created.Version+1)
+		expectKind(t, err, apperrors.ErrPreconditionFailed)
+		updated, err := f.Cars.UpdateCar(ctx, id, carRequest("Corolla", "Toyota", engine.ID, "2"), created.Version)
+		if err != nil {
+			t.Fatalf("UpdateCar: %v", err)
+		}
+
+		_, err = f.Cars.DeleteCar(ctx, id, created.Version)
+		expectKind(t, err, apperrors.ErrPreconditionFailed)
+		if _, err := f.Cars.DeleteCar(ctx, id, updated.Version); err != nil {
+			t.Fatalf("DeleteCar: %v", err)
+		}
+	})
+
+	t.Run("RestoreCar and PurgeDeletedCars", func(t *testing.T) {
+		f, engine := setup(t)
+		car := createCar(t, f, carRequest("Corolla", "Toyota", engine.ID, "1"))
+		id := car.ID.String()
+
+		_, err := f.Cars.RestoreCar(ctx, id)
+		expectKind(t, err, apperrors.ErrNotFound)
+
+		if _, err := f.Cars.DeleteCar(ctx, id, 0); err != nil {
+			t.Fatalf("DeleteCar: %v", err)
+		}
+		restored, err := f.Cars.RestoreCar(ctx, id)
+		if err != nil {
+			t.Fatalf("RestoreCar: %v", err)
+		}
+		if restored.ID != car.ID || restored.Engine.ID != engine.ID {
+			t.Errorf("restored %+v, want car %s with engine %s", restored, car.ID, engine.ID)
+		}
+
+		if _, err := f.Cars.DeleteCar(ctx, id, 0); err != nil {
+			t.Fatalf("DeleteCar: %v", err)
+		}
+		purged, err := f.Cars.PurgeDeletedCars(ctx, time.Now().Add(-time.Hour))
+		if err != nil || len(purged) != 0 {
+			t.Fatalf("PurgeDeletedCars before the deletion = %d cars, %v; want 0", len(purged), err)
+		}
+		purged, err = f.Cars.PurgeDeletedCars(ctx, time.Now().Add(time.Second))
+		if err != nil || len(purged) != 1 || purged[0].ID != car.ID {
+			t.Fatalf("PurgeDeletedCars = %d cars, %v; want car %s", len(purged), err, car.ID)
+		}
+		_, err = f.Cars.RestoreCar(ctx, id)
+		expectKind(t, err, apperrors.ErrNotFound)
+	})
+
+	t.Run("RestoreCar with a deleted engine", func(t *testing.T) {
+		f, engine := setup(t)
+		car := createCar(t, f, carRequest("Corolla", "Toyota", engine.ID, "1"))
+		id := car.ID.String()
+
+		if _, err := f.Cars.DeleteCar(ctx, id, 0); err != nil {
+			t.Fatalf("DeleteCar: %v", err)
+		}
+		if _, err := f.Engines.DeleteEngine(ctx, engine.ID.String(), 0); err != nil {
+			t.Fatalf("DeleteEngine: %v", err)
+		}
+		_, err := f.Cars.RestoreCar(ctx, id)
+		expectKind(t, err, apperrors.ErrForeignKey)
+
+		if _, err := f.Engines.RestoreEngine(ctx, engine.ID.String()); err != nil {
+			t.Fatalf("RestoreEngine: %v", err)
+		}
+		restored, err := f.Cars.RestoreCar(ctx, id)
+		if err != nil {
+			t.Fatalf("RestoreCar: %v", err)
+		}
+		if restored.Engine.ID != engine.ID {
+			t.Errorf("restored car engine = %s, want %s", restored.Engine.ID, engine.ID)
+		}
+	})
+
+	t.Run("ListCars", func(t *testing.T) {
+		f, engine := setup(t)
+		a := carRequest("Auris", "Toyota", engine.ID, "100")
+		a.Year = "2015"
+		b := carRequest("Beetle", "VW", engine.ID, "200")
+		b.FuelType = "Diesel"
+		c := carRequest("Corolla", "Toyota", engine.ID, "300")
+		c.Year = "2022"
+		auris, beetle, corolla := createCar(t, f, a), createCar(t, f, b), createCar(t, f, c)
+		deleted := createCar(t, f, carRequest("Aygo", "Toyota", engine.ID, "50"))
+		if _, err := f.Cars.DeleteCar(ctx, deleted.ID.String(), 0); err != nil {
+			t.Fatalf("DeleteCar: %v", err)
+		}
+
+		list := func(filter models.CarFilter) *models.Page[models.Car] {
+			t.Helper()
+			filter.ApplyDefaults()
+			page, err := f.Cars.ListCars(ctx, &filter)
+			if err != nil {
+				t.Fatalf("ListCars(%+v): %v", filter, err)
+			}
+			return page
+		}
+
+		page := list(models.CarFilter{})
+		if page.Total != 3 {
+			t.Errorf("total = %d, want 3", page.Total)
+		}
+		expectCarIDs(t, page.Items, auris.ID, beetle.ID, corolla.ID)
+		expectCarIDs(t, list(models.CarFilter{Brand: "toyota"}).Items, auris.ID, corolla.ID)
+		expectCarIDs(t, list(models.CarFilter{FuelType: "Diesel"}).Items, beetle.ID)
+		expectCarIDs(t, list(models.CarFilter{YearFrom: 2016, YearTo: 2021}).Items, beetle.ID)
+		expectCarIDs(t, list(models.CarFilter{IncludeDeleted: true}).Items, auris.ID, beetle.ID, corolla.ID, deleted.ID)
+
+		first := list(models.CarFilter{SortBy: "name", Limit: 2})
+		expectOrder(t, first.Items, auris.ID, beetle.ID)
+		if first.Total != 3 || first.NextCursor == "" {
+			t.Fatalf("first page total = %d, cursor = %q; want 3 and a cursor", first.Total, first.NextCursor)
+		}
+		second := list(models.CarFilter{SortBy: "name", Limit: 2, Cursor: first.NextCursor})
+		expectOrder(t, second.Items, corolla.ID)
+		if second.NextCursor != "" {
+			t.Errorf("last page has cursor %q", second.NextCursor)
+		}
+		exact := list(models.CarFilter{SortBy: "name", Limit: 3})
+		expectOrder(t, exact.Items, auris.ID, beetle.ID, corolla.ID)
+		if exact.NextCursor != "" {
+			t.Errorf("page ending on the last car has cursor %q", exact.NextCursor)
+		}
+		exact = list(models.CarFilter{SortBy: "name", Limit: 1, Cursor: first.NextCursor})
+		expectOrder(t, exact.Items, corolla.ID)
+		if exact.NextCursor != "" {
+			t.Errorf("page after a cursor ending on the last car has cursor %q", exact.NextCursor)
+		}
+
+		yen := carRequest("Yaris", "Toyota", engine.ID, "200")
+		yen.Price.Currency = "JPY"
+		yaris := createCar(t, f, yen)
+		min, max := 150.0, 250.0
+		expectCarIDs(t, list(models.CarFilter{PriceMin: &min, PriceMax: &max, Currency: "USD"}).Items, beetle.ID)
+		expectCarIDs(t, list(models.CarFilter{PriceMin: &min, PriceMax: &max, Currency: "JPY"}).Items, yaris.ID)
+		expectOrder(t, list(models.CarFilter{SortBy: "price", SortOrder: "desc", Offset: 1, Currency: "USD"}).Items, beetle.ID, auris.ID)
+
+		for _, car := range list(models.CarFilter{IsEngine: true}).Items {
+			if car.Engine.ID != engine.ID {
+				t.Errorf("car %s engine = %s, want %s", car.ID, car.Engine.ID, engine.ID)
+			}
+		}
+	})
+
+	t.Run("SearchCars highlights escaped text", func(t *testing.T) {
+		f, engine := setup(t)
+		car := createCar(t, f, carRequest(`Golf <img src=x onerror="alert(1)">`, "VW", engine.ID, "1"))
+
+		query := &models.CarSearchQuery{Q: "golf"}
+		query.ApplyDefaults()
+		page, err := f.Cars.SearchCars(ctx, query)
+		if err != nil {
+			t.Fatalf("SearchCars: %v", err)
+		}
+		if len(page.Items) != 1 || page.Items[0].Car.ID != car.ID {
+			t.Fatalf("SearchCars found %d cars, want %s", len(page.Items), car.ID)
+		}
+		want := "<mark>Golf</mark> &lt;img src=x onerror=&#34;alert(1)&#34;&gt;"
+		if got := page.Items[0].Highlight["name"]; got != want {
+			t.Errorf("name highlight = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("GetPriceHistory", func(t *testing.T) {
+		f, engine := setup(t)
+		car := createCar(t, f, carRequest("Corolla", "Toyota", engine.ID, "100"))
+		id := car.ID.String()
+
+		for _, price := range []string{"100", "120", "120", "90"} {
+			if _, err := f.Cars.UpdateCar(ctx, id, carRequest("Corolla", "Toyota", engine.ID, price), 0); err != nil {
+				t.Fatalf("UpdateCar: %v", err)
+			}
+		}
+
+		history, err := f.Cars.GetPriceHistory(ctx, id)
+		if err != nil {
+			t.Fatalf("GetPriceHistory: %v", err)
+		}
+		if len(history) != 2 {
+			t.Fatalf("got %d price changes, want 2", len(history))
+		}
+		if history[0].Price.Amount.String() != "90" || history[0].PreviousPrice.Amount.String() != "120" ||
+			history[1].Price.Amount.String() != "120" || history[1].PreviousPrice.Amount.String() != "100" {
+			t.Errorf("history = %+v, want 120->90 then 100->120", history)
+		}
+
+		_, err = f.Cars.GetPriceHistory(ctx, uuid.NewString())
+		expectKind(t, err, apperrors.ErrNotFound)
+	})
+
+	t.Run("CreateCars and ExistingEngineIDs", func(t *testing.T) {
+		f, engine := setup(t)
+		cars, err := f.Cars.CreateCars(ctx, []*models.CarRequest{
+			carRequest("Corolla", "Toyota", engine.ID, "1"),
+			carRequest("Golf", "VW", engine.ID, "1"),
+		}, 1)
+		if err != nil {
+			t.Fatalf("CreateCars: %v", err)
+		}
+		if len(cars) != 2 || cars[1].Brand != "Volkswagen" || cars[1].Version != 1 {
+			t.Errorf("CreateCars = %+v", cars)
+		}
+
+		_, err = f.Cars.CreateCars(ctx, []*models.CarRequest{carRequest("Model 3", "Tesla", engine.ID, "1")}, 10)
+		expectKind(t, err, apperrors.ErrForeignKey)
+
+		deleted := createEngine(t, f)
+		if _, err := f.Engines.DeleteEngine(ctx, deleted.ID.String(), 0); err != nil {
+			t.Fatalf("DeleteEngine: %v", err)
+		}
+		missing := uuid.New()
+		existing, err := f.Cars.ExistingEngineIDs(ctx, []uuid.UUID{engine.ID, deleted.ID, missing})
+		if err != nil {
+			t.Fatalf("ExistingEngineIDs: %v", err)
+		}
+		if !existing[engine.ID] || existing[deleted.ID] || existing[missing] || len(existing) != 1 {
+			t.Errorf("ExistingEngineIDs = %v, want only %s", existing, engine.ID)
+		}
+	})
+
+	t.Run("CountCarsByBrand", func(t *testing.T) {
+		f, engine := setup(t)
+		createCar(t, f, carRequest("Corolla", "Toyota", engine.ID, "1"))
+		createCar(t, f, carRequest("Yaris", "Toyota", engine.ID, "1"))
+		golf := createCar(t, f, carRequest("Golf", "VW", engine.ID, "1"))
+		if _, err := f.Cars.DeleteCar(ctx, golf.ID.String(), 0); err != nil {
+			t.Fatalf("DeleteCar: %v", err)
+		}
+
+		counts, err := f.Cars.CountCarsByBrand(ctx)
+		if err != nil {
+			t.Fatalf("CountCarsByBrand: %v", err)
+		}
+		want := map[string]int64{"Toyota": 2, "Volkswagen": 0}
+		if len(counts) != len(want) || counts["Toyota"] != want["Toyota"] || counts["Volkswagen"] != want["Volkswagen"] {
+			t.Errorf("CountCarsByBrand = %v, want %v", counts, want)
+		}
+	})
+}
+
+// TestEngineRepository runs the engine repository suite, the engine store
+// suite included, calling newFixture for a fresh implementation in each
+// test.
+func TestEngineRepository(t *testing.T, newFixture func(t *testing.T) RepositoryFixture) {
+	ctx := context.Background()
+
+	t.Run("store", func(t *testing.T) {
+		testEngineStore(t, func(t *testing.T) storeFixture { return newFixture(t).stores() })
+	})
+
+	setup := func(t *testing.T) RepositoryFixture {
+		f := newFixture(t)
+		f.AddBrand(t, &models.BrandRequest{Name: "Toyota"})
+		return f
+	}
+
+	t.Run("versions", func(t *testing.T) {
+		f := setup(t)
+		engine := createEngine(t, f)
+		id := engine.ID.String()
+		request := &models.EngineRequest{Displacement: 1, NoOfCylinders: 1}
+
+		_, err := f.Engines.UpdateEngine(ctx, id, request, engine.Version+1)
+		expectKind(t, err, apperrors.ErrPreconditionFailed)
+		updated, err := f.Engines.UpdateEngine(ctx, id, request, engine.Version)
+		if err != nil {
+			t.Fatalf("UpdateEngine: %v", err)
+		}
+
+		_, err = f.Engines.DeleteEngine(ctx, id, engine.Version)
+		expectKind(t, err, apperrors.ErrPreconditionFailed)
+		if _, err := f.Engines.DeleteEngine(ctx, id, updated.Version); err != nil {
+			t.Fatalf("DeleteEngine: %v", err)
+		}
+	})
+
+	t.Run("RestoreEngine and PurgeDeletedEngines", func(t *testing.T) {
+		f := setup(t)
+		unused, used := createEngine(t, f), createEngine(t, f)
+		car := createCar(t, f, carRequest("Corolla", "Toyota", used.ID, "1"))
+		if _, err := f.Cars.DeleteCar(ctx, car.ID.String(), 0); err != nil {
+			t.Fatalf("DeleteCar: %v", err)
+		}
+
+		_, err := f.Engines.RestoreEngine(ctx, unused.ID.String())
+		expectKind(t, err, apperrors.ErrNotFound)
+
+		for _, engine := range []*models.Engine{unused, used} {
+			if _, err := f.Engines.DeleteEngine(ctx, engine.ID.String(), 0); err != nil {
+				t.Fatalf("DeleteEngine: %v", err)
+			}
+		}
+		restored, err := f.Engines.RestoreEngine(ctx, unused.ID.String())
+		if err != nil || restored.ID != unused.ID {
+			t.Fatalf("RestoreEngine = %+v, %v", restored, err)
+		}
+		if _, err := f.Engines.DeleteEngine(ctx, unused.ID.String(), 0); err != nil {
+			t.Fatalf("DeleteEngine: %v", err)
+		}
+
+		// The engine of a soft deleted car is kept.
+		purged, err := f.Engines.PurgeDeletedEngines(ctx, time.Now().Add(time.Second))
+		if err != nil || len(purged) != 1 || purged[0].ID != unused.ID {
+			t.Fatalf("PurgeDeletedEngines = %d engines, %v; want engine %s", len(purged), err, unused.ID)
+		}
+		_, err = f.Engines.RestoreEngine(ctx, unused.ID.String())
+		expectKind(t, err, apperrors.ErrNotFound)
+		if _, err := f.Engines.RestoreEngine(ctx, used.ID.String()); err != nil {
+			t.Fatalf("RestoreEngine of the kept engine: %v", err)
+		}
+	})
+
+	t.Run("CountEngines", func(t *testing.T) {
+		f := setup(t)
+		createEngine(t, f)
+		deleted := createEngine(t, f)
+		if _, err := f.Engines.DeleteEngine(ctx, deleted.ID.String(), 0); err != nil {
+			t.Fatalf("DeleteEngine: %v", err)
+		}
+
+		count, err := f.Engines.CountEngines(ctx)
+		if err != nil || count != 1 {
+			t.Errorf("CountEngines = %d, %v; want 1", count, err)
+		}
+	})
+
+	t.Run("GetReferencingCarIDs and ReassignCars", func(t *testing.T) {
+		f := setup(t)
+		from, to := createEngine(t, f), createEngine(t, f)
+		live := createCar(t, f, carRequest("Corolla", "Toyota", from.ID, "1"))
+		deleted := createCar(t, f, carRequest("Yaris", "Toyota", from.ID, "1"))
+		if _, err := f.Cars.DeleteCar(ctx, deleted.ID.String(), 0); err != nil {
+			t.Fatalf("DeleteCar: %v", err)
+		}
+
+		ids, err := f.Engines.GetReferencingCarIDs(ctx, from.ID.String())
+		if err != nil || len(ids) != 1 || ids[0] != live.ID {
+			t.Fatalf("GetReferencingCarIDs = %v, %v; want [%s]", ids, err, live.ID)
+		}
+
+		moved, err := f.Engines.ReassignCars(ctx, from.ID.String(), to.ID.String())
+		if err != nil || len(moved) != 2 {
+			t.Fatalf("ReassignCars = %v, %v; want 2 cars, soft deleted ones included", moved, err)
+		}
+		want := map[uuid.UUID]bool{live.ID: true, deleted.ID: true}
+		if !want[moved[0]] || !want[moved[1]] || moved[0].String() > moved[1].String() {
+			t.Errorf("ReassignCars = %v, want %s and %s in order", moved, live.ID, deleted.ID)
+		}
+		car, err := f.Cars.GetCarById(ctx, live.ID.String())
+		if err != nil {
+			t.Fatalf("GetCarById: %v", err)
+		}
+		if car.EngineID != to.ID || car.Version != live.Version+1 {
+			t.Errorf("car engine = %s version = %d; want %s and %d", car.EngineID, car.Version, to.ID, live.Version+1)
+		}
+
+		ids, err = f.Engines.GetReferencingCarIDs(ctx, from.ID.String())
+		if err != nil || len(ids) != 0 {
+			t.Errorf("GetReferencingCarIDs after reassigning = %v, %v; want none", ids, err)
+		}
+		moved, err = f.Engines.ReassignCars(ctx, from.ID.String(), to.ID.String())
+		if err != nil || len(moved) != 0 {
+			t.Errorf("ReassignCars again = %v, %v; want none", moved, err)
+		}
+	})
+}
+
+// TestTransactor runs the transaction suite, calling newFixture for a fresh
+// implementation in each test.
+func TestTransactor(t *testing.T, newFixture func(t *testing.T) RepositoryFixture) {
+	ctx := context.Background()
+	request := &models.EngineRequest{Displacement: 1998, NoOfCylinders: 4}
+
+	t.Run("commit", func(t *testing.T) {
+		f := newFixture(t)
+		var engine *models.Engine
+		err := f.Transactor.Transaction(ctx, func(ctx context.Context) error {
+			var err error
+			engine, err = f.Engines.CreateEngine(ctx, request)
+			return err
+		})
+		if err != nil {
+			t.Fatalf("Transaction: %v", err)
+		}
+		if _, err := f.Engines.GetEngineById(ctx, engine.ID.String()); err != nil {
+			t.Errorf("GetEngineById after commit: %v", err)
+		}
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		f := newFixture(t)
+		kept := createEngine(t, f)
+		failure := errors.New("failure")
+
+		var engine *models.Engine
+		err := f.Transactor.Transaction(ctx, func(ctx context.Context) error {
+			var err error
+			if engine, err = f.Engines.CreateEngine(ctx, request); err != nil {
+				return err
+			}
+			if _, err := f.Engines.UpdateEngine(ctx, kept.ID.String(), request, 0); err != nil {
+				return err
+			}
+			// A nested transaction joins the outer one.
+			return f.Transactor.Transaction(ctx, func(ctx context.Context) error {
+				if _, err := f.Engines.DeleteEngine(ctx, kept.ID.String(), 0); err != nil {
+					return err
+				}
+				return failure
+			})
+		})
+		if !errors.Is(err, failure) {
+			t.Fatalf("Transaction error = %v, want %v", err, failure)
+		}
+
+		_, err = f.Engines.GetEngineById(ctx, engine.ID.String())
+		expectKind(t, err, apperrors.ErrNotFound)
+		got, err := f.Engines.GetEngineById(ctx, kept.ID.String())
+		if err != nil {
+			t.Fatalf("GetEngineById after rollback: %v", err)
+		}
+		if got.Version != kept.Version || got.Displacement != kept.Displacement {
+			t.Errorf("engine after rollback = %+v, want %+v", got, kept)
+		}
+	})
+}
+
+func createEngine(t *testing.T, f RepositoryFixture) *models.Engine {
+	t.Helper()
+	return createStoreEngine(t, f.stores())
+}
+
+func createCar(t *testing.T, f RepositoryFixture, request *models.CarRequest) *models.Car {
+	t.Helper()
+	return createStoreCar(t, f.stores(), request)
+}
+
+// expectOrder checks that cars are the cars with the given IDs, in order.
+func expectOrder(t *testing.T, cars []models.Car, ids ...uuid.UUID) {
+	t.Helper()
+	if len(cars) != len(ids) {
+		t.Fatalf("got %d cars, want %d", len(cars), len(ids))
+	}
+	for i, car := range cars {
+		if car.ID != ids[i] {
+			t.Fatalf("car %d is %s (%s), want %s", i, car.ID, car.Name, ids[i])
+		}
+	}
+}
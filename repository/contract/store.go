@@ -0,0 +1,308 @@
+package contract
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Tushar456/go-carzone/apperrors"
+	"github.com/Tushar456/go-carzone/models"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// testCarStore runs the car store suite, calling newFixture for a fresh
+// implementation in each test.
+func testCarStore(t *testing.T, newFixture func(t *testing.T) storeFixture) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T) (storeFixture, *models.Engine) {
+		f := newFixture(t)
+		f.AddBrand(t, &models.BrandRequest{Name: "Toyota"})
+		f.AddBrand(t, &models.BrandRequest{Name: "Honda"})
+		return f, createStoreEngine(t, f)
+	}
+
+	t.Run("GetCarById not found", func(t *testing.T) {
+		f, _ := setup(t)
+		car, err := f.Cars.GetCarById(ctx, uuid.NewString())
+		expectKind(t, err, apperrors.ErrNotFound)
+		if car != nil {
+			t.Errorf("got car %+v with the error, want nil", car)
+		}
+	})
+
+	t.Run("CreateCar and GetCarById", func(t *testing.T) {
+		f, engine := setup(t)
+		request := carRequest("Corolla", "Toyota", engine.ID, "19999.99")
+		created, err := f.Cars.CreateCar(ctx, request)
+		if err != nil {
+			t.Fatalf("CreateCar: %v", err)
+		}
+		expectCar(t, created, request)
+		if created.Version != 1 {
+			t.Errorf("version = %d, want 1", created.Version)
+		}
+
+		got, err := f.Cars.GetCarById(ctx, created.ID.String())
+		if err != nil {
+			t.Fatalf("GetCarById: %v", err)
+		}
+		expectCar(t, got, request)
+		if got.Engine.ID != engine.ID {
+			t.Errorf("engine = %s, want %s loaded with the car", got.Engine.ID, engine.ID)
+		}
+	})
+
+	t.Run("CreateCar with a missing engine", func(t *testing.T) {
+		f, _ := setup(t)
+		_, err := f.Cars.CreateCar(ctx, carRequest("Corolla", "Toyota", uuid.New(), "1"))
+		expectKind(t, err, apperrors.ErrForeignKey)
+	})
+
+	t.Run("UpdateCar", func(t *testing.T) {
+		f, engine := setup(t)
+		created := createStoreCar(t, f, carRequest("Corolla", "Toyota", engine.ID, "100"))
+		other := createStoreEngine(t, f)
+
+		request := carRequest("Civic", "Honda", other.ID, "200")
+		request.FuelType = "Hybrid"
+		updated, err := f.Cars.UpdateCar(ctx, created.ID.String(), request)
+		if err != nil {
+			t.Fatalf("UpdateCar: %v", err)
+		}
+		expectCar(t, updated, request)
+		if updated.Version != created.Version+1 {
+			t.Errorf("version = %d, want %d", updated.Version, created.Version+1)
+		}
+
+		got, err := f.Cars.GetCarById(ctx, created.ID.String())
+		if err != nil {
+			t.Fatalf("GetCarById: %v", err)
+		}
+		expectCar(t, got, request)
+	})
+
+	t.Run("UpdateCar not found", func(t *testing.T) {
+		f, engine := setup(t)
+		_, err := f.Cars.UpdateCar(ctx, uuid.NewString(), carRequest("Corolla", "Toyota", engine.ID, "1"))
+		expectKind(t, err, apperrors.ErrNotFound)
+	})
+
+	t.Run("UpdateCar with a missing engine", func(t *testing.T) {
+		f, engine := setup(t)
+		created := createStoreCar(t, f, carRequest("Corolla", "Toyota", engine.ID, "1"))
+		_, err := f.Cars.UpdateCar(ctx, created.ID.String(), carRequest("Corolla", "Toyota", uuid.New(), "1"))
+		expectKind(t, err, apperrors.ErrForeignKey)
+	})
+
+	t.Run("DeleteCar", func(t *testing.T) {
+		f, engine := setup(t)
+		created := createStoreCar(t, f, carRequest("Corolla", "Toyota", engine.ID, "1"))
+
+		deleted, err := f.Cars.DeleteCar(ctx, created.ID.String())
+		if err != nil {
+			t.Fatalf("DeleteCar: %v", err)
+		}
+		if deleted.ID != created.ID {
+			t.Errorf("deleted car %s, want %s", deleted.ID, created.ID)
+		}
+
+		_, err = f.Cars.GetCarById(ctx, created.ID.String())
+		expectKind(t, err, apperrors.ErrNotFound)
+		_, err = f.Cars.DeleteCar(ctx, created.ID.String())
+		expectKind(t, err, apperrors.ErrNotFound)
+	})
+
+	t.Run("GetCarByBrand", func(t *testing.T) {
+		f, engine := setup(t)
+		corolla := createStoreCar(t, f, carRequest("Corolla", "Toyota", engine.ID, "1"))
+		yaris := createStoreCar(t, f, carRequest("Yaris", "Toyota", engine.ID, "1"))
+		createStoreCar(t, f, carRequest("Civic", "Honda", engine.ID, "1"))
+		deleted := createStoreCar(t, f, carRequest("Camry", "Toyota", engine.ID, "1"))
+		if _, err := f.Cars.DeleteCar(ctx, deleted.ID.String()); err != nil {
+			t.Fatalf("DeleteCar: %v", err)
+		}
+
+		cars, err := f.Cars.GetCarByBrand(ctx, "Toyota", false)
+		if err != nil {
+			t.Fatalf("GetCarByBrand: %v", err)
+		}
+		expectCarIDs(t, cars, corolla.ID, yaris.ID)
+		for _, car := range cars {
+			if car.Engine.ID != uuid.Nil {
+				t.Errorf("car %s has its engine loaded without isEngine", car.ID)
+			}
+		}
+
+		cars, err = f.Cars.GetCarByBrand(ctx, "Toyota", true)
+		if err != nil {
+			t.Fatalf("GetCarByBrand: %v", err)
+		}
+		for _, car := range cars {
+			if car.Engine.ID != engine.ID {
+				t.Errorf("car %s engine = %s, want %s", car.ID, car.Engine.ID, engine.ID)
+			}
+		}
+
+		cars, err = f.Cars.GetCarByBrand(ctx, "Ford", false)
+		if err != nil {
+			t.Fatalf("GetCarByBrand: %v", err)
+		}
+		expectCarIDs(t, cars)
+	})
+}
+
+// testEngineStore runs the engine store suite, calling newFixture for a
+// fresh implementation in each test.
+func testEngineStore(t *testing.T, newFixture func(t *testing.T) storeFixture) {
+	ctx := context.Background()
+
+	t.Run("GetEngineById not found", func(t *testing.T) {
+		f := newFixture(t)
+		_, err := f.Engines.GetEngineById(ctx, uuid.NewString())
+		expectKind(t, err, apperrors.ErrNotFound)
+	})
+
+	t.Run("CreateEngine and GetEngineById", func(t *testing.T) {
+		f := newFixture(t)
+		request := &models.EngineRequest{Displacement: 1998, NoOfCylinders: 4, CarRange: 600}
+		created, err := f.Engines.CreateEngine(ctx, request)
+		if err != nil {
+			t.Fatalf("CreateEngine: %v", err)
+		}
+		got, err := f.Engines.GetEngineById(ctx, created.ID.String())
+		if err != nil {
+			t.Fatalf("GetEngineById: %v", err)
+		}
+		expectEngine(t, got, request)
+		if got.Version != 1 {
+			t.Errorf("version = %d, want 1", got.Version)
+		}
+	})
+
+	t.Run("UpdateEngine", func(t *testing.T) {
+		f := newFixture(t)
+		created := createStoreEngine(t, f)
+		request := &models.EngineRequest{Displacement: 2998, NoOfCylinders: 6, CarRange: 450}
+		updated, err := f.Engines.UpdateEngine(ctx, created.ID.String(), request)
+		if err != nil {
+			t.Fatalf("UpdateEngine: %v", err)
+		}
+		expectEngine(t, updated, request)
+		if updated.Version != created.Version+1 {
+			t.Errorf("version = %d, want %d", updated.Version, created.Version+1)
+		}
+
+		got, err := f.Engines.GetEngineById(ctx, created.ID.String())
+		if err != nil {
+			t.Fatalf("GetEngineById: %v", err)
+		}
+		expectEngine(t, got, request)
+	})
+
+	t.Run("UpdateEngine not found", func(t *testing.T) {
+		f := newFixture(t)
+		_, err := f.Engines.UpdateEngine(ctx, uuid.NewString(), &models.EngineRequest{Displacement: 1, NoOfCylinders: 1})
+		expectKind(t, err, apperrors.ErrNotFound)
+	})
+
+	t.Run("DeleteEngine", func(t *testing.T) {
+		f := newFixture(t)
+		created := createStoreEngine(t, f)
+		deleted, err := f.Engines.DeleteEngine(ctx, created.ID.String())
+		if err != nil {
+			t.Fatalf("DeleteEngine: %v", err)
+		}
+		if deleted.ID != created.ID {
+			t.Errorf("deleted engine %s, want %s", deleted.ID, created.ID)
+		}
+
+		_, err = f.Engines.GetEngineById(ctx, created.ID.String())
+		expectKind(t, err, apperrors.ErrNotFound)
+		_, err = f.Engines.DeleteEngine(ctx, created.ID.String())
+		expectKind(t, err, apperrors.ErrNotFound)
+	})
+}
+
+func carRequest(name, brand string, engineID uuid.UUID, price string) *models.CarRequest {
+	return &models.CarRequest{
+		Name:     name,
+		Year:     "2020",
+		Brand:    brand,
+		FuelType: "Petrol",
+		EngineID: engineID.String(),
+		Price:    models.NewMoney(decimal.RequireFromString(price), models.DefaultCurrency),
+	}
+}
+
+func createStoreEngine(t *testing.T, f storeFixture) *models.Engine {
+	t.Helper()
+	engine, err := f.Engines.CreateEngine(context.Background(), &models.EngineRequest{Displacement: 1998, NoOfCylinders: 4, CarRange: 600})
+	if err != nil {
+		t.Fatalf("CreateEngine: %v", err)
+	}
+	return engine
+}
+
+func createStoreCar(t *testing.T, f storeFixture, request *models.CarRequest) *models.Car {
+	t.Helper()
+	car, err := f.Cars.CreateCar(context.Background(), request)
+	if err != nil {
+		t.Fatalf("CreateCar: %v", err)
+	}
+	return car
+}
+
+// expectKind fails the test unless err is a domain error of the given kind.
+func expectKind(t *testing.T, err error, kind error) {
+	t.Helper()
+	if !errors.Is(err, kind) {
+		t.Fatalf("error = %v, want %v", err, kind)
+	}
+}
+
+func expectCar(t *testing.T, car *models.Car, request *models.CarRequest) {
+	t.Helper()
+	if car.Name != request.Name || car.Year != request.Year || car.Brand != request.Brand ||
+		car.FuelType != request.FuelType || car.EngineID.String() != request.EngineID || !car.Price.Equal(request.Price) {
+		t.Errorf("car = %+v, want the fields of %+v", car, request)
+	}
+}
+
+func expectEngine(t *testing.T, engine *models.Engine, request *models.EngineRequest) {
+	t.Helper()
+	if engine.Displacement != request.Displacement || engine.NoOfCylinders != request.NoOfCylinders || engine.CarRange != request.CarRange {
+		t.Errorf("engine = %+v, want the fields of %+v", engine, request)
+	}
+}
+
+// expectCarIDs checks that cars are exactly the cars with the given IDs, in
+// any order.
+func expectCarIDs(t *testing.T, cars []models.Car, ids ...uuid.UUID) {
+	t.Helper()
+	want := map[uuid.UUID]bool{}
+	for _, id := range ids {
+		want[id] = true
+	}
+	got := map[uuid.UUID]bool{}
+	for _, car := range cars {
+		got[car.ID] = true
+	}
+	if len(cars) != len(ids) || len(got) != len(want) {
+		t.Fatalf("got %d cars %v, want %v", len(cars), keys(got), ids)
+	}
+	for id := range want {
+		if !got[id] {
+			t.Fatalf("got cars %v, want %v", keys(got), ids)
+		}
+	}
+}
+
+func keys(m map[uuid.UUID]bool) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	return ids
+}
@@ -2,23 +2,37 @@ package engineRepository
 
 import (
 	"context"
+	"log/slog"
+	"time"
 
 	"errors"
 
+	"github.com/Tushar456/go-carzone/apperrors"
 	"github.com/Tushar456/go-carzone/models"
 	"github.com/Tushar456/go-carzone/repository"
 	"github.com/google/uuid"
 	"go.opentelemetry.io/otel"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type EngineRepository struct {
-	repo *repository.Repository[models.Engine]
+	repo    *repository.Repository[models.Engine]
+	carRepo *repository.Repository[models.Car]
+
+	outboxRepo *repository.Repository[models.OutboxEvent]
+
+	logger *slog.Logger
 }
 
-func NewEngineRepository(db *gorm.DB) *EngineRepository {
+func NewEngineRepository(db *gorm.DB, logger *slog.Logger) *EngineRepository {
 	return &EngineRepository{
-		repo: repository.New[models.Engine](db),
+		repo:    repository.New[models.Engine](db),
+		carRepo: repository.New[models.Car](db),
+
+		outboxRepo: repository.New[models.OutboxEvent](db),
+
+		logger: logger,
 	}
 }
 
@@ -29,9 +43,7 @@ func (s *EngineRepository) GetEngineById(ctx context.Context, id string) (*model
 	var engine models.Engine
 	if err := s.repo.Get(ctx, &engine, "engine_id = ?", id); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			// Note: Returning an empty struct on "not found" can be misleading.
-			// Consider returning (nil, gorm.ErrRecordNotFound) or (nil, nil).
-			return &models.Engine{}, nil
+			return &models.Engine{}, apperrors.NotFound("engine not found")
 		}
 		return &models.Engine{}, err
 	}
@@ -43,10 +55,11 @@ func (s *EngineRepository) CreateEngine(ctx context.Context, engineRequest *mode
 	defer span.End()
 
 	engine := &models.Engine{
-		EngineID:      uuid.New(),
+		ID:            uuid.New(),
 		Displacement:  engineRequest.Displacement,
 		NoOfCylinders: engineRequest.NoOfCylinders,
 		CarRange:      engineRequest.CarRange,
+		Version:       1,
 	}
 
 	if err := s.repo.Create(ctx, engine); err != nil {
@@ -56,46 +69,191 @@ func (s *EngineRepository) CreateEngine(ctx context.Context, engineRequest *mode
 	return engine, nil
 }
 
-func (s *EngineRepository) UpdateEngine(ctx context.Context, id string, engineRequest *models.EngineRequest) (*models.Engine, error) {
+// UpdateEngine replaces the fields of an engine. A non-zero version must
+// match the stored one; the update also fails if the engine changes while it runs.
+func (s *EngineRepository) UpdateEngine(ctx context.Context, id string, engineRequest *models.EngineRequest, version int64) (*models.Engine, error) {
 	ctx, span := otel.Tracer("engineservice").Start(ctx, "UpdateEngine")
 	defer span.End()
 
 	var engine models.Engine
 	if err := s.repo.Get(ctx, &engine, "engine_id = ?", id); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return &models.Engine{}, errors.New("engine not found")
+			return &models.Engine{}, apperrors.NotFound("engine not found")
 		}
 		return &models.Engine{}, err
 	}
+	if version != 0 && engine.Version != version {
+		return &models.Engine{}, repository.ErrEngineModified
+	}
 
-	engine.Displacement = engineRequest.Displacement
-	engine.NoOfCylinders = engineRequest.NoOfCylinders
-	engine.CarRange = engineRequest.CarRange
+	updated, err := s.repo.UpdateColumns(ctx, map[string]interface{}{
+		"displacement":    engineRequest.Displacement,
+		"no_of_cylinders": engineRequest.NoOfCylinders,
+		"car_range":       engineRequest.CarRange,
+		"version":         gorm.Expr("version + 1"),
+	}, "engine_id = ? AND version = ?", engine.ID, engine.Version)
+	if err != nil {
+		return &models.Engine{}, err
+	}
+	if updated == 0 {
+		s.logger.DebugContext(ctx, "Engine changed during update", "engine_id", engine.ID, "version", engine.Version)
+		return &models.Engine{}, repository.ErrEngineModified
+	}
 
-	if err := s.repo.Update(ctx, &engine); err != nil {
+	if err := s.repo.Get(ctx, &engine, "engine_id = ?", engine.ID); err != nil {
+		return &models.Engine{}, err
+	}
+	err = repository.AddEvent(ctx, s.outboxRepo, models.EventEngineUpdated, models.AggregateEngine, engine.ID, &engine)
+	if err != nil {
 		return &models.Engine{}, err
 	}
 
 	return &engine, nil
 }
 
-func (s *EngineRepository) DeleteEngine(ctx context.Context, id string) (*models.Engine, error) {
+// DeleteEngine soft deletes an engine. A non-zero version must match the
+// stored one.
+func (s *EngineRepository) DeleteEngine(ctx context.Context, id string, version int64) (*models.Engine, error) {
 	ctx, span := otel.Tracer("engineservice").Start(ctx, "DeleteEngine")
 	defer span.End()
 
 	var engine models.Engine
-	// The original implementation had a bug here using "id = ?" instead of "engine_id = ?".
-	// I've corrected it to use the correct primary key column.
 	if err := s.repo.Get(ctx, &engine, "engine_id = ?", id); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return &models.Engine{}, errors.New("engine not found")
+			return &models.Engine{}, apperrors.NotFound("engine not found")
 		}
 		return &models.Engine{}, err
 	}
+	if version != 0 && engine.Version != version {
+		return &models.Engine{}, repository.ErrEngineModified
+	}
 
-	if err := s.repo.Delete(ctx, &engine); err != nil {
+	deleted, err := s.repo.DeleteWhere(ctx, "engine_id = ? AND version = ?", engine.ID, engine.Version)
+	if err != nil {
 		return &models.Engine{}, err
 	}
+	if deleted == 0 {
+		s.logger.DebugContext(ctx, "Engine changed during delete", "engine_id", engine.ID, "version", engine.Version)
+		return &models.Engine{}, repository.ErrEngineModified
+	}
 
 	return &engine, nil
 }
+
+func (s *EngineRepository) RestoreEngine(ctx context.Context, id string) (*models.Engine, error) {
+	ctx, span := otel.Tracer("engineservice").Start(ctx, "RestoreEngine")
+	defer span.End()
+
+	restored, err := s.repo.Restore(ctx, "engine_id = ?", id)
+	if err != nil {
+		return &models.Engine{}, err
+	}
+	if restored == 0 {
+		return &models.Engine{}, apperrors.NotFound("deleted engine not found")
+	}
+
+	var engine models.Engine
+	if err := s.repo.Get(ctx, &engine, "engine_id = ?", id); err != nil {
+		return &models.Engine{}, err
+	}
+	return &engine, nil
+}
+
+// PurgeDeletedEngines permanently removes the engines soft deleted before
+// the given time and returns them as they were. It should run in a
+// transaction.
+func (s *EngineRepository) PurgeDeletedEngines(ctx context.Context, before time.Time) ([]models.Engine, error) {
+	ctx, span := otel.Tracer("engineservice").Start(ctx, "PurgeDeletedEngines")
+	defer span.End()
+
+	// Engines still referenced by a car, even a soft deleted one, are kept
+	// until the car itself has been purged.
+	var engines []models.Engine
+	err := s.repo.Unscoped().FindPage(ctx, &engines, nil, "engine_id", 0, 0, func(db *gorm.DB) *gorm.DB {
+		return db.Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+			Where("NOT EXISTS (SELECT 1 FROM cars WHERE cars.engine_id = engines.engine_id)").
+			Clauses(clause.Locking{Strength: "UPDATE"})
+	})
+	if err != nil || len(engines) == 0 {
+		return engines, err
+	}
+
+	ids := make([]uuid.UUID, len(engines))
+	for i := range engines {
+		ids[i] = engines[i].ID
+	}
+	if _, err := s.repo.Purge(ctx, before, func(db *gorm.DB) *gorm.DB {
+		return db.Where("engine_id IN ?", ids)
+	}); err != nil {
+		return nil, err
+	}
+	s.logger.DebugContext(ctx, "Purged deleted engines", "before", before, "count", len(engines))
+	return engines, nil
+}
+
+// CountEngines returns the number of engines, soft deleted ones excluded.
+func (s *EngineRepository) CountEngines(ctx context.Context) (int64, error) {
+	ctx, span := otel.Tracer("engineservice").Start(ctx, "CountEngines")
+	defer span.End()
+
+	return s.repo.Count(ctx)
+}
+
+// GetReferencingCarIDs returns the IDs of the cars that use the engine.
+func (s *EngineRepository) GetReferencingCarIDs(ctx context.Context, id string) ([]uuid.UUID, error) {
+	ctx, span := otel.Tracer("engineservice").Start(ctx, "GetReferencingCarIDs")
+	defer span.End()
+
+	var cars []models.Car
+	if err := s.carRepo.Find(ctx, &cars, "engine_id = ?", id); err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, len(cars))
+	for i, car := range cars {
+		ids[i] = car.ID
+	}
+	return ids, nil
+}
+
+// ReassignCars moves every car of one engine, soft deleted ones included, to
+// another engine and returns the IDs of the cars moved, in order.
+func (s *EngineRepository) ReassignCars(ctx context.Context, fromID string, toID string) ([]uuid.UUID, error) {
+	ctx, span := otel.Tracer("engineservice").Start(ctx, "ReassignCars")
+	defer span.End()
+
+	var cars []models.Car
+	err := s.carRepo.Unscoped().FindPage(ctx, &cars, nil, "id", 0, 0, func(db *gorm.DB) *gorm.DB {
+		return db.Where("engine_id = ?", fromID).Clauses(clause.Locking{Strength: "UPDATE"})
+	})
+	if err != nil {
+		return nil, err
+	}
+	carIDs := make([]uuid.UUID, len(cars))
+	for i, car := range cars {
+		carIDs[i] = car.ID
+	}
+	if len(carIDs) == 0 {
+		return carIDs, nil
+	}
+
+	if _, err := s.carRepo.Unscoped().UpdateColumns(ctx, map[string]interface{}{
+		"engine_id": toID,
+		"version":   gorm.Expr("version + 1"),
+	}, "id IN ?", carIDs); err != nil {
+		return nil, err
+	}
+
+	cars = nil
+	if err := s.carRepo.Unscoped().FindWithPreload(ctx, &cars, []string{"Engine"}, "id IN ?", carIDs); err != nil {
+		return nil, err
+	}
+	for i := range cars {
+		err := repository.AddEvent(ctx, s.outboxRepo, models.EventCarUpdated, models.AggregateCar, cars[i].ID, &cars[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+	s.logger.DebugContext(ctx, "Reassigned cars", "from", fromID, "to", toID, "count", len(carIDs))
+	return carIDs, nil
+}
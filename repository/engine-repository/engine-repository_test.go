@@ -0,0 +1,19 @@
+package engineRepository_test
+
+import (
+	"testing"
+
+	carRepository "github.com/Tushar456/go-carzone/repository/car-repository"
+	"github.com/Tushar456/go-carzone/repository/contract"
+	engineRepository "github.com/Tushar456/go-carzone/repository/engine-repository"
+)
+
+var newFixture = contract.NewGORMFixture(carRepository.NewCarRepository, engineRepository.NewEngineRepository)
+
+func TestEngineRepository(t *testing.T) {
+	contract.TestEngineRepository(t, newFixture)
+}
+
+func TestTransactor(t *testing.T) {
+	contract.TestTransactor(t, newFixture)
+}
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/Tushar456/go-carzone/apperrors"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUserNotFound  = apperrors.NotFound("user not found")
+	ErrTokenNotFound = apperrors.NotFound("token not found")
+
+	ErrCarModified    = apperrors.PreconditionFailed("car has been modified since it was read")
+	ErrEngineModified = apperrors.PreconditionFailed("engine has been modified since it was read")
+
+	ErrCarEngineDeleted = apperrors.ForeignKey("engine_id", "the car's engine is deleted; restore the engine first")
+)
+
+// translateError turns constraint violations reported by gorm into domain errors.
+func translateError(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return apperrors.Wrap(err, apperrors.Conflict("record already exists"))
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		return apperrors.Wrap(err, apperrors.ForeignKey("", "record references a missing record or is still referenced"))
+	}
+	return err
+}
@@ -2,21 +2,99 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/Tushar456/go-carzone/models"
+	"github.com/google/uuid"
 )
 
+// TransactorInterface runs fn in a transaction carried by the context it is
+// given; repository calls made with that context are part of it.
+type TransactorInterface interface {
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
 type CarRepositoryInterface interface {
 	GetCarById(ctx context.Context, id string) (*models.Car, error)
 	GetCarByBrand(ctx context.Context, brand string, isEngine bool) ([]models.Car, error)
+	ListCars(ctx context.Context, filter *models.CarFilter) (*models.Page[models.Car], error)
+	SearchCars(ctx context.Context, query *models.CarSearchQuery) (*models.Page[models.CarSearchResult], error)
 	CreateCar(ctx context.Context, car *models.CarRequest) (*models.Car, error)
-	UpdateCar(ctx context.Context, id string, updateCar *models.CarRequest) (*models.Car, error)
-	DeleteCar(ctx context.Context, id string) (*models.Car, error)
+	CreateCars(ctx context.Context, cars []*models.CarRequest, batchSize int) ([]models.Car, error)
+	ExistingEngineIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]bool, error)
+	ResolveBrands(ctx context.Context, names []string) (map[string]*models.Brand, error)
+	UpdateCar(ctx context.Context, id string, updateCar *models.CarRequest, version int64) (*models.Car, error)
+	GetPriceHistory(ctx context.Context, id string) ([]models.CarPriceHistory, error)
+	DeleteCar(ctx context.Context, id string, version int64) (*models.Car, error)
+	RestoreCar(ctx context.Context, id string) (*models.Car, error)
+	PurgeDeletedCars(ctx context.Context, before time.Time) ([]models.Car, error)
+	CountCarsByBrand(ctx context.Context) (map[string]int64, error)
 }
 
 type EngineRepositoryInterface interface {
 	GetEngineById(ctx context.Context, id string) (*models.Engine, error)
 	CreateEngine(ctx context.Context, engine *models.EngineRequest) (*models.Engine, error)
-	UpdateEngine(ctx context.Context, id string, updateEngine *models.EngineRequest) (*models.Engine, error)
-	DeleteEngine(ctx context.Context, id string) (*models.Engine, error)
+	UpdateEngine(ctx context.Context, id string, updateEngine *models.EngineRequest, version int64) (*models.Engine, error)
+	DeleteEngine(ctx context.Context, id string, version int64) (*models.Engine, error)
+	RestoreEngine(ctx context.Context, id string) (*models.Engine, error)
+	PurgeDeletedEngines(ctx context.Context, before time.Time) ([]models.Engine, error)
+	GetReferencingCarIDs(ctx context.Context, id string) ([]uuid.UUID, error)
+	ReassignCars(ctx context.Context, fromID string, toID string) ([]uuid.UUID, error)
+	CountEngines(ctx context.Context) (int64, error)
+}
+
+type BrandRepositoryInterface interface {
+	GetBrandById(ctx context.Context, id string) (*models.Brand, error)
+	ListBrands(ctx context.Context, limit, offset int) (*models.Page[models.Brand], error)
+	ResolveBrand(ctx context.Context, name string) (*models.Brand, error)
+	ResolveBrands(ctx context.Context, names []string) (map[string]*models.Brand, error)
+	CreateBrand(ctx context.Context, brand *models.BrandRequest) (*models.Brand, error)
+	UpdateBrand(ctx context.Context, id string, updateBrand *models.BrandRequest) (*models.Brand, []models.RenamedCar, error)
+	DeleteBrand(ctx context.Context, id string) (*models.Brand, error)
+}
+
+type AuditRepositoryInterface interface {
+	CreateAuditEntry(ctx context.Context, entry *models.AuditEntry) error
+	ListAuditEntries(ctx context.Context, filter *models.AuditFilter) (*models.Page[models.AuditEntry], error)
+}
+
+type OutboxRepositoryInterface interface {
+	ClaimEvents(ctx context.Context, limit int, now time.Time, lease time.Duration) ([]models.OutboxEvent, error)
+	ReleaseEvents(ctx context.Context, events []models.OutboxEvent, at time.Time) error
+	MarkPublished(ctx context.Context, event *models.OutboxEvent, at time.Time) error
+	MarkFailed(ctx context.Context, event *models.OutboxEvent, nextAttemptAt time.Time, cause error) error
+	PurgePublished(ctx context.Context, before time.Time) (int64, error)
+}
+
+type WebhookRepositoryInterface interface {
+	GetSubscriptionById(ctx context.Context, id string) (*models.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error)
+	ListActiveSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error)
+	CreateSubscription(ctx context.Context, subscription *models.WebhookSubscription) (*models.WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, subscription *models.WebhookSubscription) (*models.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id string) (*models.WebhookSubscription, error)
+	CreateDeliveries(ctx context.Context, deliveries []models.WebhookDelivery) error
+	ClaimDeliveries(ctx context.Context, limit int, now time.Time, lease time.Duration) ([]models.WebhookDelivery, error)
+	SaveDeliveryAttempt(ctx context.Context, delivery *models.WebhookDelivery) error
+	ListDeliveries(ctx context.Context, subscriptionID string, filter *models.WebhookDeliveryFilter) (*models.Page[models.WebhookDelivery], error)
+	Redeliver(ctx context.Context, subscriptionID string, deliveryID string) (*models.WebhookDelivery, error)
+}
+
+type UserRepositoryInterface interface {
+	GetUserById(ctx context.Context, id uuid.UUID) (*models.User, error)
+	GetUserByUsername(ctx context.Context, username string) (*models.User, error)
+	CreateUser(ctx context.Context, user *models.User) (*models.User, error)
+	UpdatePassword(ctx context.Context, username string, passwordHash string) error
+	UpdateRole(ctx context.Context, username string, role string) (*models.User, error)
+}
+
+type TokenRepositoryInterface interface {
+	CreateRefreshToken(ctx context.Context, token *models.RefreshToken) error
+	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error)
+	RevokeRefreshToken(ctx context.Context, id uuid.UUID, replacedBy *uuid.UUID) (bool, error)
+	RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID) error
+	RevokeUserRefreshTokens(ctx context.Context, userID uuid.UUID) error
+	RevokeAccessToken(ctx context.Context, token *models.RevokedToken) error
+	IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error)
+	PurgeExpiredTokens(ctx context.Context, before time.Time) (int64, error)
 }
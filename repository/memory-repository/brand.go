@@ -0,0 +1,262 @@
+package memoryRepository
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/Tushar456/go-carzone/apperrors"
+	"github.com/Tushar456/go-carzone/models"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+)
+
+type BrandRepository struct {
+	db     *DB
+	logger *slog.Logger
+}
+
+func NewBrandRepository(db *DB, logger *slog.Logger) *BrandRepository {
+	return &BrandRepository{db: db, logger: logger}
+}
+
+func (s *BrandRepository) GetBrandById(ctx context.Context, id string) (*models.Brand, error) {
+	_, span := otel.Tracer("brandservice").Start(ctx, "GetBrandById")
+	defer span.End()
+
+	var brand models.Brand
+	err := s.db.read(func() error {
+		found, ok := s.db.brand(id)
+		if !ok {
+			return apperrors.NotFound("brand not found")
+		}
+		brand = found
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &brand, nil
+}
+
+func (s *BrandRepository) ListBrands(ctx context.Context, limit, offset int) (*models.Page[models.Brand], error) {
+	_, span := otel.Tracer("brandservice").Start(ctx, "ListBrands")
+	defer span.End()
+
+	brands := []models.Brand{}
+	s.db.read(func() error {
+		for _, brand := range s.db.brands {
+			brands = append(brands, brand)
+		}
+		return nil
+	})
+	sort.Slice(brands, func(i, j int) bool {
+		if brands[i].Name != brands[j].Name {
+			return brands[i].Name < brands[j].Name
+		}
+		return brands[i].ID.String() < brands[j].ID.String()
+	})
+
+	return &models.Page[models.Brand]{
+		Items:  page(brands, limit, offset),
+		Total:  int64(len(brands)),
+		Limit:  limit,
+		Offset: offset,
+	}, nil
+}
+
+// ResolveBrand returns the brand whose name or alias matches name once both
+// are normalised.
+func (s *BrandRepository) ResolveBrand(ctx context.Context, name string) (*models.Brand, error) {
+	_, span := otel.Tracer("brandservice").Start(ctx, "ResolveBrand")
+	defer span.End()
+
+	var brand *models.Brand
+	err := s.db.read(func() error {
+		var err error
+		brand, err = s.db.resolveBrand(name)
+		return err
+	})
+	if err != nil {
+		return nil, apperrors.NotFound("brand not found")
+	}
+	return brand, nil
+}
+
+func (s *BrandRepository) ResolveBrands(ctx context.Context, names []string) (map[string]*models.Brand, error) {
+	_, span := otel.Tracer("brandservice").Start(ctx, "ResolveBrands")
+	defer span.End()
+
+	var resolved map[string]*models.Brand
+	s.db.read(func() error {
+		resolved = s.db.resolveBrands(names)
+		return nil
+	})
+	return resolved, nil
+}
+
+func (s *BrandRepository) CreateBrand(ctx context.Context, brandRequest *models.BrandRequest) (*models.Brand, error) {
+	ctx, span := otel.Tracer("brandservice").Start(ctx, "CreateBrand")
+	defer span.End()
+
+	id := uuid.New()
+	now := time.Now()
+	brand := models.Brand{
+		ID:             id,
+		Name:           brandRequest.Name,
+		NormalizedName: models.NormalizeBrandName(brandRequest.Name),
+		Country:        brandRequest.Country,
+		LogoURL:        brandRequest.LogoURL,
+		Aliases:        brandAliases(id, brandRequest.Aliases),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	err := s.db.write(ctx, func() error {
+		if err := s.db.checkBrandUnique(brand); err != nil {
+			return err
+		}
+		s.db.brands[brand.ID] = brand
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &brand, nil
+}
+
+// UpdateBrand replaces the fields and aliases of a brand and renames its
+// cars, which it returns before and after the rename.
+func (s *BrandRepository) UpdateBrand(ctx context.Context, id string, brandRequest *models.BrandRequest) (*models.Brand, []models.RenamedCar, error) {
+	ctx, span := otel.Tracer("brandservice").Start(ctx, "UpdateBrand")
+	defer span.End()
+
+	var brand models.Brand
+	var renamed []models.RenamedCar
+	err := s.db.write(ctx, func() error {
+		found, ok := s.db.brand(id)
+		if !ok {
+			return apperrors.NotFound("brand not found")
+		}
+
+		brand = found
+		brand.Name = brandRequest.Name
+		brand.NormalizedName = models.NormalizeBrandName(brandRequest.Name)
+		brand.Country = brandRequest.Country
+		brand.LogoURL = brandRequest.LogoURL
+		brand.Aliases = brandAliases(brand.ID, brandRequest.Aliases)
+		brand.UpdatedAt = time.Now()
+		if err := s.db.checkBrandUnique(brand); err != nil {
+			return err
+		}
+		s.db.brands[brand.ID] = brand
+
+		if found.Name == brand.Name {
+			return nil
+		}
+		// Cars keep a copy of the brand name for filtering, sorting and search.
+		for carID, car := range s.db.cars {
+			if car.BrandID != brand.ID {
+				continue
+			}
+			before := s.db.withEngine(car)
+			car.Brand = brand.Name
+			car.Version++
+			s.db.cars[carID] = car
+
+			car = s.db.withEngine(car)
+			renamed = append(renamed, models.RenamedCar{Before: before, After: car})
+			if err := s.db.addCarEvent(ctx, models.EventCarUpdated, &car); err != nil {
+				return err
+			}
+		}
+		s.logger.DebugContext(ctx, "Renamed brand on cars", "brand_id", brand.ID, "from", found.Name, "to", brand.Name, "cars", len(renamed))
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Slice(renamed, func(i, j int) bool { return renamed[i].After.ID.String() < renamed[j].After.ID.String() })
+	return &brand, renamed, nil
+}
+
+// DeleteBrand deletes a brand that no car, deleted or not, references.
+func (s *BrandRepository) DeleteBrand(ctx context.Context, id string) (*models.Brand, error) {
+	ctx, span := otel.Tracer("brandservice").Start(ctx, "DeleteBrand")
+	defer span.End()
+
+	var brand models.Brand
+	err := s.db.write(ctx, func() error {
+		found, ok := s.db.brand(id)
+		if !ok {
+			return apperrors.NotFound("brand not found")
+		}
+
+		var cars int64
+		for _, car := range s.db.cars {
+			if car.BrandID == found.ID {
+				cars++
+			}
+		}
+		if cars > 0 {
+			return apperrors.Conflict("brand is still used by cars").
+				WithDetails(map[string]interface{}{"car_count": cars})
+		}
+
+		delete(s.db.brands, found.ID)
+		brand = found
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &brand, nil
+}
+
+// brand returns the brand with the given ID. The caller holds a lock.
+func (db *DB) brand(id string) (models.Brand, bool) {
+	brandID, err := uuid.Parse(id)
+	if err != nil {
+		return models.Brand{}, false
+	}
+	brand, ok := db.brands[brandID]
+	return brand, ok
+}
+
+// checkBrandUnique mirrors the unique indexes on brand names and alias
+// names, ignoring the stored copy of brand itself. The caller holds a lock.
+func (db *DB) checkBrandUnique(brand models.Brand) error {
+	aliases := map[string]bool{}
+	for _, alias := range brand.Aliases {
+		if aliases[alias.NormalizedName] {
+			return apperrors.Conflict("record already exists")
+		}
+		aliases[alias.NormalizedName] = true
+	}
+	for _, existing := range db.brands {
+		if existing.ID == brand.ID {
+			continue
+		}
+		if existing.NormalizedName == brand.NormalizedName {
+			return apperrors.Conflict("record already exists")
+		}
+		for _, alias := range existing.Aliases {
+			if aliases[alias.NormalizedName] {
+				return apperrors.Conflict("record already exists")
+			}
+		}
+	}
+	return nil
+}
+
+func brandAliases(brandID uuid.UUID, names []string) []models.BrandAlias {
+	aliases := make([]models.BrandAlias, len(names))
+	for i, name := range names {
+		aliases[i] = models.BrandAlias{
+			NormalizedName: models.NormalizeBrandName(name),
+			Name:           name,
+			BrandID:        brandID,
+		}
+	}
+	return aliases
+}
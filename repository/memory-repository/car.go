@@ -0,0 +1,623 @@
+package memoryRepository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Tushar456/go-carzone/apperrors"
+	"github.com/Tushar456/go-carzone/models"
+	"github.com/Tushar456/go-carzone/repository"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.opentelemetry.io/otel"
+	"gorm.io/gorm"
+)
+
+type CarRepository struct {
+	db     *DB
+	logger *slog.Logger
+}
+
+func NewCarRepository(db *DB, logger *slog.Logger) *CarRepository {
+	return &CarRepository{db: db, logger: logger}
+}
+
+func (s *CarRepository) GetCarById(ctx context.Context, id string) (*models.Car, error) {
+	_, span := otel.Tracer("carservice").Start(ctx, "GetCarById")
+	defer span.End()
+
+	var car models.Car
+	err := s.db.read(func() error {
+		found, ok := s.db.liveCar(id)
+		if !ok {
+			return apperrors.NotFound("car not found")
+		}
+		car = s.db.withEngine(found)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &car, nil
+}
+
+func (s *CarRepository) GetCarByBrand(ctx context.Context, brand string, isEngine bool) ([]models.Car, error) {
+	_, span := otel.Tracer("carservice").Start(ctx, "GetCarByBrand")
+	defer span.End()
+
+	cars := []models.Car{}
+	s.db.read(func() error {
+		key := models.NormalizeBrandName(brand)
+		for _, car := range s.db.cars {
+			if car.DeletedAt.Valid || !s.db.hasBrand(car, key) {
+				continue
+			}
+			if isEngine {
+				car = s.db.withEngine(car)
+			}
+			cars = append(cars, car)
+		}
+		return nil
+	})
+	return cars, nil
+}
+
+func (s *CarRepository) ListCars(ctx context.Context, filter *models.CarFilter) (*models.Page[models.Car], error) {
+	_, span := otel.Tracer("carservice").Start(ctx, "ListCars")
+	defer span.End()
+
+	var cursor *models.PageCursor
+	if filter.Cursor != "" {
+		var err error
+		if cursor, err = models.DecodeCursor(filter.Cursor); err != nil {
+			return nil, err
+		}
+		if _, err := carCursorValue(filter.SortBy, cursor.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	var matched []models.Car
+	s.db.read(func() error {
+		for _, car := range s.db.cars {
+			if s.db.matches(car, filter) {
+				if filter.IsEngine {
+					car = s.db.withEngine(car)
+				}
+				matched = append(matched, car)
+			}
+		}
+		return nil
+	})
+	total := int64(len(matched))
+
+	desc := filter.SortOrder == "desc"
+	sort.Slice(matched, func(i, j int) bool {
+		c := compareCars(&matched[i], &matched[j], filter.SortBy)
+		if desc {
+			return c > 0
+		}
+		return c < 0
+	})
+
+	offset := filter.Offset
+	if cursor != nil {
+		// Keyset pagination: continue strictly after the last row of the previous page.
+		start := len(matched)
+		for i := range matched {
+			c := compareCarToCursor(&matched[i], filter.SortBy, cursor)
+			if (!desc && c > 0) || (desc && c < 0) {
+				start = i
+				break
+			}
+		}
+		matched = matched[start:]
+		offset = 0
+	}
+
+	cars := page(matched, filter.Limit, offset)
+	result := &models.Page[models.Car]{
+		Items:  cars,
+		Total:  total,
+		Limit:  filter.Limit,
+		Offset: offset,
+	}
+
+	if len(cars) > 0 && offset+len(cars) < len(matched) {
+		last := cars[len(cars)-1]
+		result.NextCursor = models.EncodeCursor(models.PageCursor{
+			Value: carSortValue(&last, filter.SortBy),
+			ID:    last.ID.String(),
+		})
+	}
+
+	return result, nil
+}
+
+func (s *CarRepository) CreateCar(ctx context.Context, carRequest *models.CarRequest) (*models.Car, error) {
+	ctx, span := otel.Tracer("carservice").Start(ctx, "CreateCar")
+	defer span.End()
+
+	var car models.Car
+	err := s.db.write(ctx, func() error {
+		engine, ok := s.db.liveEngine(carRequest.EngineID)
+		if !ok {
+			return apperrors.ForeignKey("engine_id", "engine not found")
+		}
+		brand, err := s.db.resolveBrand(carRequest.Brand)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		car = models.Car{
+			ID:        uuid.New(),
+			Name:      carRequest.Name,
+			Year:      carRequest.Year,
+			Brand:     brand.Name,
+			BrandID:   brand.ID,
+			FuelType:  carRequest.FuelType,
+			EngineID:  engine.ID,
+			Price:     carRequest.Price,
+			Version:   1,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		s.db.cars[car.ID] = car
+		car = s.db.withEngine(car)
+		return s.db.addCarEvent(ctx, models.EventCarCreated, &car)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &car, nil
+}
+
+// CreateCars inserts cars all at once; batchSize has no meaning in memory.
+// Like the foreign key does in the database, it fails if an engine does not
+// exist.
+func (s *CarRepository) CreateCars(ctx context.Context, carRequests []*models.CarRequest, batchSize int) ([]models.Car, error) {
+	ctx, span := otel.Tracer("carservice").Start(ctx, "CreateCars")
+	defer span.End()
+
+	cars := make([]models.Car, len(carRequests))
+	err := s.db.write(ctx, func() error {
+		names := make([]string, len(carRequests))
+		for i, carRequest := range carRequests {
+			names[i] = carRequest.Brand
+		}
+		brands := s.db.resolveBrands(names)
+
+		now := time.Now()
+		for i, carRequest := range carRequests {
+			engineID, err := uuid.Parse(carRequest.EngineID)
+			if err != nil {
+				return apperrors.Validation("engine_id", "engine id must be a valid UUID")
+			}
+			brand, ok := brands[models.NormalizeBrandName(carRequest.Brand)]
+			if !ok {
+				return unknownBrand(carRequest.Brand)
+			}
+			if _, ok := s.db.engines[engineID]; !ok {
+				return apperrors.ForeignKey("", "record references a missing record or is still referenced")
+			}
+			cars[i] = models.Car{
+				ID:        uuid.New(),
+				Name:      carRequest.Name,
+				Year:      carRequest.Year,
+				Brand:     brand.Name,
+				BrandID:   brand.ID,
+				FuelType:  carRequest.FuelType,
+				EngineID:  engineID,
+				Price:     carRequest.Price,
+				Version:   1,
+				CreatedAt: now,
+				UpdatedAt: now,
+			}
+		}
+		for _, car := range cars {
+			s.db.cars[car.ID] = car
+		}
+		for i := range cars {
+			if err := s.db.addCarEvent(ctx, models.EventCarCreated, &cars[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.logger.DebugContext(ctx, "Inserted cars", "cars", len(cars), "batch_size", batchSize)
+	return cars, nil
+}
+
+func (s *CarRepository) ExistingEngineIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]bool, error) {
+	_, span := otel.Tracer("carservice").Start(ctx, "ExistingEngineIDs")
+	defer span.End()
+
+	existing := make(map[uuid.UUID]bool, len(ids))
+	s.db.read(func() error {
+		for _, id := range ids {
+			if engine, ok := s.db.engines[id]; ok && !engine.DeletedAt.Valid {
+				existing[id] = true
+			}
+		}
+		return nil
+	})
+	return existing, nil
+}
+
+func (s *CarRepository) ResolveBrands(ctx context.Context, names []string) (map[string]*models.Brand, error) {
+	_, span := otel.Tracer("carservice").Start(ctx, "ResolveBrands")
+	defer span.End()
+
+	var resolved map[string]*models.Brand
+	s.db.read(func() error {
+		resolved = s.db.resolveBrands(names)
+		return nil
+	})
+	return resolved, nil
+}
+
+func (s *CarRepository) UpdateCar(ctx context.Context, id string, updateCarRequest *models.CarRequest, version int64) (*models.Car, error) {
+	ctx, span := otel.Tracer("carservice").Start(ctx, "UpdateCar")
+	defer span.End()
+
+	var car models.Car
+	err := s.db.write(ctx, func() error {
+		found, ok := s.db.liveCar(id)
+		if !ok {
+			return apperrors.NotFound("car not found")
+		}
+		if version != 0 && found.Version != version {
+			return repository.ErrCarModified
+		}
+
+		engineID, err := uuid.Parse(updateCarRequest.EngineID)
+		if err != nil {
+			return apperrors.Validation("engine_id", "engine id must be a valid UUID")
+		}
+		if _, ok := s.db.liveEngine(engineID.String()); !ok {
+			return apperrors.ForeignKey("engine_id", "engine not found")
+		}
+		brand, err := s.db.resolveBrand(updateCarRequest.Brand)
+		if err != nil {
+			return err
+		}
+
+		car = found
+		car.Name = updateCarRequest.Name
+		car.Year = updateCarRequest.Year
+		car.Brand = brand.Name
+		car.BrandID = brand.ID
+		car.FuelType = updateCarRequest.FuelType
+		car.Price = updateCarRequest.Price
+		car.EngineID = engineID
+		car.Version++
+		car.UpdatedAt = time.Now()
+		s.db.cars[car.ID] = car
+
+		if !found.Price.Equal(updateCarRequest.Price) {
+			s.db.priceHistory = append(s.db.priceHistory, models.CarPriceHistory{
+				ID:            uuid.New(),
+				CarID:         car.ID,
+				Price:         updateCarRequest.Price,
+				PreviousPrice: found.Price,
+				ChangedAt:     car.UpdatedAt,
+			})
+		}
+
+		car = s.db.withEngine(car)
+		return s.db.addCarEvent(ctx, models.EventCarUpdated, &car)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &car, nil
+}
+
+// GetPriceHistory returns the price changes of a car, most recent first.
+func (s *CarRepository) GetPriceHistory(ctx context.Context, id string) ([]models.CarPriceHistory, error) {
+	_, span := otel.Tracer("carservice").Start(ctx, "GetPriceHistory")
+	defer span.End()
+
+	history := []models.CarPriceHistory{}
+	err := s.db.read(func() error {
+		car, ok := s.db.liveCar(id)
+		if !ok {
+			return apperrors.NotFound("car not found")
+		}
+		// Changes are appended in order, so walking backwards gives the most
+		// recent first.
+		for i := len(s.db.priceHistory) - 1; i >= 0; i-- {
+			if s.db.priceHistory[i].CarID == car.ID {
+				history = append(history, s.db.priceHistory[i])
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// DeleteCar soft deletes a car. A non-zero version must match the stored one.
+func (s *CarRepository) DeleteCar(ctx context.Context, id string, version int64) (*models.Car, error) {
+	ctx, span := otel.Tracer("carservice").Start(ctx, "DeleteCar")
+	defer span.End()
+
+	var car models.Car
+	err := s.db.write(ctx, func() error {
+		found, ok := s.db.liveCar(id)
+		if !ok {
+			return apperrors.NotFound("car not found")
+		}
+		if version != 0 && found.Version != version {
+			return repository.ErrCarModified
+		}
+
+		car = s.db.withEngine(found)
+		found.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+		s.db.cars[found.ID] = found
+		return s.db.addCarEvent(ctx, models.EventCarDeleted, &car)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &car, nil
+}
+
+func (s *CarRepository) RestoreCar(ctx context.Context, id string) (*models.Car, error) {
+	ctx, span := otel.Tracer("carservice").Start(ctx, "RestoreCar")
+	defer span.End()
+
+	var car models.Car
+	err := s.db.write(ctx, func() error {
+		carID, err := uuid.Parse(id)
+		found, ok := s.db.cars[carID]
+		if err != nil || !ok || !found.DeletedAt.Valid {
+			return apperrors.NotFound("deleted car not found")
+		}
+		if _, ok := s.db.liveEngine(found.EngineID.String()); !ok {
+			return repository.ErrCarEngineDeleted
+		}
+
+		found.DeletedAt = gorm.DeletedAt{}
+		s.db.cars[found.ID] = found
+		car = s.db.withEngine(found)
+		return s.db.addCarEvent(ctx, models.EventCarRestored, &car)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &car, nil
+}
+
+// PurgeDeletedCars permanently removes the cars soft deleted before the
+// given time and returns them as they were.
+func (s *CarRepository) PurgeDeletedCars(ctx context.Context, before time.Time) ([]models.Car, error) {
+	ctx, span := otel.Tracer("carservice").Start(ctx, "PurgeDeletedCars")
+	defer span.End()
+
+	var purged []models.Car
+	s.db.write(ctx, func() error {
+		for id, car := range s.db.cars {
+			if car.DeletedAt.Valid && car.DeletedAt.Time.Before(before) {
+				purged = append(purged, s.db.withEngine(car))
+				delete(s.db.cars, id)
+			}
+		}
+		return nil
+	})
+	sort.Slice(purged, func(i, j int) bool { return purged[i].ID.String() < purged[j].ID.String() })
+	s.logger.DebugContext(ctx, "Purged deleted cars", "before", before, "count", len(purged))
+	return purged, nil
+}
+
+// CountCarsByBrand returns the number of cars of each brand, soft deleted
+// cars excluded.
+func (s *CarRepository) CountCarsByBrand(ctx context.Context) (map[string]int64, error) {
+	_, span := otel.Tracer("carservice").Start(ctx, "CountCarsByBrand")
+	defer span.End()
+
+	counts := map[string]int64{}
+	s.db.read(func() error {
+		for _, brand := range s.db.brands {
+			counts[brand.Name] = 0
+		}
+		for _, car := range s.db.cars {
+			if brand, ok := s.db.brands[car.BrandID]; ok && !car.DeletedAt.Valid {
+				counts[brand.Name]++
+			}
+		}
+		return nil
+	})
+	return counts, nil
+}
+
+func unknownBrand(name string) error {
+	return apperrors.ForeignKey("brand", fmt.Sprintf("brand %q is not in the catalog", name))
+}
+
+// liveCar returns the car with the given ID unless it is soft deleted. The
+// caller holds a lock.
+func (db *DB) liveCar(id string) (models.Car, bool) {
+	carID, err := uuid.Parse(id)
+	if err != nil {
+		return models.Car{}, false
+	}
+	car, ok := db.cars[carID]
+	return car, ok && !car.DeletedAt.Valid
+}
+
+// withEngine returns the car with its engine attached, as a preload does:
+// left zero when the engine is soft deleted. The caller holds a lock.
+func (db *DB) withEngine(car models.Car) models.Car {
+	car.Engine = models.Engine{}
+	if engine, ok := db.engines[car.EngineID]; ok && !engine.DeletedAt.Valid {
+		car.Engine = engine
+	}
+	return car
+}
+
+// hasBrand reports whether the car's brand is called, or aliased, key. The
+// caller holds a lock.
+func (db *DB) hasBrand(car models.Car, key string) bool {
+	brand, ok := db.brands[car.BrandID]
+	return ok && (brand.NormalizedName == key || brandByName(brand, key))
+}
+
+// resolveBrand returns the brand a car request's brand name or alias refers
+// to. The caller holds a lock.
+func (db *DB) resolveBrand(name string) (*models.Brand, error) {
+	brand, ok := db.resolveBrands([]string{name})[models.NormalizeBrandName(name)]
+	if !ok {
+		return nil, unknownBrand(name)
+	}
+	return brand, nil
+}
+
+// matches reports whether a car passes the filter, like the conditions
+// built by the GORM repository. The caller holds a lock.
+func (db *DB) matches(car models.Car, filter *models.CarFilter) bool {
+	if car.DeletedAt.Valid && !filter.IncludeDeleted {
+		return false
+	}
+	if filter.Brand != "" && !db.hasBrand(car, models.NormalizeBrandName(filter.Brand)) {
+		return false
+	}
+	if filter.Currency != "" && car.Price.Currency != filter.Currency {
+		return false
+	}
+	if filter.FuelType != "" && car.FuelType != filter.FuelType {
+		return false
+	}
+	if filter.EngineID != "" && car.EngineID.String() != filter.EngineID {
+		return false
+	}
+	if filter.YearFrom != 0 && car.Year < strconv.Itoa(filter.YearFrom) {
+		return false
+	}
+	if filter.YearTo != 0 && car.Year > strconv.Itoa(filter.YearTo) {
+		return false
+	}
+	if filter.PriceMin != nil && car.Price.Amount.LessThan(decimal.NewFromFloat(*filter.PriceMin)) {
+		return false
+	}
+	if filter.PriceMax != nil && car.Price.Amount.GreaterThan(decimal.NewFromFloat(*filter.PriceMax)) {
+		return false
+	}
+	return true
+}
+
+// compareCars orders two cars by a sort column, then by ID.
+func compareCars(a, b *models.Car, column string) int {
+	if c := compareColumn(a, column, b); c != 0 {
+		return c
+	}
+	return strings.Compare(a.ID.String(), b.ID.String())
+}
+
+// compareCarToCursor orders a car against the position a cursor records.
+func compareCarToCursor(car *models.Car, column string, cursor *models.PageCursor) int {
+	value, _ := carCursorValue(column, cursor.Value)
+	var c int
+	switch v := value.(type) {
+	case decimal.Decimal:
+		c = car.Price.Amount.Cmp(v)
+	case time.Time:
+		c = columnTime(car, column).Compare(v)
+	default:
+		if column != "id" {
+			c = strings.Compare(carSortValue(car, column), v.(string))
+		}
+	}
+	if c != 0 {
+		return c
+	}
+	return strings.Compare(car.ID.String(), cursor.ID)
+}
+
+func compareColumn(a *models.Car, column string, b *models.Car) int {
+	switch column {
+	case "id":
+		return 0
+	case "price":
+		return a.Price.Amount.Cmp(b.Price.Amount)
+	case "created_at", "updated_at":
+		return columnTime(a, column).Compare(columnTime(b, column))
+	default:
+		return strings.Compare(carSortValue(a, column), carSortValue(b, column))
+	}
+}
+
+func columnTime(car *models.Car, column string) time.Time {
+	if column == "updated_at" {
+		return car.UpdatedAt
+	}
+	return car.CreatedAt
+}
+
+// carSortValue returns the value of the sort column of a car, formatted for a cursor.
+func carSortValue(car *models.Car, column string) string {
+	switch column {
+	case "name":
+		return car.Name
+	case "year":
+		return car.Year
+	case "brand":
+		return car.Brand
+	case "fuel_type":
+		return car.FuelType
+	case "engine_id":
+		return car.EngineID.String()
+	case "price":
+		return car.Price.Amount.String()
+	case "created_at":
+		return car.CreatedAt.Format(time.RFC3339Nano)
+	case "updated_at":
+		return car.UpdatedAt.Format(time.RFC3339Nano)
+	default:
+		return car.ID.String()
+	}
+}
+
+// carCursorValue parses a cursor value back into the type of its sort column.
+func carCursorValue(column, value string) (interface{}, error) {
+	switch column {
+	case "price":
+		price, err := decimal.NewFromString(value)
+		if err != nil {
+			return nil, apperrors.Validation("cursor", "cursor is malformed")
+		}
+		return price, nil
+	case "created_at", "updated_at":
+		t, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return nil, apperrors.Validation("cursor", "cursor is malformed")
+		}
+		return t, nil
+	default:
+		return value, nil
+	}
+}
+
+// page returns at most limit items after the first offset ones; a limit of
+// zero means no limit.
+func page[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return []T{}
+	}
+	items = items[offset:]
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return append([]T{}, items...)
+}
@@ -0,0 +1,260 @@
+package memoryRepository
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/Tushar456/go-carzone/apperrors"
+	"github.com/Tushar456/go-carzone/models"
+	"github.com/Tushar456/go-carzone/repository"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"gorm.io/gorm"
+)
+
+type EngineRepository struct {
+	db     *DB
+	logger *slog.Logger
+}
+
+func NewEngineRepository(db *DB, logger *slog.Logger) *EngineRepository {
+	return &EngineRepository{db: db, logger: logger}
+}
+
+func (s *EngineRepository) GetEngineById(ctx context.Context, id string) (*models.Engine, error) {
+	_, span := otel.Tracer("engineservice").Start(ctx, "GetEngineById")
+	defer span.End()
+
+	var engine models.Engine
+	err := s.db.read(func() error {
+		found, ok := s.db.liveEngine(id)
+		if !ok {
+			return apperrors.NotFound("engine not found")
+		}
+		engine = found
+		return nil
+	})
+	if err != nil {
+		return &models.Engine{}, err
+	}
+	return &engine, nil
+}
+
+func (s *EngineRepository) CreateEngine(ctx context.Context, engineRequest *models.EngineRequest) (*models.Engine, error) {
+	ctx, span := otel.Tracer("engineservice").Start(ctx, "CreateEngine")
+	defer span.End()
+
+	engine := models.Engine{
+		ID:            uuid.New(),
+		Displacement:  engineRequest.Displacement,
+		NoOfCylinders: engineRequest.NoOfCylinders,
+		CarRange:      engineRequest.CarRange,
+		Version:       1,
+	}
+	s.db.write(ctx, func() error {
+		s.db.engines[engine.ID] = engine
+		return nil
+	})
+	return &engine, nil
+}
+
+// UpdateEngine replaces the fields of an engine. A non-zero version must
+// match the stored one.
+func (s *EngineRepository) UpdateEngine(ctx context.Context, id string, engineRequest *models.EngineRequest, version int64) (*models.Engine, error) {
+	ctx, span := otel.Tracer("engineservice").Start(ctx, "UpdateEngine")
+	defer span.End()
+
+	var engine models.Engine
+	err := s.db.write(ctx, func() error {
+		found, ok := s.db.liveEngine(id)
+		if !ok {
+			return apperrors.NotFound("engine not found")
+		}
+		if version != 0 && found.Version != version {
+			return repository.ErrEngineModified
+		}
+
+		engine = found
+		engine.Displacement = engineRequest.Displacement
+		engine.NoOfCylinders = engineRequest.NoOfCylinders
+		engine.CarRange = engineRequest.CarRange
+		engine.Version++
+		s.db.engines[engine.ID] = engine
+		return s.db.addEvent(ctx, models.EventEngineUpdated, models.AggregateEngine, engine.ID, &engine)
+	})
+	if err != nil {
+		return &models.Engine{}, err
+	}
+	return &engine, nil
+}
+
+// DeleteEngine soft deletes an engine. A non-zero version must match the
+// stored one.
+func (s *EngineRepository) DeleteEngine(ctx context.Context, id string, version int64) (*models.Engine, error) {
+	ctx, span := otel.Tracer("engineservice").Start(ctx, "DeleteEngine")
+	defer span.End()
+
+	var engine models.Engine
+	err := s.db.write(ctx, func() error {
+		found, ok := s.db.liveEngine(id)
+		if !ok {
+			return apperrors.NotFound("engine not found")
+		}
+		if version != 0 && found.Version != version {
+			return repository.ErrEngineModified
+		}
+
+		engine = found
+		found.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+		s.db.engines[found.ID] = found
+		return nil
+	})
+	if err != nil {
+		return &models.Engine{}, err
+	}
+	return &engine, nil
+}
+
+func (s *EngineRepository) RestoreEngine(ctx context.Context, id string) (*models.Engine, error) {
+	ctx, span := otel.Tracer("engineservice").Start(ctx, "RestoreEngine")
+	defer span.End()
+
+	var engine models.Engine
+	err := s.db.write(ctx, func() error {
+		engineID, err := uuid.Parse(id)
+		found, ok := s.db.engines[engineID]
+		if err != nil || !ok || !found.DeletedAt.Valid {
+			return apperrors.NotFound("deleted engine not found")
+		}
+
+		found.DeletedAt = gorm.DeletedAt{}
+		s.db.engines[found.ID] = found
+		engine = found
+		return nil
+	})
+	if err != nil {
+		return &models.Engine{}, err
+	}
+	return &engine, nil
+}
+
+// PurgeDeletedEngines permanently removes the engines soft deleted before
+// the given time and returns them as they were.
+func (s *EngineRepository) PurgeDeletedEngines(ctx context.Context, before time.Time) ([]models.Engine, error) {
+	ctx, span := otel.Tracer("engineservice").Start(ctx, "PurgeDeletedEngines")
+	defer span.End()
+
+	var purged []models.Engine
+	s.db.write(ctx, func() error {
+		referenced := map[uuid.UUID]bool{}
+		for _, car := range s.db.cars {
+			referenced[car.EngineID] = true
+		}
+		// Engines still referenced by a car, even a soft deleted one, are
+		// kept until the car itself has been purged.
+		for id, engine := range s.db.engines {
+			if engine.DeletedAt.Valid && engine.DeletedAt.Time.Before(before) && !referenced[id] {
+				purged = append(purged, engine)
+				delete(s.db.engines, id)
+			}
+		}
+		return nil
+	})
+	sort.Slice(purged, func(i, j int) bool { return purged[i].ID.String() < purged[j].ID.String() })
+	s.logger.DebugContext(ctx, "Purged deleted engines", "before", before, "count", len(purged))
+	return purged, nil
+}
+
+// CountEngines returns the number of engines, soft deleted ones excluded.
+func (s *EngineRepository) CountEngines(ctx context.Context) (int64, error) {
+	_, span := otel.Tracer("engineservice").Start(ctx, "CountEngines")
+	defer span.End()
+
+	var count int64
+	s.db.read(func() error {
+		for _, engine := range s.db.engines {
+			if !engine.DeletedAt.Valid {
+				count++
+			}
+		}
+		return nil
+	})
+	return count, nil
+}
+
+// GetReferencingCarIDs returns the IDs of the cars that use the engine.
+func (s *EngineRepository) GetReferencingCarIDs(ctx context.Context, id string) ([]uuid.UUID, error) {
+	_, span := otel.Tracer("engineservice").Start(ctx, "GetReferencingCarIDs")
+	defer span.End()
+
+	ids := []uuid.UUID{}
+	s.db.read(func() error {
+		for _, car := range s.db.cars {
+			if !car.DeletedAt.Valid && car.EngineID.String() == id {
+				ids = append(ids, car.ID)
+			}
+		}
+		return nil
+	})
+	return ids, nil
+}
+
+// ReassignCars moves every car of one engine, soft deleted ones included, to
+// another engine and returns the IDs of the cars moved, in order.
+func (s *EngineRepository) ReassignCars(ctx context.Context, fromID string, toID string) ([]uuid.UUID, error) {
+	ctx, span := otel.Tracer("engineservice").Start(ctx, "ReassignCars")
+	defer span.End()
+
+	carIDs := []uuid.UUID{}
+	err := s.db.write(ctx, func() error {
+		for id, car := range s.db.cars {
+			if car.EngineID.String() == fromID {
+				carIDs = append(carIDs, id)
+			}
+		}
+		if len(carIDs) == 0 {
+			return nil
+		}
+		sort.Slice(carIDs, func(i, j int) bool { return carIDs[i].String() < carIDs[j].String() })
+
+		to, err := uuid.Parse(toID)
+		if err != nil {
+			return apperrors.Validation("engine_id", "engine id must be a valid UUID")
+		}
+		if _, ok := s.db.engines[to]; !ok {
+			return apperrors.ForeignKey("", "record references a missing record or is still referenced")
+		}
+		now := time.Now()
+		for _, id := range carIDs {
+			car := s.db.cars[id]
+			car.EngineID = to
+			car.Version++
+			car.UpdatedAt = now
+			s.db.cars[id] = car
+
+			car = s.db.withEngine(car)
+			if err := s.db.addCarEvent(ctx, models.EventCarUpdated, &car); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.logger.DebugContext(ctx, "Reassigned cars", "from", fromID, "to", toID, "count", len(carIDs))
+	return carIDs, nil
+}
+
+// liveEngine returns the engine with the given ID unless it is soft
+// deleted. The caller holds a lock.
+func (db *DB) liveEngine(id string) (models.Engine, bool) {
+	engineID, err := uuid.Parse(id)
+	if err != nil {
+		return models.Engine{}, false
+	}
+	engine, ok := db.engines[engineID]
+	return engine, ok && !engine.DeletedAt.Valid
+}
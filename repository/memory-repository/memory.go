@@ -0,0 +1,190 @@
+// Package memoryRepository implements the car, engine and brand
+// repositories on maps held in memory, for unit tests and for running the
+// server without a database. It follows the GORM repositories closely,
+// errors included, which the suite in the contract package checks;
+// full-text search is only approximated, and domain events are only written
+// when the DB is given an EventRecorder.
+package memoryRepository
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/Tushar456/go-carzone/models"
+	"github.com/google/uuid"
+)
+
+// DB holds the records shared by the repositories created from it. It is
+// safe for concurrent use.
+type DB struct {
+	// txMu serialises transactions, and the writes made outside one, so
+	// that a rollback cannot discard another caller's changes.
+	txMu sync.Mutex
+	// mu guards the records.
+	mu sync.RWMutex
+
+	cars         map[uuid.UUID]models.Car
+	engines      map[uuid.UUID]models.Engine
+	brands       map[uuid.UUID]models.Brand
+	priceHistory []models.CarPriceHistory
+
+	events EventRecorder
+}
+
+// EventRecorder writes the domain events of the changes made in memory, as
+// the outbox repository does. Events are written in the same context as the
+// change, so with a transactor spanning both stores an event is only kept
+// if the change is.
+type EventRecorder interface {
+	AddEvent(ctx context.Context, eventType, aggregateType string, aggregateID uuid.UUID, payload interface{}) error
+}
+
+func NewDB() *DB {
+	return &DB{
+		cars:    map[uuid.UUID]models.Car{},
+		engines: map[uuid.UUID]models.Engine{},
+		brands:  map[uuid.UUID]models.Brand{},
+	}
+}
+
+// RecordEvents sends the domain events of later changes to events. It must
+// be called before the repositories are used.
+func (db *DB) RecordEvents(events EventRecorder) {
+	db.events = events
+}
+
+// addEvent writes a domain event if the DB records them. The caller holds
+// the write lock.
+func (db *DB) addEvent(ctx context.Context, eventType, aggregateType string, aggregateID uuid.UUID, payload interface{}) error {
+	if db.events == nil {
+		return nil
+	}
+	return db.events.AddEvent(ctx, eventType, aggregateType, aggregateID, payload)
+}
+
+func (db *DB) addCarEvent(ctx context.Context, eventType string, car *models.Car) error {
+	return db.addEvent(ctx, eventType, models.AggregateCar, car.ID, car)
+}
+
+// AddBrand validates and adds a brand to the catalog cars are checked
+// against, for setting up tests.
+func (db *DB) AddBrand(brandRequest *models.BrandRequest) (*models.Brand, error) {
+	if err := brandRequest.Validate(); err != nil {
+		return nil, err
+	}
+	return NewBrandRepository(db, slog.New(slog.DiscardHandler)).CreateBrand(context.Background(), brandRequest)
+}
+
+// read runs fn holding the read lock.
+func (db *DB) read(fn func() error) error {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return fn()
+}
+
+// write runs fn holding the write lock. Outside a transaction it also waits
+// for any running transaction to end.
+func (db *DB) write(ctx context.Context, fn func() error) error {
+	if !inTransaction(ctx) {
+		db.txMu.Lock()
+		defer db.txMu.Unlock()
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return fn()
+}
+
+// resolveBrands mirrors repository.ResolveBrands. The caller holds a lock.
+func (db *DB) resolveBrands(names []string) map[string]*models.Brand {
+	resolved := map[string]*models.Brand{}
+	for _, name := range names {
+		key := models.NormalizeBrandName(name)
+		if key == "" {
+			continue
+		}
+		for _, brand := range db.brands {
+			if brand.NormalizedName == key || brandByName(brand, key) {
+				brand := brand
+				resolved[brand.NormalizedName] = &brand
+				for _, alias := range brand.Aliases {
+					resolved[alias.NormalizedName] = &brand
+				}
+			}
+		}
+	}
+	return resolved
+}
+
+// brandByName reports whether one of a brand's aliases normalises to key.
+func brandByName(brand models.Brand, key string) bool {
+	for _, alias := range brand.Aliases {
+		if alias.NormalizedName == key {
+			return true
+		}
+	}
+	return false
+}
+
+// snapshot copies the records so that a transaction can be rolled back.
+// The caller holds a lock.
+func (db *DB) snapshot() *DB {
+	snap := &DB{
+		cars:         make(map[uuid.UUID]models.Car, len(db.cars)),
+		engines:      make(map[uuid.UUID]models.Engine, len(db.engines)),
+		brands:       make(map[uuid.UUID]models.Brand, len(db.brands)),
+		priceHistory: append([]models.CarPriceHistory(nil), db.priceHistory...),
+	}
+	for id, car := range db.cars {
+		snap.cars[id] = car
+	}
+	for id, engine := range db.engines {
+		snap.engines[id] = engine
+	}
+	for id, brand := range db.brands {
+		snap.brands[id] = brand
+	}
+	return snap
+}
+
+type txKey struct{}
+
+func inTransaction(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{}).(bool)
+	return ok
+}
+
+// Transactor runs functions in transactions on a DB. Transactions run one
+// at a time and are rolled back by restoring the records as they were when
+// the transaction began. Reads are not isolated: they see the changes of a
+// running transaction.
+type Transactor struct {
+	db *DB
+}
+
+func NewTransactor(db *DB) *Transactor {
+	return &Transactor{db: db}
+}
+
+// Transaction runs fn in a transaction that is kept when fn returns nil and
+// rolled back otherwise. Nested calls join the outer transaction.
+func (t *Transactor) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if inTransaction(ctx) {
+		return fn(ctx)
+	}
+
+	t.db.txMu.Lock()
+	defer t.db.txMu.Unlock()
+
+	t.db.mu.RLock()
+	snap := t.db.snapshot()
+	t.db.mu.RUnlock()
+
+	if err := fn(context.WithValue(ctx, txKey{}, true)); err != nil {
+		t.db.mu.Lock()
+		t.db.cars, t.db.engines, t.db.brands, t.db.priceHistory = snap.cars, snap.engines, snap.brands, snap.priceHistory
+		t.db.mu.Unlock()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package memoryRepository_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/Tushar456/go-carzone/models"
+	"github.com/Tushar456/go-carzone/repository/contract"
+	memoryRepository "github.com/Tushar456/go-carzone/repository/memory-repository"
+)
+
+func newFixture(t *testing.T) contract.RepositoryFixture {
+	db, logger := memoryRepository.NewDB(), contract.Logger(t)
+	return contract.RepositoryFixture{
+		Cars:       memoryRepository.NewCarRepository(db, logger),
+		Engines:    memoryRepository.NewEngineRepository(db, logger),
+		Transactor: memoryRepository.NewTransactor(db),
+		AddBrand: func(t *testing.T, brand *models.BrandRequest) {
+			if _, err := db.AddBrand(brand); err != nil {
+				t.Fatalf("AddBrand: %v", err)
+			}
+		},
+	}
+}
+
+func TestCarRepository(t *testing.T) {
+	contract.TestCarRepository(t, newFixture)
+}
+
+func TestEngineRepository(t *testing.T) {
+	contract.TestEngineRepository(t, newFixture)
+}
+
+func TestTransactor(t *testing.T) {
+	contract.TestTransactor(t, newFixture)
+}
+
+func TestConcurrentUse(t *testing.T) {
+	f := newFixture(t)
+	f.AddBrand(t, &models.BrandRequest{Name: "Toyota"})
+	ctx := context.Background()
+	engine, err := f.Engines.CreateEngine(ctx, &models.EngineRequest{Displacement: 1998, NoOfCylinders: 4})
+	if err != nil {
+		t.Fatalf("CreateEngine: %v", err)
+	}
+
+	const workers, carsEach = 8, 25
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < carsEach; i++ {
+				request := &models.CarRequest{Name: "Corolla", Year: "2020", Brand: "Toyota", FuelType: "Petrol", EngineID: engine.ID.String()}
+				err := f.Transactor.Transaction(ctx, func(ctx context.Context) error {
+					car, err := f.Cars.CreateCar(ctx, request)
+					if err != nil {
+						return err
+					}
+					_, err = f.Cars.UpdateCar(ctx, car.ID.String(), request, car.Version)
+					return err
+				})
+				if err != nil {
+					t.Errorf("creating and updating a car: %v", err)
+				}
+				if _, err := f.Cars.ListCars(ctx, &models.CarFilter{SortBy: "created_at", Limit: 10}); err != nil {
+					t.Errorf("ListCars: %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	cars, err := f.Cars.GetCarByBrand(ctx, "Toyota", false)
+	if err != nil {
+		t.Fatalf("GetCarByBrand: %v", err)
+	}
+	if len(cars) != workers*carsEach {
+		t.Errorf("got %d cars, want %d", len(cars), workers*carsEach)
+	}
+	for _, car := range cars {
+		if car.Version != 2 {
+			t.Errorf("car %s version = %d, want 2", car.ID, car.Version)
+		}
+	}
+}
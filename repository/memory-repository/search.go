@@ -0,0 +1,59 @@
+package memoryRepository
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/Tushar456/go-carzone/models"
+	"github.com/Tushar456/go-carzone/repository"
+	"go.opentelemetry.io/otel"
+)
+
+// SearchCars approximates the full-text and fuzzy search of the GORM
+// repository with repository.SearchRank.
+func (s *CarRepository) SearchCars(ctx context.Context, query *models.CarSearchQuery) (*models.Page[models.CarSearchResult], error) {
+	_, span := otel.Tracer("carservice").Start(ctx, "SearchCars")
+	defer span.End()
+
+	terms := strings.Fields(strings.ToLower(query.Q))
+
+	var results []models.CarSearchResult
+	s.db.read(func() error {
+		for _, car := range s.db.cars {
+			if car.DeletedAt.Valid {
+				continue
+			}
+			rank := repository.SearchRank(terms, car.Name) + repository.SearchRank(terms, car.Brand)
+			if rank == 0 {
+				continue
+			}
+			if query.IsEngine {
+				car = s.db.withEngine(car)
+			}
+			results = append(results, models.CarSearchResult{
+				Car:  car,
+				Rank: rank,
+				Highlight: map[string]string{
+					"name":  repository.SearchHighlight(terms, car.Name),
+					"brand": repository.SearchHighlight(terms, car.Brand),
+				},
+			})
+		}
+		return nil
+	})
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Rank != results[j].Rank {
+			return results[i].Rank > results[j].Rank
+		}
+		return results[i].Car.ID.String() < results[j].Car.ID.String()
+	})
+
+	return &models.Page[models.CarSearchResult]{
+		Items:  page(results, query.Limit, query.Offset),
+		Total:  int64(len(results)),
+		Limit:  query.Limit,
+		Offset: query.Offset,
+	}, nil
+}
@@ -0,0 +1,28 @@
+package metricsRepository
+
+import (
+	"context"
+
+	"github.com/Tushar456/go-carzone/metrics"
+	"github.com/Tushar456/go-carzone/models"
+	"github.com/Tushar456/go-carzone/repository"
+)
+
+type AuditRepository struct {
+	observer
+	next repository.AuditRepositoryInterface
+}
+
+func NewAuditRepository(next repository.AuditRepositoryInterface, m *metrics.Metrics) *AuditRepository {
+	return &AuditRepository{observer: observer{metrics: m, repository: "audit"}, next: next}
+}
+
+func (s *AuditRepository) CreateAuditEntry(ctx context.Context, entry *models.AuditEntry) error {
+	defer s.observe("CreateAuditEntry")()
+	return s.next.CreateAuditEntry(ctx, entry)
+}
+
+func (s *AuditRepository) ListAuditEntries(ctx context.Context, filter *models.AuditFilter) (*models.Page[models.AuditEntry], error) {
+	defer s.observe("ListAuditEntries")()
+	return s.next.ListAuditEntries(ctx, filter)
+}
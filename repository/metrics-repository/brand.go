@@ -0,0 +1,53 @@
+package metricsRepository
+
+import (
+	"context"
+
+	"github.com/Tushar456/go-carzone/metrics"
+	"github.com/Tushar456/go-carzone/models"
+	"github.com/Tushar456/go-carzone/repository"
+)
+
+type BrandRepository struct {
+	observer
+	next repository.BrandRepositoryInterface
+}
+
+func NewBrandRepository(next repository.BrandRepositoryInterface, m *metrics.Metrics) *BrandRepository {
+	return &BrandRepository{observer: observer{metrics: m, repository: "brands"}, next: next}
+}
+
+func (s *BrandRepository) GetBrandById(ctx context.Context, id string) (*models.Brand, error) {
+	defer s.observe("GetBrandById")()
+	return s.next.GetBrandById(ctx, id)
+}
+
+func (s *BrandRepository) ListBrands(ctx context.Context, limit, offset int) (*models.Page[models.Brand], error) {
+	defer s.observe("ListBrands")()
+	return s.next.ListBrands(ctx, limit, offset)
+}
+
+func (s *BrandRepository) ResolveBrand(ctx context.Context, name string) (*models.Brand, error) {
+	defer s.observe("ResolveBrand")()
+	return s.next.ResolveBrand(ctx, name)
+}
+
+func (s *BrandRepository) ResolveBrands(ctx context.Context, names []string) (map[string]*models.Brand, error) {
+	defer s.observe("ResolveBrands")()
+	return s.next.ResolveBrands(ctx, names)
+}
+
+func (s *BrandRepository) CreateBrand(ctx context.Context, brand *models.BrandRequest) (*models.Brand, error) {
+	defer s.observe("CreateBrand")()
+	return s.next.CreateBrand(ctx, brand)
+}
+
+func (s *BrandRepository) UpdateBrand(ctx context.Context, id string, updateBrand *models.BrandRequest) (*models.Brand, []models.RenamedCar, error) {
+	defer s.observe("UpdateBrand")()
+	return s.next.UpdateBrand(ctx, id, updateBrand)
+}
+
+func (s *BrandRepository) DeleteBrand(ctx context.Context, id string) (*models.Brand, error) {
+	defer s.observe("DeleteBrand")()
+	return s.next.DeleteBrand(ctx, id)
+}
@@ -0,0 +1,90 @@
+package metricsRepository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Tushar456/go-carzone/metrics"
+	"github.com/Tushar456/go-carzone/models"
+	"github.com/Tushar456/go-carzone/repository"
+	"github.com/google/uuid"
+)
+
+type CarRepository struct {
+	observer
+	next repository.CarRepositoryInterface
+}
+
+func NewCarRepository(next repository.CarRepositoryInterface, m *metrics.Metrics) *CarRepository {
+	return &CarRepository{observer: observer{metrics: m, repository: "cars"}, next: next}
+}
+
+func (s *CarRepository) GetCarById(ctx context.Context, id string) (*models.Car, error) {
+	defer s.observe("GetCarById")()
+	return s.next.GetCarById(ctx, id)
+}
+
+func (s *CarRepository) GetCarByBrand(ctx context.Context, brand string, isEngine bool) ([]models.Car, error) {
+	defer s.observe("GetCarByBrand")()
+	return s.next.GetCarByBrand(ctx, brand, isEngine)
+}
+
+func (s *CarRepository) ListCars(ctx context.Context, filter *models.CarFilter) (*models.Page[models.Car], error) {
+	defer s.observe("ListCars")()
+	return s.next.ListCars(ctx, filter)
+}
+
+func (s *CarRepository) SearchCars(ctx context.Context, query *models.CarSearchQuery) (*models.Page[models.CarSearchResult], error) {
+	defer s.observe("SearchCars")()
+	return s.next.SearchCars(ctx, query)
+}
+
+func (s *CarRepository) CreateCar(ctx context.Context, car *models.CarRequest) (*models.Car, error) {
+	defer s.observe("CreateCar")()
+	return s.next.CreateCar(ctx, car)
+}
+
+func (s *CarRepository) CreateCars(ctx context.Context, cars []*models.CarRequest, batchSize int) ([]models.Car, error) {
+	defer s.observe("CreateCars")()
+	return s.next.CreateCars(ctx, cars, batchSize)
+}
+
+func (s *CarRepository) ExistingEngineIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]bool, error) {
+	defer s.observe("ExistingEngineIDs")()
+	return s.next.ExistingEngineIDs(ctx, ids)
+}
+
+func (s *CarRepository) ResolveBrands(ctx context.Context, names []string) (map[string]*models.Brand, error) {
+	defer s.observe("ResolveBrands")()
+	return s.next.ResolveBrands(ctx, names)
+}
+
+func (s *CarRepository) UpdateCar(ctx context.Context, id string, updateCar *models.CarRequest, version int64) (*models.Car, error) {
+	defer s.observe("UpdateCar")()
+	return s.next.UpdateCar(ctx, id, updateCar, version)
+}
+
+func (s *CarRepository) GetPriceHistory(ctx context.Context, id string) ([]models.CarPriceHistory, error) {
+	defer s.observe("GetPriceHistory")()
+	return s.next.GetPriceHistory(ctx, id)
+}
+
+func (s *CarRepository) DeleteCar(ctx context.Context, id string, version int64) (*models.Car, error) {
+	defer s.observe("DeleteCar")()
+	return s.next.DeleteCar(ctx, id, version)
+}
+
+func (s *CarRepository) RestoreCar(ctx context.Context, id string) (*models.Car, error) {
+	defer s.observe("RestoreCar")()
+	return s.next.RestoreCar(ctx, id)
+}
+
+func (s *CarRepository) PurgeDeletedCars(ctx context.Context, before time.Time) ([]models.Car, error) {
+	defer s.observe("PurgeDeletedCars")()
+	return s.next.PurgeDeletedCars(ctx, before)
+}
+
+func (s *CarRepository) CountCarsByBrand(ctx context.Context) (map[string]int64, error) {
+	defer s.observe("CountCarsByBrand")()
+	return s.next.CountCarsByBrand(ctx)
+}
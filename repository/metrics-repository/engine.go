@@ -0,0 +1,65 @@
+package metricsRepository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Tushar456/go-carzone/metrics"
+	"github.com/Tushar456/go-carzone/models"
+	"github.com/Tushar456/go-carzone/repository"
+	"github.com/google/uuid"
+)
+
+type EngineRepository struct {
+	observer
+	next repository.EngineRepositoryInterface
+}
+
+func NewEngineRepository(next repository.EngineRepositoryInterface, m *metrics.Metrics) *EngineRepository {
+	return &EngineRepository{observer: observer{metrics: m, repository: "engines"}, next: next}
+}
+
+func (s *EngineRepository) GetEngineById(ctx context.Context, id string) (*models.Engine, error) {
+	defer s.observe("GetEngineById")()
+	return s.next.GetEngineById(ctx, id)
+}
+
+func (s *EngineRepository) CreateEngine(ctx context.Context, engine *models.EngineRequest) (*models.Engine, error) {
+	defer s.observe("CreateEngine")()
+	return s.next.CreateEngine(ctx, engine)
+}
+
+func (s *EngineRepository) UpdateEngine(ctx context.Context, id string, updateEngine *models.EngineRequest, version int64) (*models.Engine, error) {
+	defer s.observe("UpdateEngine")()
+	return s.next.UpdateEngine(ctx, id, updateEngine, version)
+}
+
+func (s *EngineRepository) DeleteEngine(ctx context.Context, id string, version int64) (*models.Engine, error) {
+	defer s.observe("DeleteEngine")()
+	return s.next.DeleteEngine(ctx, id, version)
+}
+
+func (s *EngineRepository) RestoreEngine(ctx context.Context, id string) (*models.Engine, error) {
+	defer s.observe("RestoreEngine")()
+	return s.next.RestoreEngine(ctx, id)
+}
+
+func (s *EngineRepository) PurgeDeletedEngines(ctx context.Context, before time.Time) ([]models.Engine, error) {
+	defer s.observe("PurgeDeletedEngines")()
+	return s.next.PurgeDeletedEngines(ctx, before)
+}
+
+func (s *EngineRepository) GetReferencingCarIDs(ctx context.Context, id string) ([]uuid.UUID, error) {
+	defer s.observe("GetReferencingCarIDs")()
+	return s.next.GetReferencingCarIDs(ctx, id)
+}
+
+func (s *EngineRepository) ReassignCars(ctx context.Context, fromID string, toID string) ([]uuid.UUID, error) {
+	defer s.observe("ReassignCars")()
+	return s.next.ReassignCars(ctx, fromID, toID)
+}
+
+func (s *EngineRepository) CountEngines(ctx context.Context) (int64, error) {
+	defer s.observe("CountEngines")()
+	return s.next.CountEngines(ctx)
+}
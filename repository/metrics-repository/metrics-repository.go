@@ -0,0 +1,24 @@
+// Package metricsRepository wraps the repositories to record how long each
+// of their methods takes, whatever implementation is underneath, in the
+// carzone_repository_duration_seconds histogram.
+package metricsRepository
+
+import (
+	"time"
+
+	"github.com/Tushar456/go-carzone/metrics"
+)
+
+// observer records the calls of one repository.
+type observer struct {
+	metrics    *metrics.Metrics
+	repository string
+}
+
+// observe starts timing a call to method; the returned function records it.
+func (o observer) observe(method string) func() {
+	start := time.Now()
+	return func() {
+		o.metrics.ObserveRepository(o.repository, method, time.Since(start))
+	}
+}
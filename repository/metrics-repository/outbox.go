@@ -0,0 +1,44 @@
+package metricsRepository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Tushar456/go-carzone/metrics"
+	"github.com/Tushar456/go-carzone/models"
+	"github.com/Tushar456/go-carzone/repository"
+)
+
+type OutboxRepository struct {
+	observer
+	next repository.OutboxRepositoryInterface
+}
+
+func NewOutboxRepository(next repository.OutboxRepositoryInterface, m *metrics.Metrics) *OutboxRepository {
+	return &OutboxRepository{observer: observer{metrics: m, repository: "outbox"}, next: next}
+}
+
+func (s *OutboxRepository) ClaimEvents(ctx context.Context, limit int, now time.Time, lease time.Duration) ([]models.OutboxEvent, error) {
+	defer s.observe("ClaimEvents")()
+	return s.next.ClaimEvents(ctx, limit, now, lease)
+}
+
+func (s *OutboxRepository) ReleaseEvents(ctx context.Context, events []models.OutboxEvent, at time.Time) error {
+	defer s.observe("ReleaseEvents")()
+	return s.next.ReleaseEvents(ctx, events, at)
+}
+
+func (s *OutboxRepository) MarkPublished(ctx context.Context, event *models.OutboxEvent, at time.Time) error {
+	defer s.observe("MarkPublished")()
+	return s.next.MarkPublished(ctx, event, at)
+}
+
+func (s *OutboxRepository) MarkFailed(ctx context.Context, event *models.OutboxEvent, nextAttemptAt time.Time, cause error) error {
+	defer s.observe("MarkFailed")()
+	return s.next.MarkFailed(ctx, event, nextAttemptAt, cause)
+}
+
+func (s *OutboxRepository) PurgePublished(ctx context.Context, before time.Time) (int64, error) {
+	defer s.observe("PurgePublished")()
+	return s.next.PurgePublished(ctx, before)
+}
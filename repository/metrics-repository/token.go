@@ -0,0 +1,60 @@
+package metricsRepository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Tushar456/go-carzone/metrics"
+	"github.com/Tushar456/go-carzone/models"
+	"github.com/Tushar456/go-carzone/repository"
+	"github.com/google/uuid"
+)
+
+type TokenRepository struct {
+	observer
+	next repository.TokenRepositoryInterface
+}
+
+func NewTokenRepository(next repository.TokenRepositoryInterface, m *metrics.Metrics) *TokenRepository {
+	return &TokenRepository{observer: observer{metrics: m, repository: "tokens"}, next: next}
+}
+
+func (s *TokenRepository) CreateRefreshToken(ctx context.Context, token *models.RefreshToken) error {
+	defer s.observe("CreateRefreshToken")()
+	return s.next.CreateRefreshToken(ctx, token)
+}
+
+func (s *TokenRepository) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	defer s.observe("GetRefreshTokenByHash")()
+	return s.next.GetRefreshTokenByHash(ctx, tokenHash)
+}
+
+func (s *TokenRepository) RevokeRefreshToken(ctx context.Context, id uuid.UUID, replacedBy *uuid.UUID) (bool, error) {
+	defer s.observe("RevokeRefreshToken")()
+	return s.next.RevokeRefreshToken(ctx, id, replacedBy)
+}
+
+func (s *TokenRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID) error {
+	defer s.observe("RevokeRefreshTokenFamily")()
+	return s.next.RevokeRefreshTokenFamily(ctx, familyID)
+}
+
+func (s *TokenRepository) RevokeUserRefreshTokens(ctx context.Context, userID uuid.UUID) error {
+	defer s.observe("RevokeUserRefreshTokens")()
+	return s.next.RevokeUserRefreshTokens(ctx, userID)
+}
+
+func (s *TokenRepository) RevokeAccessToken(ctx context.Context, token *models.RevokedToken) error {
+	defer s.observe("RevokeAccessToken")()
+	return s.next.RevokeAccessToken(ctx, token)
+}
+
+func (s *TokenRepository) IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	defer s.observe("IsAccessTokenRevoked")()
+	return s.next.IsAccessTokenRevoked(ctx, jti)
+}
+
+func (s *TokenRepository) PurgeExpiredTokens(ctx context.Context, before time.Time) (int64, error) {
+	defer s.observe("PurgeExpiredTokens")()
+	return s.next.PurgeExpiredTokens(ctx, before)
+}
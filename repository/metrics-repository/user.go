@@ -0,0 +1,44 @@
+package metricsRepository
+
+import (
+	"context"
+
+	"github.com/Tushar456/go-carzone/metrics"
+	"github.com/Tushar456/go-carzone/models"
+	"github.com/Tushar456/go-carzone/repository"
+	"github.com/google/uuid"
+)
+
+type UserRepository struct {
+	observer
+	next repository.UserRepositoryInterface
+}
+
+func NewUserRepository(next repository.UserRepositoryInterface, m *metrics.Metrics) *UserRepository {
+	return &UserRepository{observer: observer{metrics: m, repository: "users"}, next: next}
+}
+
+func (s *UserRepository) GetUserById(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	defer s.observe("GetUserById")()
+	return s.next.GetUserById(ctx, id)
+}
+
+func (s *UserRepository) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	defer s.observe("GetUserByUsername")()
+	return s.next.GetUserByUsername(ctx, username)
+}
+
+func (s *UserRepository) CreateUser(ctx context.Context, user *models.User) (*models.User, error) {
+	defer s.observe("CreateUser")()
+	return s.next.CreateUser(ctx, user)
+}
+
+func (s *UserRepository) UpdatePassword(ctx context.Context, username string, passwordHash string) error {
+	defer s.observe("UpdatePassword")()
+	return s.next.UpdatePassword(ctx, username, passwordHash)
+}
+
+func (s *UserRepository) UpdateRole(ctx context.Context, username string, role string) (*models.User, error) {
+	defer s.observe("UpdateRole")()
+	return s.next.UpdateRole(ctx, username, role)
+}
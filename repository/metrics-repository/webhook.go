@@ -0,0 +1,74 @@
+package metricsRepository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Tushar456/go-carzone/metrics"
+	"github.com/Tushar456/go-carzone/models"
+	"github.com/Tushar456/go-carzone/repository"
+)
+
+type WebhookRepository struct {
+	observer
+	next repository.WebhookRepositoryInterface
+}
+
+func NewWebhookRepository(next repository.WebhookRepositoryInterface, m *metrics.Metrics) *WebhookRepository {
+	return &WebhookRepository{observer: observer{metrics: m, repository: "webhooks"}, next: next}
+}
+
+func (s *WebhookRepository) GetSubscriptionById(ctx context.Context, id string) (*models.WebhookSubscription, error) {
+	defer s.observe("GetSubscriptionById")()
+	return s.next.GetSubscriptionById(ctx, id)
+}
+
+func (s *WebhookRepository) ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	defer s.observe("ListSubscriptions")()
+	return s.next.ListSubscriptions(ctx)
+}
+
+func (s *WebhookRepository) ListActiveSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	defer s.observe("ListActiveSubscriptions")()
+	return s.next.ListActiveSubscriptions(ctx)
+}
+
+func (s *WebhookRepository) CreateSubscription(ctx context.Context, subscription *models.WebhookSubscription) (*models.WebhookSubscription, error) {
+	defer s.observe("CreateSubscription")()
+	return s.next.CreateSubscription(ctx, subscription)
+}
+
+func (s *WebhookRepository) UpdateSubscription(ctx context.Context, subscription *models.WebhookSubscription) (*models.WebhookSubscription, error) {
+	defer s.observe("UpdateSubscription")()
+	return s.next.UpdateSubscription(ctx, subscription)
+}
+
+func (s *WebhookRepository) DeleteSubscription(ctx context.Context, id string) (*models.WebhookSubscription, error) {
+	defer s.observe("DeleteSubscription")()
+	return s.next.DeleteSubscription(ctx, id)
+}
+
+func (s *WebhookRepository) CreateDeliveries(ctx context.Context, deliveries []models.WebhookDelivery) error {
+	defer s.observe("CreateDeliveries")()
+	return s.next.CreateDeliveries(ctx, deliveries)
+}
+
+func (s *WebhookRepository) ClaimDeliveries(ctx context.Context, limit int, now time.Time, lease time.Duration) ([]models.WebhookDelivery, error) {
+	defer s.observe("ClaimDeliveries")()
+	return s.next.ClaimDeliveries(ctx, limit, now, lease)
+}
+
+func (s *WebhookRepository) SaveDeliveryAttempt(ctx context.Context, delivery *models.WebhookDelivery) error {
+	defer s.observe("SaveDeliveryAttempt")()
+	return s.next.SaveDeliveryAttempt(ctx, delivery)
+}
+
+func (s *WebhookRepository) ListDeliveries(ctx context.Context, subscriptionID string, filter *models.WebhookDeliveryFilter) (*models.Page[models.WebhookDelivery], error) {
+	defer s.observe("ListDeliveries")()
+	return s.next.ListDeliveries(ctx, subscriptionID, filter)
+}
+
+func (s *WebhookRepository) Redeliver(ctx context.Context, subscriptionID string, deliveryID string) (*models.WebhookDelivery, error) {
+	defer s.observe("Redeliver")()
+	return s.next.Redeliver(ctx, subscriptionID, deliveryID)
+}
@@ -0,0 +1,119 @@
+package outboxRepository
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Tushar456/go-carzone/models"
+	"github.com/Tushar456/go-carzone/repository"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OutboxRepository struct {
+	repo   *repository.Repository[models.OutboxEvent]
+	logger *slog.Logger
+}
+
+func NewOutboxRepository(db *gorm.DB, logger *slog.Logger) *OutboxRepository {
+	return &OutboxRepository{
+		repo:   repository.New[models.OutboxEvent](db),
+		logger: logger,
+	}
+}
+
+// ClaimEvents leases up to limit unpublished events that are due, oldest
+// first, by moving their next attempt to now plus lease, so that no relay
+// claims them again before the lease runs out. It should run in a short
+// transaction: events locked by another relay are skipped.
+func (s *OutboxRepository) ClaimEvents(ctx context.Context, limit int, now time.Time, lease time.Duration) ([]models.OutboxEvent, error) {
+	ctx, span := otel.Tracer("outbox").Start(ctx, "ClaimEvents")
+	defer span.End()
+
+	var events []models.OutboxEvent
+	err := s.repo.FindPage(ctx, &events, nil, "created_at ASC, id ASC", limit, 0, func(db *gorm.DB) *gorm.DB {
+		return db.Where("published_at IS NULL AND next_attempt_at <= ?", now).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return events, nil
+	}
+
+	leasedUntil := now.Add(lease)
+	ids := make([]uuid.UUID, len(events))
+	for i := range events {
+		ids[i] = events[i].ID
+		events[i].NextAttemptAt = leasedUntil
+	}
+	if _, err := s.repo.UpdateColumns(ctx, map[string]interface{}{"next_attempt_at": leasedUntil}, "id IN ?", ids); err != nil {
+		return nil, err
+	}
+	s.logger.DebugContext(ctx, "Claimed outbox events", "count", len(events), "leased_until", leasedUntil)
+	return events, nil
+}
+
+// ReleaseEvents ends the lease on claimed events that were not attempted,
+// making them due at the given time.
+func (s *OutboxRepository) ReleaseEvents(ctx context.Context, events []models.OutboxEvent, at time.Time) error {
+	ctx, span := otel.Tracer("outbox").Start(ctx, "ReleaseEvents")
+	defer span.End()
+
+	if len(events) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, len(events))
+	for i := range events {
+		ids[i] = events[i].ID
+	}
+	_, err := s.repo.UpdateColumns(ctx, map[string]interface{}{"next_attempt_at": at}, "id IN ? AND published_at IS NULL", ids)
+	return err
+}
+
+func (s *OutboxRepository) MarkPublished(ctx context.Context, event *models.OutboxEvent, at time.Time) error {
+	ctx, span := otel.Tracer("outbox").Start(ctx, "MarkPublished")
+	defer span.End()
+
+	_, err := s.repo.UpdateColumns(ctx, map[string]interface{}{
+		"published_at": at,
+		"attempts":     event.Attempts + 1,
+		"last_error":   "",
+	}, "id = ?", event.ID)
+	return err
+}
+
+// MarkFailed records a failed attempt to publish an event and when to try
+// again.
+func (s *OutboxRepository) MarkFailed(ctx context.Context, event *models.OutboxEvent, nextAttemptAt time.Time, cause error) error {
+	ctx, span := otel.Tracer("outbox").Start(ctx, "MarkFailed")
+	defer span.End()
+
+	_, err := s.repo.UpdateColumns(ctx, map[string]interface{}{
+		"attempts":        event.Attempts + 1,
+		"next_attempt_at": nextAttemptAt,
+		"last_error":      cause.Error(),
+	}, "id = ?", event.ID)
+	return err
+}
+
+// PurgePublished deletes events published before the given time.
+func (s *OutboxRepository) PurgePublished(ctx context.Context, before time.Time) (int64, error) {
+	ctx, span := otel.Tracer("outbox").Start(ctx, "PurgePublished")
+	defer span.End()
+
+	return s.repo.DeleteWhere(ctx, "published_at IS NOT NULL AND published_at < ?", before)
+}
+
+// AddEvent writes a domain event to the outbox, for stores that keep the
+// aggregates elsewhere, such as the in-memory repositories.
+func (s *OutboxRepository) AddEvent(ctx context.Context, eventType, aggregateType string, aggregateID uuid.UUID, payload interface{}) error {
+	ctx, span := otel.Tracer("outbox").Start(ctx, "AddEvent")
+	defer span.End()
+
+	return repository.AddEvent(ctx, s.repo, eventType, aggregateType, aggregateID, payload)
+}
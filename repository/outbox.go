@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/Tushar456/go-carzone/models"
+	"github.com/google/uuid"
+)
+
+// AddEvent writes a domain event about an aggregate to the outbox. Called
+// with the context of the transaction making the change, the event is only
+// kept, and so only published, if the change is.
+func AddEvent(ctx context.Context, repo *Repository[models.OutboxEvent], eventType, aggregateType string, aggregateID uuid.UUID, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return repo.Create(ctx, &models.OutboxEvent{
+		ID:            uuid.New(),
+		Type:          eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Payload:       data,
+		NextAttemptAt: time.Now(),
+	})
+}
@@ -2,13 +2,16 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Repository is a generic repository providing basic CRUD operations.
 type Repository[T any] struct {
-	db *gorm.DB
+	db       *gorm.DB
+	unscoped bool
 }
 
 // New creates a new generic repository.
@@ -16,14 +19,27 @@ func New[T any](db *gorm.DB) *Repository[T] {
 	return &Repository[T]{db: db}
 }
 
+// conn returns the database handle for ctx: the transaction started by a
+// Transactor when there is one, the repository's own handle otherwise.
+func (r *Repository[T]) conn(ctx context.Context) *gorm.DB {
+	db := r.db
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		db = tx
+		if r.unscoped {
+			db = db.Unscoped()
+		}
+	}
+	return db.WithContext(ctx)
+}
+
 // Get finds a single record matching the given condition.
 func (r *Repository[T]) Get(ctx context.Context, dest *T, conds ...interface{}) error {
-	return r.db.WithContext(ctx).First(dest, conds...).Error
+	return r.conn(ctx).First(dest, conds...).Error
 }
 
 // GetWithPreload finds a single record with preloaded associations.
 func (r *Repository[T]) GetWithPreload(ctx context.Context, dest *T, preloads []string, conds ...interface{}) error {
-	query := r.db.WithContext(ctx)
+	query := r.conn(ctx)
 	for _, p := range preloads {
 		query = query.Preload(p)
 	}
@@ -32,29 +48,119 @@ func (r *Repository[T]) GetWithPreload(ctx context.Context, dest *T, preloads []
 
 // Create inserts a new record into the database.
 func (r *Repository[T]) Create(ctx context.Context, entity *T) error {
-	return r.db.WithContext(ctx).Create(entity).Error
+	return translateError(r.conn(ctx).Create(entity).Error)
+}
+
+// CreateInBatches inserts records using one statement per batchSize records.
+func (r *Repository[T]) CreateInBatches(ctx context.Context, entities []T, batchSize int) error {
+	return translateError(r.conn(ctx).CreateInBatches(&entities, batchSize).Error)
+}
+
+// CreateWith inserts a new record, applying extra clauses such as ON CONFLICT.
+func (r *Repository[T]) CreateWith(ctx context.Context, entity *T, clauses ...clause.Expression) error {
+	return translateError(r.conn(ctx).Clauses(clauses...).Create(entity).Error)
 }
 
 // Update saves an existing record in the database.
 func (r *Repository[T]) Update(ctx context.Context, entity *T) error {
-	return r.db.WithContext(ctx).Save(entity).Error
+	return translateError(r.conn(ctx).Save(entity).Error)
 }
 
 // Delete removes a record from the database.
 func (r *Repository[T]) Delete(ctx context.Context, entity *T) error {
-	return r.db.WithContext(ctx).Delete(entity).Error
+	return translateError(r.conn(ctx).Delete(entity).Error)
 }
 
 // Find finds records matching the given condition.
 func (r *Repository[T]) Find(ctx context.Context, dest *[]T, conds ...interface{}) error {
-	return r.db.WithContext(ctx).Find(dest, conds...).Error
+	return r.conn(ctx).Find(dest, conds...).Error
 }
 
 // FindWithPreload finds records with preloaded associations.
 func (r *Repository[T]) FindWithPreload(ctx context.Context, dest *[]T, preloads []string, conds ...interface{}) error {
-	query := r.db.WithContext(ctx)
+	query := r.conn(ctx)
 	for _, p := range preloads {
 		query = query.Preload(p)
 	}
 	return query.Find(dest, conds...).Error
 }
+
+// Raw runs a hand-written query and scans its rows into dest, for queries
+// that cannot be expressed with the methods above.
+func (r *Repository[T]) Raw(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return r.conn(ctx).Raw(query, args...).Scan(dest).Error
+}
+
+// Dialect names the database the repository runs on, such as "postgres" or
+// "sqlite", for the few queries that differ between them.
+func (r *Repository[T]) Dialect() string {
+	return r.db.Dialector.Name()
+}
+
+// Scope narrows a query. It has the same shape as a gorm scope so it can be
+// passed straight to gorm.DB.Scopes.
+type Scope = func(*gorm.DB) *gorm.DB
+
+// Count returns the number of records matching the given scopes.
+func (r *Repository[T]) Count(ctx context.Context, scopes ...Scope) (int64, error) {
+	var count int64
+	err := r.conn(ctx).Model(new(T)).Scopes(scopes...).Count(&count).Error
+	return count, err
+}
+
+// FindPage finds at most limit records matching the given scopes, skipping the
+// first offset records of the given order, with preloaded associations.
+func (r *Repository[T]) FindPage(ctx context.Context, dest *[]T, preloads []string, order string, limit, offset int, scopes ...Scope) error {
+	query := r.conn(ctx).Scopes(scopes...)
+	for _, p := range preloads {
+		query = query.Preload(p)
+	}
+	if order != "" {
+		query = query.Order(order)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+	return query.Find(dest).Error
+}
+
+// UpdateColumns updates the given columns on every record matching the
+// condition and reports how many records were changed.
+func (r *Repository[T]) UpdateColumns(ctx context.Context, columns map[string]interface{}, query interface{}, args ...interface{}) (int64, error) {
+	result := r.conn(ctx).Model(new(T)).Where(query, args...).Updates(columns)
+	return result.RowsAffected, translateError(result.Error)
+}
+
+// DeleteWhere removes every record matching the condition and reports how
+// many records were removed.
+func (r *Repository[T]) DeleteWhere(ctx context.Context, query interface{}, args ...interface{}) (int64, error) {
+	result := r.conn(ctx).Where(query, args...).Delete(new(T))
+	return result.RowsAffected, translateError(result.Error)
+}
+
+// Unscoped returns a repository whose queries also see soft deleted records
+// and whose deletes remove records permanently.
+func (r *Repository[T]) Unscoped() *Repository[T] {
+	return &Repository[T]{db: r.db.Unscoped(), unscoped: true}
+}
+
+// Restore clears the soft delete mark of the deleted records matching the
+// condition and reports how many were restored.
+func (r *Repository[T]) Restore(ctx context.Context, query interface{}, args ...interface{}) (int64, error) {
+	result := r.conn(ctx).Unscoped().Model(new(T)).
+		Where(query, args...).Where("deleted_at IS NOT NULL").
+		Update("deleted_at", nil)
+	return result.RowsAffected, translateError(result.Error)
+}
+
+// Purge permanently removes records soft deleted before the given time that
+// also match the given scopes.
+func (r *Repository[T]) Purge(ctx context.Context, before time.Time, scopes ...Scope) (int64, error) {
+	result := r.conn(ctx).Unscoped().Scopes(scopes...).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Delete(new(T))
+	return result.RowsAffected, translateError(result.Error)
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"html"
+	"strings"
+)
+
+// minFuzzyLength is the shortest search term matched inside longer words, so
+// that single letters do not match everything.
+const minFuzzyLength = 3
+
+// SearchRank approximates the full-text and fuzzy ranking of the Postgres
+// car search, for stores without it. terms are the lower case words of the
+// query. A term equal to a word of text counts as a full-text match, and one
+// contained in such a word, or containing it, as a fuzzy match worth half as
+// much.
+func SearchRank(terms []string, text string) float64 {
+	rank := 0.0
+	for _, word := range strings.Fields(strings.ToLower(text)) {
+		for _, term := range terms {
+			switch {
+			case word == term:
+				rank += 2
+			case len(term) >= minFuzzyLength && (strings.Contains(word, term) || strings.Contains(term, word)):
+				rank++
+			}
+		}
+	}
+	return rank
+}
+
+// SearchHighlight HTML-escapes text and wraps the words that fully match a
+// term in <mark> tags, like the Postgres search does.
+func SearchHighlight(terms []string, text string) string {
+	words := strings.Fields(text)
+	for i, word := range words {
+		words[i] = html.EscapeString(word)
+		for _, term := range terms {
+			if strings.ToLower(word) == term {
+				words[i] = "<mark>" + words[i] + "</mark>"
+				break
+			}
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// HTMLEscapeSQL returns an SQL expression escaping the text of column like
+// html.EscapeString does, so that the only markup in a ts_headline of it is
+// the markup ts_headline adds.
+func HTMLEscapeSQL(column string) string {
+	expr := column
+	for _, r := range [][2]string{{"&", "&amp;"}, {"<", "&lt;"}, {">", "&gt;"}, {`"`, "&#34;"}, {"'", "&#39;"}} {
+		expr = "replace(" + expr + ", '" + strings.ReplaceAll(r[0], "'", "''") + "', '" + r[1] + "')"
+	}
+	return expr
+}
@@ -0,0 +1,135 @@
+package sqlRepository_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/Tushar456/go-carzone/models"
+	"github.com/Tushar456/go-carzone/repository"
+	brandRepository "github.com/Tushar456/go-carzone/repository/brand-repository"
+	"github.com/Tushar456/go-carzone/repository/contract"
+	sqlRepository "github.com/Tushar456/go-carzone/repository/sql-repository"
+	"github.com/shopspring/decimal"
+)
+
+// The benchmarks compare the GORM queries with the hand-written ones on an
+// in-memory SQLite database of benchBrands brands with carsPerBrand cars
+// each. Run them with
+//
+//	go test ./repository/sql-repository -run '^$' -bench . -benchmem
+const (
+	benchBrands  = 10
+	carsPerBrand = 100
+)
+
+type benchData struct {
+	cars    map[string]repository.CarRepositoryInterface
+	engines map[string]repository.EngineRepositoryInterface
+	carIDs  []string
+	engine  string
+}
+
+func seed(b *testing.B) *benchData {
+	ctx := context.Background()
+	db, logger := contract.OpenSQLite(b), contract.Logger(b)
+	sqlCars, sqlEngines := sqlRepository.NewCarRepository(db, logger), sqlRepository.NewEngineRepository(db, logger)
+
+	engine, err := sqlEngines.CreateEngine(ctx, &models.EngineRequest{Displacement: 2000, NoOfCylinders: 4, CarRange: 600})
+	if err != nil {
+		b.Fatalf("CreateEngine: %v", err)
+	}
+
+	brands := brandRepository.NewBrandRepository(db, logger)
+	var requests []*models.CarRequest
+	for i := 0; i < benchBrands; i++ {
+		brand := fmt.Sprintf("Brand %d", i)
+		if _, err := brands.CreateBrand(ctx, &models.BrandRequest{Name: brand}); err != nil {
+			b.Fatalf("CreateBrand: %v", err)
+		}
+		for j := 0; j < carsPerBrand; j++ {
+			requests = append(requests, &models.CarRequest{
+				Name: fmt.Sprintf("Model %d", j), Year: "2020", Brand: brand, FuelType: "Petrol",
+				EngineID: engine.ID.String(),
+				Price:    models.NewMoney(decimal.NewFromInt(int64(10000+j)), "USD"),
+			})
+		}
+	}
+	created, err := sqlCars.CreateCars(ctx, requests, 100)
+	if err != nil {
+		b.Fatalf("CreateCars: %v", err)
+	}
+
+	data := &benchData{
+		cars: map[string]repository.CarRepositoryInterface{
+			"gorm": sqlCars.CarRepository,
+			"sql":  sqlCars,
+		},
+		engines: map[string]repository.EngineRepositoryInterface{
+			"gorm": sqlEngines.EngineRepository,
+			"sql":  sqlEngines,
+		},
+		engine: engine.ID.String(),
+	}
+	for _, car := range created {
+		data.carIDs = append(data.carIDs, car.ID.String())
+	}
+	return data
+}
+
+// implementations lists the benchmarked implementations in a fixed order.
+var implementations = []string{"gorm", "sql"}
+
+func BenchmarkGetCarById(b *testing.B) {
+	data := seed(b)
+	ctx := context.Background()
+	for _, name := range implementations {
+		cars := data.cars[name]
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := cars.GetCarById(ctx, data.carIDs[i%len(data.carIDs)]); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkGetCarByBrand(b *testing.B) {
+	data := seed(b)
+	ctx := context.Background()
+	for _, isEngine := range []bool{false, true} {
+		for _, name := range implementations {
+			cars := data.cars[name]
+			b.Run(fmt.Sprintf("%s/isEngine=%t", name, isEngine), func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					found, err := cars.GetCarByBrand(ctx, fmt.Sprintf("brand %d", i%benchBrands), isEngine)
+					if err != nil {
+						b.Fatal(err)
+					}
+					if len(found) != carsPerBrand {
+						b.Fatalf("found %d cars, want %d", len(found), carsPerBrand)
+					}
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkGetEngineById(b *testing.B) {
+	data := seed(b)
+	ctx := context.Background()
+	for _, name := range implementations {
+		engines := data.engines[name]
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := engines.GetEngineById(ctx, data.engine); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+// Package sqlRepository serves the hottest reads of the car and engine
+// repositories with hand-written SQL, where GORM's reflection and separate
+// preload queries cost the most, and leaves every other method, and so all
+// the writes with their events, to the GORM repositories it wraps. The
+// queries use only SQL that Postgres and SQLite share.
+package sqlRepository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+
+	"github.com/Tushar456/go-carzone/apperrors"
+	"github.com/Tushar456/go-carzone/models"
+	"github.com/Tushar456/go-carzone/repository"
+	carRepository "github.com/Tushar456/go-carzone/repository/car-repository"
+	engineRepository "github.com/Tushar456/go-carzone/repository/engine-repository"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"gorm.io/gorm"
+)
+
+const carColumns = `c.id, c.name, c.year, c.brand, c.brand_id, c.fuel_type, c.engine_id,
+       c.price, c.currency, c.version, c.created_at, c.updated_at`
+
+// engineJoin loads the engine of a car in the same query. Like GORM's
+// preload, it leaves the engine empty when the engine is soft deleted.
+const engineJoin = `, e.engine_id, e.displacement, e.no_of_cylinders, e.car_range, e.version
+FROM cars c
+LEFT JOIN engines e ON e.engine_id = c.engine_id AND e.deleted_at IS NULL`
+
+const carByIDQuery = `SELECT ` + carColumns + engineJoin + `
+WHERE c.id = $1 AND c.deleted_at IS NULL`
+
+// carByBrandCondition mirrors repository.BrandIDByNameQuery.
+const carByBrandCondition = `
+WHERE c.deleted_at IS NULL
+  AND c.brand_id IN (SELECT id FROM brands WHERE normalized_name = $1
+                     UNION SELECT brand_id FROM brand_aliases WHERE normalized_name = $1)`
+
+const carByBrandQuery = `SELECT ` + carColumns + `
+FROM cars c` + carByBrandCondition
+
+const carByBrandWithEngineQuery = `SELECT ` + carColumns + engineJoin + carByBrandCondition
+
+const engineByIDQuery = `SELECT engine_id, displacement, no_of_cylinders, car_range, version
+FROM engines
+WHERE engine_id = $1 AND deleted_at IS NULL`
+
+type CarRepository struct {
+	*carRepository.CarRepository
+	db *gorm.DB
+}
+
+func NewCarRepository(db *gorm.DB, logger *slog.Logger) *CarRepository {
+	return &CarRepository{
+		CarRepository: carRepository.NewCarRepository(db, logger),
+		db:            db,
+	}
+}
+
+func (s *CarRepository) GetCarById(ctx context.Context, id string) (*models.Car, error) {
+	ctx, span := otel.Tracer("carservice").Start(ctx, "GetCarById")
+	defer span.End()
+
+	row := repository.SQLConn(ctx, s.db).QueryRowContext(ctx, carByIDQuery, id)
+	car, err := scanCar(row, true)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperrors.NotFound("car not found")
+		}
+		return nil, err
+	}
+	return &car, nil
+}
+
+func (s *CarRepository) GetCarByBrand(ctx context.Context, brand string, isEngine bool) ([]models.Car, error) {
+	ctx, span := otel.Tracer("carservice").Start(ctx, "GetCarByBrand")
+	defer span.End()
+
+	query := carByBrandQuery
+	if isEngine {
+		query = carByBrandWithEngineQuery
+	}
+
+	rows, err := repository.SQLConn(ctx, s.db).QueryContext(ctx, query, models.NormalizeBrandName(brand))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cars := []models.Car{}
+	for rows.Next() {
+		car, err := scanCar(rows, isEngine)
+		if err != nil {
+			return nil, err
+		}
+		cars = append(cars, car)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return cars, nil
+}
+
+type EngineRepository struct {
+	*engineRepository.EngineRepository
+	db *gorm.DB
+}
+
+func NewEngineRepository(db *gorm.DB, logger *slog.Logger) *EngineRepository {
+	return &EngineRepository{
+		EngineRepository: engineRepository.NewEngineRepository(db, logger),
+		db:               db,
+	}
+}
+
+func (s *EngineRepository) GetEngineById(ctx context.Context, id string) (*models.Engine, error) {
+	ctx, span := otel.Tracer("engineservice").Start(ctx, "GetEngineById")
+	defer span.End()
+
+	var engine models.Engine
+	err := repository.SQLConn(ctx, s.db).QueryRowContext(ctx, engineByIDQuery, id).Scan(
+		&engine.ID,
+		&engine.Displacement,
+		&engine.NoOfCylinders,
+		&engine.CarRange,
+		&engine.Version,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return &models.Engine{}, apperrors.NotFound("engine not found")
+		}
+		return &models.Engine{}, err
+	}
+	return &engine, nil
+}
+
+// scanner is the Scan method shared by sql.Row and sql.Rows.
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanCar reads carColumns, followed by the engine columns of engineJoin
+// when withEngine is set.
+func scanCar(row scanner, withEngine bool) (models.Car, error) {
+	var car models.Car
+	dest := []interface{}{
+		&car.ID,
+		&car.Name,
+		&car.Year,
+		&car.Brand,
+		&car.BrandID,
+		&car.FuelType,
+		&car.EngineID,
+		&car.Price.Amount,
+		&car.Price.Currency,
+		&car.Version,
+		&car.CreatedAt,
+		&car.UpdatedAt,
+	}
+	if !withEngine {
+		return car, row.Scan(dest...)
+	}
+
+	var engineID uuid.NullUUID
+	var displacement, cylinders, carRange, version sql.NullInt64
+	if err := row.Scan(append(dest, &engineID, &displacement, &cylinders, &carRange, &version)...); err != nil {
+		return car, err
+	}
+	if engineID.Valid {
+		car.Engine = models.Engine{
+			ID:            engineID.UUID,
+			Displacement:  int(displacement.Int64),
+			NoOfCylinders: int(cylinders.Int64),
+			CarRange:      int(carRange.Int64),
+			Version:       version.Int64,
+		}
+	}
+	return car, nil
+}
@@ -0,0 +1,95 @@
+package sqlRepository_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/Tushar456/go-carzone/models"
+	brandRepository "github.com/Tushar456/go-carzone/repository/brand-repository"
+	"github.com/Tushar456/go-carzone/repository/contract"
+	sqlRepository "github.com/Tushar456/go-carzone/repository/sql-repository"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+var newFixture = contract.NewGORMFixture(sqlRepository.NewCarRepository, sqlRepository.NewEngineRepository)
+
+func TestCarRepository(t *testing.T) {
+	contract.TestCarRepository(t, newFixture)
+}
+
+func TestEngineRepository(t *testing.T) {
+	contract.TestEngineRepository(t, newFixture)
+}
+
+func TestTransactor(t *testing.T) {
+	contract.TestTransactor(t, newFixture)
+}
+
+// TestMatchesGORM checks that the hand-written queries return exactly what
+// the GORM repositories they replace return.
+func TestMatchesGORM(t *testing.T) {
+	ctx := context.Background()
+	db, logger := contract.OpenSQLite(t), contract.Logger(t)
+	if _, err := brandRepository.NewBrandRepository(db, logger).CreateBrand(ctx, &models.BrandRequest{Name: "Toyota", Aliases: []string{"Toyo"}}); err != nil {
+		t.Fatalf("CreateBrand: %v", err)
+	}
+	cars, engines := sqlRepository.NewCarRepository(db, logger), sqlRepository.NewEngineRepository(db, logger)
+
+	engine, err := engines.CreateEngine(ctx, &models.EngineRequest{Displacement: 1800, NoOfCylinders: 4, CarRange: 600})
+	if err != nil {
+		t.Fatalf("CreateEngine: %v", err)
+	}
+	deletedEngine, err := engines.CreateEngine(ctx, &models.EngineRequest{Displacement: 2500, NoOfCylinders: 6, CarRange: 500})
+	if err != nil {
+		t.Fatalf("CreateEngine: %v", err)
+	}
+	var ids []string
+	for _, engineID := range []string{engine.ID.String(), deletedEngine.ID.String()} {
+		car, err := cars.CreateCar(ctx, &models.CarRequest{
+			Name: "Corolla", Year: "2020", Brand: "Toyota", FuelType: "Petrol", EngineID: engineID,
+			Price: models.NewMoney(decimal.RequireFromString("19999.5"), "USD"),
+		})
+		if err != nil {
+			t.Fatalf("CreateCar: %v", err)
+		}
+		ids = append(ids, car.ID.String())
+	}
+	if _, err := engines.DeleteEngine(ctx, deletedEngine.ID.String(), 0); err != nil {
+		t.Fatalf("DeleteEngine: %v", err)
+	}
+
+	gormCars, gormEngines := cars.CarRepository, engines.EngineRepository
+	for _, id := range append(ids, uuid.NewString()) {
+		want, wantErr := gormCars.GetCarById(ctx, id)
+		got, gotErr := cars.GetCarById(ctx, id)
+		expectSame(t, "GetCarById", got, gotErr, want, wantErr)
+	}
+	for _, brand := range []string{"toyo", "Honda"} {
+		for _, isEngine := range []bool{false, true} {
+			want, wantErr := gormCars.GetCarByBrand(ctx, brand, isEngine)
+			got, gotErr := cars.GetCarByBrand(ctx, brand, isEngine)
+			sort.Slice(want, func(i, j int) bool { return want[i].ID.String() < want[j].ID.String() })
+			sort.Slice(got, func(i, j int) bool { return got[i].ID.String() < got[j].ID.String() })
+			expectSame(t, "GetCarByBrand", got, gotErr, want, wantErr)
+		}
+	}
+	for _, id := range []string{engine.ID.String(), deletedEngine.ID.String()} {
+		want, wantErr := gormEngines.GetEngineById(ctx, id)
+		got, gotErr := engines.GetEngineById(ctx, id)
+		expectSame(t, "GetEngineById", got, gotErr, want, wantErr)
+	}
+}
+
+func expectSame(t *testing.T, method string, got interface{}, gotErr error, want interface{}, wantErr error) {
+	t.Helper()
+	if fmt.Sprint(gotErr) != fmt.Sprint(wantErr) {
+		t.Errorf("%s: error %v, want %v", method, gotErr, wantErr)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("%s:\n got %+v\nwant %+v", method, got, want)
+	}
+}
@@ -0,0 +1,144 @@
+package tokenRepository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/Tushar456/go-carzone/models"
+	"github.com/Tushar456/go-carzone/repository"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TokenRepository struct {
+	refreshRepo *repository.Repository[models.RefreshToken]
+	revokedRepo *repository.Repository[models.RevokedToken]
+
+	logger *slog.Logger
+}
+
+func NewTokenRepository(db *gorm.DB, logger *slog.Logger) *TokenRepository {
+	return &TokenRepository{
+		refreshRepo: repository.New[models.RefreshToken](db),
+		revokedRepo: repository.New[models.RevokedToken](db),
+
+		logger: logger,
+	}
+}
+
+func (s *TokenRepository) CreateRefreshToken(ctx context.Context, token *models.RefreshToken) error {
+	ctx, span := otel.Tracer("tokenservice").Start(ctx, "CreateRefreshToken")
+	defer span.End()
+
+	return s.refreshRepo.Create(ctx, token)
+}
+
+func (s *TokenRepository) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	ctx, span := otel.Tracer("tokenservice").Start(ctx, "GetRefreshTokenByHash")
+	defer span.End()
+
+	var token models.RefreshToken
+	if err := s.refreshRepo.Get(ctx, &token, "token_hash = ?", tokenHash); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrTokenNotFound
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// RevokeRefreshToken marks a single refresh token as used. It reports false
+// when the token had already been revoked, which means another request won
+// the race to rotate it.
+func (s *TokenRepository) RevokeRefreshToken(ctx context.Context, id uuid.UUID, replacedBy *uuid.UUID) (bool, error) {
+	ctx, span := otel.Tracer("tokenservice").Start(ctx, "RevokeRefreshToken")
+	defer span.End()
+
+	rows, err := s.refreshRepo.UpdateColumns(ctx, map[string]interface{}{
+		"revoked_at":  time.Now(),
+		"replaced_by": replacedBy,
+	}, "id = ? AND revoked_at IS NULL", id)
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
+}
+
+func (s *TokenRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID) error {
+	ctx, span := otel.Tracer("tokenservice").Start(ctx, "RevokeRefreshTokenFamily")
+	defer span.End()
+
+	revoked, err := s.refreshRepo.UpdateColumns(ctx, map[string]interface{}{
+		"revoked_at": time.Now(),
+	}, "family_id = ? AND revoked_at IS NULL", familyID)
+	if err != nil {
+		return err
+	}
+	s.logger.DebugContext(ctx, "Revoked refresh token family", "family_id", familyID, "count", revoked)
+	return nil
+}
+
+// RevokeUserRefreshTokens revokes every refresh token family of a user, so
+// sessions opened before a password change cannot be refreshed.
+func (s *TokenRepository) RevokeUserRefreshTokens(ctx context.Context, userID uuid.UUID) error {
+	ctx, span := otel.Tracer("tokenservice").Start(ctx, "RevokeUserRefreshTokens")
+	defer span.End()
+
+	revoked, err := s.refreshRepo.UpdateColumns(ctx, map[string]interface{}{
+		"revoked_at": time.Now(),
+	}, "user_id = ? AND revoked_at IS NULL", userID)
+	if err != nil {
+		return err
+	}
+	s.logger.DebugContext(ctx, "Revoked refresh tokens of user", "user_id", userID, "count", revoked)
+	return nil
+}
+
+func (s *TokenRepository) RevokeAccessToken(ctx context.Context, token *models.RevokedToken) error {
+	ctx, span := otel.Tracer("tokenservice").Start(ctx, "RevokeAccessToken")
+	defer span.End()
+
+	// Entries past their expiry are useless, so drop them while we are here.
+	if _, err := s.revokedRepo.DeleteWhere(ctx, "expires_at < ?", time.Now()); err != nil {
+		return err
+	}
+
+	return s.revokedRepo.CreateWith(ctx, token, clause.OnConflict{DoNothing: true})
+}
+
+func (s *TokenRepository) IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	ctx, span := otel.Tracer("tokenservice").Start(ctx, "IsAccessTokenRevoked")
+	defer span.End()
+
+	count, err := s.revokedRepo.Count(ctx, func(db *gorm.DB) *gorm.DB {
+		return db.Where("jti = ?", jti)
+	})
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// PurgeExpiredTokens deletes the refresh tokens and the revoked access tokens
+// that expired before the given time, and reports how many rows went.
+// Revoked refresh tokens are kept until they expire, so that reusing one is
+// still caught.
+func (s *TokenRepository) PurgeExpiredTokens(ctx context.Context, before time.Time) (int64, error) {
+	ctx, span := otel.Tracer("tokenservice").Start(ctx, "PurgeExpiredTokens")
+	defer span.End()
+
+	refresh, err := s.refreshRepo.DeleteWhere(ctx, "expires_at < ?", before)
+	if err != nil {
+		return 0, err
+	}
+	revoked, err := s.revokedRepo.DeleteWhere(ctx, "expires_at < ?", before)
+	if err != nil {
+		return 0, err
+	}
+	s.logger.DebugContext(ctx, "Purged expired tokens", "before", before, "refresh_tokens", refresh, "revoked_tokens", revoked)
+	return refresh + revoked, nil
+}
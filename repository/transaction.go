@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type txKey struct{}
+
+// Transactor runs functions inside a database transaction. The transaction
+// travels in the context, so every repository called with that context takes
+// part in it without having to be handed a *gorm.DB.
+type Transactor struct {
+	db *gorm.DB
+}
+
+// NewTransactor creates a transactor for the given database.
+func NewTransactor(db *gorm.DB) *Transactor {
+	return &Transactor{db: db}
+}
+
+// Transaction runs fn in a transaction that is committed when fn returns nil
+// and rolled back otherwise. Nested calls join the outer transaction.
+func (t *Transactor) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+	return t.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// Transactors runs functions in a transaction of each of several stores,
+// the first outermost. A failure rolls back every transaction that has not
+// committed yet, so the store whose commit can fail should come last.
+type Transactors []TransactorInterface
+
+func (ts Transactors) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if len(ts) == 0 {
+		return fn(ctx)
+	}
+	return ts[0].Transaction(ctx, func(ctx context.Context) error {
+		return ts[1:].Transaction(ctx, fn)
+	})
+}
+
+// SQLConn returns the connection hand-written SQL should run on for ctx: the
+// transaction started by a Transactor when there is one, db's own pool
+// otherwise.
+func SQLConn(ctx context.Context, db *gorm.DB) gorm.ConnPool {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.Statement.ConnPool
+	}
+	return db.Statement.ConnPool
+}
@@ -0,0 +1,95 @@
+package userRepository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Tushar456/go-carzone/models"
+	"github.com/Tushar456/go-carzone/repository"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"gorm.io/gorm"
+)
+
+type UserRepository struct {
+	repo *repository.Repository[models.User]
+}
+
+func NewUserRepository(db *gorm.DB) *UserRepository {
+	return &UserRepository{
+		repo: repository.New[models.User](db),
+	}
+}
+
+func (s *UserRepository) GetUserById(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	ctx, span := otel.Tracer("userservice").Start(ctx, "GetUserById")
+	defer span.End()
+
+	var user models.User
+	if err := s.repo.Get(ctx, &user, "id = ?", id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (s *UserRepository) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	ctx, span := otel.Tracer("userservice").Start(ctx, "GetUserByUsername")
+	defer span.End()
+
+	var user models.User
+	if err := s.repo.Get(ctx, &user, "username = ?", username); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (s *UserRepository) CreateUser(ctx context.Context, user *models.User) (*models.User, error) {
+	ctx, span := otel.Tracer("userservice").Start(ctx, "CreateUser")
+	defer span.End()
+
+	if err := s.repo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *UserRepository) UpdatePassword(ctx context.Context, username string, passwordHash string) error {
+	ctx, span := otel.Tracer("userservice").Start(ctx, "UpdatePassword")
+	defer span.End()
+
+	var user models.User
+	if err := s.repo.Get(ctx, &user, "username = ?", username); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return repository.ErrUserNotFound
+		}
+		return err
+	}
+
+	user.PasswordHash = passwordHash
+	return s.repo.Update(ctx, &user)
+}
+
+func (s *UserRepository) UpdateRole(ctx context.Context, username string, role string) (*models.User, error) {
+	ctx, span := otel.Tracer("userservice").Start(ctx, "UpdateRole")
+	defer span.End()
+
+	var user models.User
+	if err := s.repo.Get(ctx, &user, "username = ?", username); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	user.Role = role
+	if err := s.repo.Update(ctx, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
@@ -0,0 +1,258 @@
+package webhookRepository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/Tushar456/go-carzone/apperrors"
+	"github.com/Tushar456/go-carzone/models"
+	"github.com/Tushar456/go-carzone/repository"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WebhookRepository struct {
+	subscriptionRepo *repository.Repository[models.WebhookSubscription]
+	deliveryRepo     *repository.Repository[models.WebhookDelivery]
+
+	logger *slog.Logger
+}
+
+func NewWebhookRepository(db *gorm.DB, logger *slog.Logger) *WebhookRepository {
+	return &WebhookRepository{
+		subscriptionRepo: repository.New[models.WebhookSubscription](db),
+		deliveryRepo:     repository.New[models.WebhookDelivery](db),
+
+		logger: logger,
+	}
+}
+
+func (s *WebhookRepository) GetSubscriptionById(ctx context.Context, id string) (*models.WebhookSubscription, error) {
+	ctx, span := otel.Tracer("webhookservice").Start(ctx, "GetSubscriptionById")
+	defer span.End()
+
+	var subscription models.WebhookSubscription
+	if err := s.subscriptionRepo.Get(ctx, &subscription, "id = ?", id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound("webhook subscription not found")
+		}
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+func (s *WebhookRepository) ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	ctx, span := otel.Tracer("webhookservice").Start(ctx, "ListSubscriptions")
+	defer span.End()
+
+	subscriptions := []models.WebhookSubscription{}
+	if err := s.subscriptionRepo.FindPage(ctx, &subscriptions, nil, "created_at ASC, id ASC", 0, 0); err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+func (s *WebhookRepository) ListActiveSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	ctx, span := otel.Tracer("webhookservice").Start(ctx, "ListActiveSubscriptions")
+	defer span.End()
+
+	var subscriptions []models.WebhookSubscription
+	if err := s.subscriptionRepo.Find(ctx, &subscriptions, "active = ?", true); err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+func (s *WebhookRepository) CreateSubscription(ctx context.Context, subscription *models.WebhookSubscription) (*models.WebhookSubscription, error) {
+	ctx, span := otel.Tracer("webhookservice").Start(ctx, "CreateSubscription")
+	defer span.End()
+
+	if err := s.subscriptionRepo.Create(ctx, subscription); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+func (s *WebhookRepository) UpdateSubscription(ctx context.Context, subscription *models.WebhookSubscription) (*models.WebhookSubscription, error) {
+	ctx, span := otel.Tracer("webhookservice").Start(ctx, "UpdateSubscription")
+	defer span.End()
+
+	updated, err := s.subscriptionRepo.UpdateColumns(ctx, map[string]interface{}{
+		"url":         subscription.URL,
+		"secret":      subscription.Secret,
+		"event_types": subscription.EventTypes,
+		"brand_ids":   subscription.BrandIDs,
+		"active":      subscription.Active,
+		"updated_at":  time.Now(),
+	}, "id = ?", subscription.ID)
+	if err != nil {
+		return nil, err
+	}
+	if updated == 0 {
+		return nil, apperrors.NotFound("webhook subscription not found")
+	}
+	return s.GetSubscriptionById(ctx, subscription.ID.String())
+}
+
+// DeleteSubscription deletes a subscription together with its deliveries.
+func (s *WebhookRepository) DeleteSubscription(ctx context.Context, id string) (*models.WebhookSubscription, error) {
+	ctx, span := otel.Tracer("webhookservice").Start(ctx, "DeleteSubscription")
+	defer span.End()
+
+	subscription, err := s.GetSubscriptionById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.deliveryRepo.DeleteWhere(ctx, "subscription_id = ?", subscription.ID); err != nil {
+		return nil, err
+	}
+	if _, err := s.subscriptionRepo.DeleteWhere(ctx, "id = ?", subscription.ID); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// CreateDeliveries queues deliveries, skipping those of an event already
+// queued for the same subscription.
+func (s *WebhookRepository) CreateDeliveries(ctx context.Context, deliveries []models.WebhookDelivery) error {
+	ctx, span := otel.Tracer("webhookservice").Start(ctx, "CreateDeliveries")
+	defer span.End()
+
+	for i := range deliveries {
+		err := s.deliveryRepo.CreateWith(ctx, &deliveries[i], clause.OnConflict{
+			Columns:   []clause.Column{{Name: "subscription_id"}, {Name: "event_id"}},
+			DoNothing: true,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ClaimDeliveries leases up to limit pending deliveries that are due,
+// oldest first, by moving their next attempt to now plus lease, so that no
+// worker claims them again before the lease runs out. It should run in a
+// short transaction: deliveries locked by another worker are skipped.
+func (s *WebhookRepository) ClaimDeliveries(ctx context.Context, limit int, now time.Time, lease time.Duration) ([]models.WebhookDelivery, error) {
+	ctx, span := otel.Tracer("webhookservice").Start(ctx, "ClaimDeliveries")
+	defer span.End()
+
+	var deliveries []models.WebhookDelivery
+	err := s.deliveryRepo.FindPage(ctx, &deliveries, nil, "next_attempt_at ASC, id ASC", limit, 0, func(db *gorm.DB) *gorm.DB {
+		return db.Where("status = ? AND next_attempt_at <= ?", models.DeliveryStatusPending, now).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(deliveries) == 0 {
+		return deliveries, nil
+	}
+
+	leasedUntil := now.Add(lease)
+	ids := make([]uuid.UUID, len(deliveries))
+	for i := range deliveries {
+		ids[i] = deliveries[i].ID
+		deliveries[i].NextAttemptAt = leasedUntil
+	}
+	if _, err := s.deliveryRepo.UpdateColumns(ctx, map[string]interface{}{"next_attempt_at": leasedUntil}, "id IN ?", ids); err != nil {
+		return nil, err
+	}
+	s.logger.DebugContext(ctx, "Claimed webhook deliveries", "count", len(deliveries), "leased_until", leasedUntil)
+	return deliveries, nil
+}
+
+// SaveDeliveryAttempt stores the outcome of an attempt recorded in delivery.
+func (s *WebhookRepository) SaveDeliveryAttempt(ctx context.Context, delivery *models.WebhookDelivery) error {
+	ctx, span := otel.Tracer("webhookservice").Start(ctx, "SaveDeliveryAttempt")
+	defer span.End()
+
+	_, err := s.deliveryRepo.UpdateColumns(ctx, map[string]interface{}{
+		"status":           delivery.Status,
+		"attempts":         delivery.Attempts,
+		"next_attempt_at":  delivery.NextAttemptAt,
+		"last_attempt_at":  delivery.LastAttemptAt,
+		"last_status_code": delivery.LastStatusCode,
+		"last_error":       delivery.LastError,
+		"delivered_at":     delivery.DeliveredAt,
+	}, "id = ?", delivery.ID)
+	return err
+}
+
+// ListDeliveries returns a page of the deliveries to a subscription, most
+// recent first.
+func (s *WebhookRepository) ListDeliveries(ctx context.Context, subscriptionID string, filter *models.WebhookDeliveryFilter) (*models.Page[models.WebhookDelivery], error) {
+	ctx, span := otel.Tracer("webhookservice").Start(ctx, "ListDeliveries")
+	defer span.End()
+
+	scopes := []repository.Scope{func(db *gorm.DB) *gorm.DB {
+		return db.Where("subscription_id = ?", subscriptionID)
+	}}
+	if filter.Status != "" {
+		scopes = append(scopes, func(db *gorm.DB) *gorm.DB {
+			return db.Where("status = ?", filter.Status)
+		})
+	}
+
+	total, err := s.deliveryRepo.Count(ctx, scopes...)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := []models.WebhookDelivery{}
+	if err := s.deliveryRepo.FindPage(ctx, &deliveries, nil, "created_at DESC, id DESC", filter.Limit, filter.Offset, scopes...); err != nil {
+		return nil, err
+	}
+
+	return &models.Page[models.WebhookDelivery]{
+		Items:  deliveries,
+		Total:  total,
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	}, nil
+}
+
+// Redeliver puts a delivery of a subscription back in the queue, due now,
+// with a fresh allowance of attempts.
+func (s *WebhookRepository) Redeliver(ctx context.Context, subscriptionID string, deliveryID string) (*models.WebhookDelivery, error) {
+	ctx, span := otel.Tracer("webhookservice").Start(ctx, "Redeliver")
+	defer span.End()
+
+	updated, err := s.deliveryRepo.UpdateColumns(ctx, map[string]interface{}{
+		"status":          models.DeliveryStatusPending,
+		"attempts":        0,
+		"next_attempt_at": time.Now(),
+		"delivered_at":    nil,
+	}, "id = ? AND subscription_id = ?", deliveryID, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if updated == 0 {
+		return nil, apperrors.NotFound("webhook delivery not found")
+	}
+
+	var delivery models.WebhookDelivery
+	if err := s.deliveryRepo.Get(ctx, &delivery, "id = ?", deliveryID); err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// NewDelivery returns a pending delivery of an event to a subscription,
+// due now.
+func NewDelivery(subscriptionID uuid.UUID, event *models.OutboxEvent, payload []byte) models.WebhookDelivery {
+	return models.WebhookDelivery{
+		ID:             uuid.New(),
+		SubscriptionID: subscriptionID,
+		EventID:        event.ID,
+		EventType:      event.Type,
+		Payload:        payload,
+		Status:         models.DeliveryStatusPending,
+		NextAttemptAt:  time.Now(),
+	}
+}
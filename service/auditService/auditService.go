@@ -0,0 +1,114 @@
+package auditService
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+
+	"github.com/Tushar456/go-carzone/auth"
+	"github.com/Tushar456/go-carzone/models"
+	"github.com/Tushar456/go-carzone/repository"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type AuditService struct {
+	store  repository.AuditRepositoryInterface
+	logger *slog.Logger
+}
+
+func NewAuditService(store repository.AuditRepositoryInterface, logger *slog.Logger) *AuditService {
+	return &AuditService{
+		store:  store,
+		logger: logger,
+	}
+}
+
+// Record appends an entry for a change to the audit log. The actor comes
+// from the context, as set by the auth middleware, and so does the trace ID.
+func (as *AuditService) Record(ctx context.Context, action, entityType string, entityID uuid.UUID, before, after interface{}) error {
+	ctx, span := otel.Tracer("auditservice").Start(ctx, "Record")
+	defer span.End()
+
+	entry := &models.AuditEntry{
+		ID:         uuid.New(),
+		Actor:      auth.Actor(ctx),
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+	}
+
+	var err error
+	if entry.Before, err = marshalEntity(before); err != nil {
+		return err
+	}
+	if entry.After, err = marshalEntity(after); err != nil {
+		return err
+	}
+	if entry.Diff, err = diff(entry.Before, entry.After); err != nil {
+		return err
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.HasTraceID() {
+		entry.TraceID = spanContext.TraceID().String()
+	}
+
+	if err := as.store.CreateAuditEntry(ctx, entry); err != nil {
+		return err
+	}
+	as.logger.DebugContext(ctx, "Recorded audit entry", "action", action, "entity_type", entityType, "entity_id", entityID)
+	return nil
+}
+
+func (as *AuditService) ListAuditEntries(ctx context.Context, filter *models.AuditFilter) (*models.Page[models.AuditEntry], error) {
+	ctx, span := otel.Tracer("auditservice").Start(ctx, "ListAuditEntries")
+	defer span.End()
+
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	filter.ApplyDefaults()
+
+	return as.store.ListAuditEntries(ctx, filter)
+}
+
+func marshalEntity(entity interface{}) (models.JSON, error) {
+	if entity == nil {
+		return nil, nil
+	}
+	if v := reflect.ValueOf(entity); v.Kind() == reflect.Ptr && v.IsNil() {
+		return nil, nil
+	}
+	return json.Marshal(entity)
+}
+
+// diff compares the top-level fields of two JSON objects and returns those
+// that differ as {"field": {"before": .., "after": ..}}. A missing document
+// counts as an object with no fields.
+func diff(before, after models.JSON) (models.JSON, error) {
+	var b, a map[string]interface{}
+	if len(before) > 0 {
+		if err := json.Unmarshal(before, &b); err != nil {
+			return nil, err
+		}
+	}
+	if len(after) > 0 {
+		if err := json.Unmarshal(after, &a); err != nil {
+			return nil, err
+		}
+	}
+
+	changes := map[string]map[string]interface{}{}
+	for field, value := range b {
+		if other, ok := a[field]; !ok || !reflect.DeepEqual(value, other) {
+			changes[field] = map[string]interface{}{"before": value, "after": a[field]}
+		}
+	}
+	for field, value := range a {
+		if _, ok := b[field]; !ok {
+			changes[field] = map[string]interface{}{"before": nil, "after": value}
+		}
+	}
+	return json.Marshal(changes)
+}
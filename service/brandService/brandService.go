@@ -0,0 +1,168 @@
+package brandService
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Tushar456/go-carzone/apperrors"
+	"github.com/Tushar456/go-carzone/models"
+	"github.com/Tushar456/go-carzone/repository"
+	"github.com/Tushar456/go-carzone/service"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+)
+
+type BrandService struct {
+	store      repository.BrandRepositoryInterface
+	transactor repository.TransactorInterface
+	audit      service.AuditRecorder
+	logger     *slog.Logger
+}
+
+func NewBrandService(store repository.BrandRepositoryInterface, transactor repository.TransactorInterface, audit service.AuditRecorder, logger *slog.Logger) *BrandService {
+	return &BrandService{
+		store:      store,
+		transactor: transactor,
+		audit:      audit,
+		logger:     logger,
+	}
+}
+
+func (bs *BrandService) GetBrandById(ctx context.Context, id string) (*models.Brand, error) {
+	ctx, span := otel.Tracer("brandservice").Start(ctx, "GetBrandById")
+	defer span.End()
+	if err := validateID(id); err != nil {
+		return &models.Brand{}, err
+	}
+	brand, err := bs.store.GetBrandById(ctx, id)
+	if err != nil {
+		return &models.Brand{}, err
+	}
+	return brand, nil
+}
+
+func (bs *BrandService) ListBrands(ctx context.Context, filter *models.BrandFilter) (*models.Page[models.Brand], error) {
+	ctx, span := otel.Tracer("brandservice").Start(ctx, "ListBrands")
+	defer span.End()
+
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	filter.ApplyDefaults()
+
+	return bs.store.ListBrands(ctx, filter.Limit, filter.Offset)
+}
+
+// ResolveBrand returns the brand a name or alias refers to.
+func (bs *BrandService) ResolveBrand(ctx context.Context, name string) (*models.Brand, error) {
+	ctx, span := otel.Tracer("brandservice").Start(ctx, "ResolveBrand")
+	defer span.End()
+	if models.NormalizeBrandName(name) == "" {
+		return &models.Brand{}, apperrors.Validation("name", "name must contain a letter or digit")
+	}
+	brand, err := bs.store.ResolveBrand(ctx, name)
+	if err != nil {
+		return &models.Brand{}, err
+	}
+	return brand, nil
+}
+
+func (bs *BrandService) CreateBrand(ctx context.Context, brandRequest *models.BrandRequest) (*models.Brand, error) {
+	ctx, span := otel.Tracer("brandservice").Start(ctx, "CreateBrand")
+	defer span.End()
+
+	if err := brandRequest.Validate(); err != nil {
+		return &models.Brand{}, err
+	}
+
+	var brand *models.Brand
+	err := bs.transactor.Transaction(ctx, func(ctx context.Context) error {
+		if err := bs.checkNamesFree(ctx, brandRequest, uuid.Nil); err != nil {
+			return err
+		}
+		var err error
+		brand, err = bs.store.CreateBrand(ctx, brandRequest)
+		return err
+	})
+	if err != nil {
+		return &models.Brand{}, err
+	}
+	bs.logger.InfoContext(ctx, "Created brand", "brand_id", brand.ID, "name", brand.Name)
+	return brand, nil
+}
+
+// UpdateBrand replaces a brand's fields and aliases. Renaming a brand also
+// renames its cars, each recorded in the audit log as updated.
+func (bs *BrandService) UpdateBrand(ctx context.Context, id string, brandRequest *models.BrandRequest) (*models.Brand, error) {
+	ctx, span := otel.Tracer("brandservice").Start(ctx, "UpdateBrand")
+	defer span.End()
+	if err := validateID(id); err != nil {
+		return &models.Brand{}, err
+	}
+	if err := brandRequest.Validate(); err != nil {
+		return &models.Brand{}, err
+	}
+
+	var brand *models.Brand
+	err := bs.transactor.Transaction(ctx, func(ctx context.Context) error {
+		if err := bs.checkNamesFree(ctx, brandRequest, uuid.MustParse(id)); err != nil {
+			return err
+		}
+		var renamed []models.RenamedCar
+		var err error
+		if brand, renamed, err = bs.store.UpdateBrand(ctx, id, brandRequest); err != nil {
+			return err
+		}
+		for i := range renamed {
+			car := &renamed[i]
+			if err := bs.audit.Record(ctx, models.AuditActionUpdate, models.AuditEntityCar, car.After.ID, &car.Before, &car.After); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return &models.Brand{}, err
+	}
+	bs.logger.InfoContext(ctx, "Updated brand", "brand_id", brand.ID, "name", brand.Name)
+	return brand, nil
+}
+
+// DeleteBrand deletes a brand no car refers to.
+func (bs *BrandService) DeleteBrand(ctx context.Context, id string) (*models.Brand, error) {
+	ctx, span := otel.Tracer("brandservice").Start(ctx, "DeleteBrand")
+	defer span.End()
+	if err := validateID(id); err != nil {
+		return &models.Brand{}, err
+	}
+	brand, err := bs.store.DeleteBrand(ctx, id)
+	if err != nil {
+		return &models.Brand{}, err
+	}
+	bs.logger.InfoContext(ctx, "Deleted brand", "brand_id", brand.ID, "name", brand.Name)
+	return brand, nil
+}
+
+// checkNamesFree fails with a conflict when the name or an alias of the
+// request already resolves to a brand other than the one with the given ID.
+func (bs *BrandService) checkNamesFree(ctx context.Context, brandRequest *models.BrandRequest, id uuid.UUID) error {
+	names := append([]string{brandRequest.Name}, brandRequest.Aliases...)
+	resolved, err := bs.store.ResolveBrands(ctx, names)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if brand, ok := resolved[models.NormalizeBrandName(name)]; ok && brand.ID != id {
+			return apperrors.Conflict("brand name " + name + " is already used by " + brand.Name).
+				WithDetails(map[string]interface{}{"brand_id": brand.ID})
+		}
+	}
+	return nil
+}
+
+func validateID(id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return apperrors.BadRequest("brand id must be a valid UUID")
+	}
+	return nil
+}
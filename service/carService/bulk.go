@@ -0,0 +1,215 @@
+package carService
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Tushar456/go-carzone/apperrors"
+	"github.com/Tushar456/go-carzone/models"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+)
+
+var errRolledBack = &apperrors.Error{
+	Kind:    apperrors.ErrConflict,
+	Message: "rolled back because another operation failed",
+}
+
+// BulkCars applies a list of create, update and delete operations. Every
+// operation is validated and every engine is looked up, in one query, before
+// anything is written; creates are then inserted in batches. In atomic mode a
+// single failure rolls everything back, in best effort mode the remaining
+// operations still go ahead. A dry run stops before writing. The result
+// reports the outcome of each operation.
+func (cs *CarService) BulkCars(ctx context.Context, options models.BulkOptions, operations []models.BulkCarOperation) (*models.BulkCarResult, error) {
+	ctx, span := otel.Tracer("carservice").Start(ctx, "BulkCars")
+	defer span.End()
+
+	mode, err := models.ValidateBulkMode(options.Mode)
+	if err != nil {
+		return nil, err
+	}
+	if len(operations) == 0 {
+		return nil, apperrors.Validation("operations", "at least one operation is required")
+	}
+	if len(operations) > models.MaxBulkOperations {
+		return nil, apperrors.Validation("operations", "too many operations in one request")
+	}
+
+	result := &models.BulkCarResult{Mode: mode, DryRun: options.DryRun, Items: make([]models.BulkItemResult, len(operations))}
+	for i := range operations {
+		op := &operations[i]
+		err := op.Validate()
+		result.Items[i] = models.BulkItemResult{Index: i, Op: op.Op, ID: op.ID}
+		if err == nil && op.ID != "" {
+			err = validateID(op.ID)
+		}
+		if err != nil {
+			setBulkError(&result.Items[i], err)
+		}
+	}
+
+	if err := cs.checkBulkReferences(ctx, operations, result); err != nil {
+		return nil, err
+	}
+
+	if options.DryRun {
+		for i := range result.Items {
+			if result.Items[i].Error == nil {
+				result.Items[i].Status = http.StatusOK
+			}
+		}
+	} else if mode == models.BulkModeAtomic {
+		if !bulkFailed(result) {
+			err = cs.transactor.Transaction(ctx, func(ctx context.Context) error {
+				return cs.applyBulk(ctx, operations, result, true)
+			})
+			if err != nil && !bulkFailed(result) {
+				return nil, err
+			}
+		}
+		if bulkFailed(result) {
+			for i := range result.Items {
+				if result.Items[i].Error == nil {
+					setBulkError(&result.Items[i], errRolledBack)
+				}
+			}
+		}
+	} else if err := cs.applyBulk(ctx, operations, result, false); err != nil {
+		return nil, err
+	}
+
+	for _, item := range result.Items {
+		if item.Error == nil {
+			result.Succeeded++
+		} else {
+			result.Failed++
+		}
+	}
+	cs.logger.InfoContext(ctx, "Applied bulk car operations", "mode", mode, "dry_run", options.DryRun,
+		"operations", len(operations), "succeeded", result.Succeeded, "failed", result.Failed)
+	return result, nil
+}
+
+// checkBulkReferences marks the creates and updates whose engine does not
+// exist or whose brand is not in the catalog.
+func (cs *CarService) checkBulkReferences(ctx context.Context, operations []models.BulkCarOperation, result *models.BulkCarResult) error {
+	var ids []uuid.UUID
+	var names []string
+	engineIDs := make([]uuid.UUID, len(operations))
+	for i, op := range operations {
+		if result.Items[i].Error != nil || op.Op == models.BulkOpDelete {
+			continue
+		}
+		engineIDs[i] = uuid.MustParse(op.Car.EngineID)
+		ids = append(ids, engineIDs[i])
+		names = append(names, op.Car.Brand)
+	}
+
+	existing, err := cs.store.ExistingEngineIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	brands, err := cs.store.ResolveBrands(ctx, names)
+	if err != nil {
+		return err
+	}
+	for i, op := range operations {
+		if engineIDs[i] == uuid.Nil {
+			continue
+		}
+		if !existing[engineIDs[i]] {
+			setBulkError(&result.Items[i], apperrors.ForeignKey("engine_id", "engine not found"))
+		} else if _, ok := brands[models.NormalizeBrandName(op.Car.Brand)]; !ok {
+			setBulkError(&result.Items[i], apperrors.ForeignKey("brand", "brand is not in the catalog"))
+		}
+	}
+	return nil
+}
+
+// applyBulk writes the valid operations. When stopOnError is set it returns
+// the first error so that the surrounding transaction is rolled back.
+func (cs *CarService) applyBulk(ctx context.Context, operations []models.BulkCarOperation, result *models.BulkCarResult, stopOnError bool) error {
+	var creates []int
+	for i, op := range operations {
+		if op.Op == models.BulkOpCreate && result.Items[i].Error == nil {
+			creates = append(creates, i)
+		}
+	}
+
+	for start := 0; start < len(creates); start += models.BulkBatchSize {
+		batch := creates[start:min(start+models.BulkBatchSize, len(creates))]
+		requests := make([]*models.CarRequest, len(batch))
+		for j, i := range batch {
+			requests[j] = operations[i].Car
+		}
+
+		cars, err := cs.createCars(ctx, requests)
+		if err == nil {
+			for j, i := range batch {
+				setBulkCar(&result.Items[i], &cars[j], http.StatusCreated)
+			}
+			continue
+		}
+		if stopOnError {
+			for _, i := range batch {
+				setBulkError(&result.Items[i], err)
+			}
+			return err
+		}
+		// A batch is a single transaction, so nothing of it was written. Retry
+		// its cars one by one to find out which of them failed.
+		for _, i := range batch {
+			car, err := cs.createCar(ctx, operations[i].Car)
+			if err != nil {
+				setBulkError(&result.Items[i], err)
+				continue
+			}
+			setBulkCar(&result.Items[i], car, http.StatusCreated)
+		}
+	}
+
+	for i, op := range operations {
+		if op.Op == models.BulkOpCreate || result.Items[i].Error != nil {
+			continue
+		}
+
+		var car *models.Car
+		var err error
+		if op.Op == models.BulkOpUpdate {
+			car, err = cs.updateCar(ctx, op.ID, op.Car, op.Version)
+		} else {
+			car, err = cs.deleteCar(ctx, op.ID, op.Version)
+		}
+		if err != nil {
+			setBulkError(&result.Items[i], err)
+			if stopOnError {
+				return err
+			}
+			continue
+		}
+		setBulkCar(&result.Items[i], car, http.StatusOK)
+	}
+	return nil
+}
+
+func bulkFailed(result *models.BulkCarResult) bool {
+	for _, item := range result.Items {
+		if item.Error != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func setBulkCar(item *models.BulkItemResult, car *models.Car, status int) {
+	item.ID = car.ID.String()
+	item.Version = car.Version
+	item.Status = status
+}
+
+func setBulkError(item *models.BulkItemResult, err error) {
+	status, body := apperrors.HTTPStatus(err)
+	item.Status = status
+	item.Error = &body
+}
@@ -2,25 +2,42 @@ package carService
 
 import (
 	"context"
+	"log/slog"
+	"time"
 
+	"github.com/Tushar456/go-carzone/apperrors"
+	"github.com/Tushar456/go-carzone/auth"
 	"github.com/Tushar456/go-carzone/models"
 	"github.com/Tushar456/go-carzone/repository"
+	"github.com/Tushar456/go-carzone/service"
+	"github.com/google/uuid"
 	"go.opentelemetry.io/otel"
 )
 
 type CarService struct {
-	store repository.CarRepositoryInterface
+	store      repository.CarRepositoryInterface
+	transactor repository.TransactorInterface
+	audit      service.AuditRecorder
+	rates      models.ExchangeRates
+	logger     *slog.Logger
 }
 
-func NewCarService(store repository.CarRepositoryInterface) *CarService {
+func NewCarService(store repository.CarRepositoryInterface, transactor repository.TransactorInterface, audit service.AuditRecorder, rates models.ExchangeRates, logger *slog.Logger) *CarService {
 	return &CarService{
-		store: store,
+		store:      store,
+		transactor: transactor,
+		audit:      audit,
+		rates:      rates,
+		logger:     logger,
 	}
 }
 
 func (cs *CarService) GetCarById(ctx context.Context, id string) (*models.Car, error) {
 	ctx, span := otel.Tracer("carservice").Start(ctx, "GetCarById")
 	defer span.End()
+	if err := validateID(id); err != nil {
+		return &models.Car{}, err
+	}
 	car, err := cs.store.GetCarById(ctx, id)
 	if err != nil {
 		return &models.Car{}, err
@@ -38,6 +55,44 @@ func (cs *CarService) GetCarByBrand(ctx context.Context, brand string, isEngine
 	return cars, nil
 }
 
+func (cs *CarService) ListCars(ctx context.Context, filter *models.CarFilter) (*models.Page[models.Car], error) {
+	ctx, span := otel.Tracer("carservice").Start(ctx, "ListCars")
+	defer span.End()
+
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	if err := cs.checkConvertTo(filter.ConvertTo); err != nil {
+		return nil, err
+	}
+	filter.ApplyDefaults()
+
+	page, err := cs.store.ListCars(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if filter.ConvertTo != "" {
+		cs.convertPrices(page.Items, filter.ConvertTo)
+	}
+	return page, nil
+}
+
+func (cs *CarService) SearchCars(ctx context.Context, query *models.CarSearchQuery) (*models.Page[models.CarSearchResult], error) {
+	ctx, span := otel.Tracer("carservice").Start(ctx, "SearchCars")
+	defer span.End()
+
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+	query.ApplyDefaults()
+
+	page, err := cs.store.SearchCars(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
 func (cs *CarService) CreateCar(ctx context.Context, car *models.CarRequest) (*models.Car, error) {
 	ctx, span := otel.Tracer("carservice").Start(ctx, "CreateCar")
 	defer span.End()
@@ -45,34 +100,200 @@ func (cs *CarService) CreateCar(ctx context.Context, car *models.CarRequest) (*m
 	if err := car.Validate(); err != nil {
 		return &models.Car{}, err
 	}
-	createdCar, err := cs.store.CreateCar(ctx, car)
+	createdCar, err := cs.createCar(ctx, car)
 	if err != nil {
 		return &models.Car{}, err
 	}
+	cs.logger.InfoContext(ctx, "Created car", "car_id", createdCar.ID)
 	return createdCar, nil
 
 }
 
-func (cs *CarService) UpdateCar(ctx context.Context, id string, carRequest *models.CarRequest) (*models.Car, error) {
+// UpdateCar updates a car. A non-zero version must match the stored one.
+func (cs *CarService) UpdateCar(ctx context.Context, id string, carRequest *models.CarRequest, version int64) (*models.Car, error) {
 	ctx, span := otel.Tracer("carservice").Start(ctx, "UpdateCar")
 	defer span.End()
+	if err := validateID(id); err != nil {
+		return &models.Car{}, err
+	}
 	if err := carRequest.Validate(); err != nil {
 		return &models.Car{}, err
 	}
-	car, err := cs.store.UpdateCar(ctx, id, carRequest)
+	car, err := cs.updateCar(ctx, id, carRequest, version)
 	if err != nil {
 		return &models.Car{}, err
 	}
+	cs.logger.InfoContext(ctx, "Updated car", "car_id", car.ID, "version", car.Version)
 	return car, nil
 
 }
 
-func (cs *CarService) DeleteCar(ctx context.Context, id string) (*models.Car, error) {
+// PatchCar applies a JSON Merge Patch or JSON Patch to the stored car and
+// saves the result after the same validation as UpdateCar. The patch is
+// applied to the version it was computed against, so a concurrent update makes
+// it fail instead of being overwritten.
+func (cs *CarService) PatchCar(ctx context.Context, id string, contentType string, patch []byte, version int64) (*models.Car, error) {
+	ctx, span := otel.Tracer("carservice").Start(ctx, "PatchCar")
+	defer span.End()
+	if err := validateID(id); err != nil {
+		return &models.Car{}, err
+	}
+	car, err := cs.store.GetCarById(ctx, id)
+	if err != nil {
+		return &models.Car{}, err
+	}
+	if version != 0 && car.Version != version {
+		return &models.Car{}, repository.ErrCarModified
+	}
+
+	current := car.ToRequest()
+	var carRequest models.CarRequest
+	if err := models.ApplyPatch(contentType, patch, &current, &carRequest); err != nil {
+		return &models.Car{}, err
+	}
+	if err := carRequest.Validate(); err != nil {
+		return &models.Car{}, err
+	}
+
+	patchedCar, err := cs.updateCar(ctx, id, &carRequest, car.Version)
+	if err != nil {
+		return &models.Car{}, err
+	}
+	cs.logger.InfoContext(ctx, "Patched car", "car_id", patchedCar.ID, "version", patchedCar.Version)
+	return patchedCar, nil
+}
+
+// createCar saves a car and its audit entry together.
+func (cs *CarService) createCar(ctx context.Context, carRequest *models.CarRequest) (*models.Car, error) {
+	carRequest.Price.FillCurrency(models.DefaultCurrency)
+	var car *models.Car
+	err := cs.transactor.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		if car, err = cs.store.CreateCar(ctx, carRequest); err != nil {
+			return err
+		}
+		return cs.audit.Record(ctx, models.AuditActionCreate, models.AuditEntityCar, car.ID, nil, car)
+	})
+	return car, err
+}
+
+// createCars saves a batch of cars and their audit entries together.
+func (cs *CarService) createCars(ctx context.Context, carRequests []*models.CarRequest) ([]models.Car, error) {
+	for _, carRequest := range carRequests {
+		carRequest.Price.FillCurrency(models.DefaultCurrency)
+	}
+	var cars []models.Car
+	err := cs.transactor.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		if cars, err = cs.store.CreateCars(ctx, carRequests, models.BulkBatchSize); err != nil {
+			return err
+		}
+		for i := range cars {
+			if err := cs.audit.Record(ctx, models.AuditActionCreate, models.AuditEntityCar, cars[i].ID, nil, &cars[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return cars, err
+}
+
+// updateCar saves a car together with the record of a change of its price
+// and its audit entry. A price without a currency keeps the car's.
+func (cs *CarService) updateCar(ctx context.Context, id string, carRequest *models.CarRequest, version int64) (*models.Car, error) {
+	var car *models.Car
+	err := cs.transactor.Transaction(ctx, func(ctx context.Context) error {
+		before, err := cs.store.GetCarById(ctx, id)
+		if err != nil {
+			return err
+		}
+		carRequest.Price.FillCurrency(before.Price.Currency)
+		if car, err = cs.store.UpdateCar(ctx, id, carRequest, version); err != nil {
+			return err
+		}
+		return cs.audit.Record(ctx, models.AuditActionUpdate, models.AuditEntityCar, car.ID, before, car)
+	})
+	return car, err
+}
+
+// deleteCar soft deletes a car and records it in the audit log together.
+func (cs *CarService) deleteCar(ctx context.Context, id string, version int64) (*models.Car, error) {
+	var car *models.Car
+	err := cs.transactor.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		if car, err = cs.store.DeleteCar(ctx, id, version); err != nil {
+			return err
+		}
+		return cs.audit.Record(ctx, models.AuditActionDelete, models.AuditEntityCar, car.ID, car, nil)
+	})
+	return car, err
+}
+
+// DeleteCar deletes a car. A non-zero version must match the stored one.
+func (cs *CarService) DeleteCar(ctx context.Context, id string, version int64) (*models.Car, error) {
 	ctx, span := otel.Tracer("carservice").Start(ctx, "DeleteCar")
 	defer span.End()
-	car, err := cs.store.DeleteCar(ctx, id)
+	if err := validateID(id); err != nil {
+		return &models.Car{}, err
+	}
+	car, err := cs.deleteCar(ctx, id, version)
 	if err != nil {
 		return &models.Car{}, err
 	}
+	cs.logger.InfoContext(ctx, "Deleted car", "car_id", car.ID)
 	return car, nil
 }
+
+func (cs *CarService) RestoreCar(ctx context.Context, id string) (*models.Car, error) {
+	ctx, span := otel.Tracer("carservice").Start(ctx, "RestoreCar")
+	defer span.End()
+	if err := validateID(id); err != nil {
+		return &models.Car{}, err
+	}
+	var car *models.Car
+	err := cs.transactor.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		if car, err = cs.store.RestoreCar(ctx, id); err != nil {
+			return err
+		}
+		return cs.audit.Record(ctx, models.AuditActionRestore, models.AuditEntityCar, car.ID, nil, car)
+	})
+	if err != nil {
+		return &models.Car{}, err
+	}
+	cs.logger.InfoContext(ctx, "Restored car", "car_id", car.ID)
+	return car, nil
+}
+
+// PurgeDeletedCars permanently removes cars soft deleted before the given
+// time, recording each in the audit log as deleted by the system.
+func (cs *CarService) PurgeDeletedCars(ctx context.Context, before time.Time) (int64, error) {
+	ctx, span := otel.Tracer("carservice").Start(ctx, "PurgeDeletedCars")
+	defer span.End()
+
+	ctx = auth.WithActor(ctx, auth.SystemActor)
+	var cars []models.Car
+	err := cs.transactor.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		if cars, err = cs.store.PurgeDeletedCars(ctx, before); err != nil {
+			return err
+		}
+		for i := range cars {
+			if err := cs.audit.Record(ctx, models.AuditActionDelete, models.AuditEntityCar, cars[i].ID, &cars[i], nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(cars)), nil
+}
+
+func validateID(id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return apperrors.BadRequest("car id must be a valid UUID")
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package carService
+
+import (
+	"context"
+
+	"github.com/Tushar456/go-carzone/apperrors"
+	"github.com/Tushar456/go-carzone/models"
+	"go.opentelemetry.io/otel"
+)
+
+// GetCarPriceHistory returns the price changes of a car, most recent first.
+func (cs *CarService) GetCarPriceHistory(ctx context.Context, id string) ([]models.CarPriceHistory, error) {
+	ctx, span := otel.Tracer("carservice").Start(ctx, "GetCarPriceHistory")
+	defer span.End()
+	if err := validateID(id); err != nil {
+		return nil, err
+	}
+	return cs.store.GetPriceHistory(ctx, id)
+}
+
+// checkConvertTo fails when prices cannot be converted into the currency
+// using the configured exchange rates.
+func (cs *CarService) checkConvertTo(currency string) error {
+	if currency != "" && !cs.rates.Has(currency) {
+		return apperrors.Validation("convert_to", "no exchange rate is configured for "+currency)
+	}
+	return nil
+}
+
+// convertPrices converts the price of each car into the currency, keeping
+// the stored price in OriginalPrice. A car whose currency has no exchange
+// rate keeps its stored price and no OriginalPrice.
+func (cs *CarService) convertPrices(cars []models.Car, currency string) {
+	for i := range cars {
+		car := &cars[i]
+		if car.Price.Currency == currency {
+			continue
+		}
+		price, err := cs.rates.Convert(car.Price, currency)
+		if err != nil {
+			continue
+		}
+		original := car.Price
+		car.Price, car.OriginalPrice = price, &original
+	}
+}
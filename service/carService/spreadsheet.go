@@ -0,0 +1,136 @@
+package carService
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Tushar456/go-carzone/apperrors"
+	"github.com/Tushar456/go-carzone/models"
+	"go.opentelemetry.io/otel"
+)
+
+// exportPageSize is the number of cars read per query while exporting.
+const exportPageSize = 500
+
+var errNotImported = &apperrors.Error{
+	Kind:    apperrors.ErrConflict,
+	Message: "not imported because another row was rejected",
+}
+
+// ExportCars calls each for every car matching the filter, with its engine,
+// reading the inventory page by page so that it never has to fit in memory.
+// The limit and offset of the filter are ignored.
+func (cs *CarService) ExportCars(ctx context.Context, filter *models.CarFilter, each func(car *models.Car) error) error {
+	ctx, span := otel.Tracer("carservice").Start(ctx, "ExportCars")
+	defer span.End()
+
+	filter.Limit, filter.Offset, filter.Cursor = 0, 0, ""
+	if err := filter.Validate(); err != nil {
+		return err
+	}
+	filter.ApplyDefaults()
+	filter.Limit = exportPageSize
+	filter.IsEngine = true
+
+	for {
+		page, err := cs.store.ListCars(ctx, filter)
+		if err != nil {
+			return err
+		}
+		for i := range page.Items {
+			if err := each(&page.Items[i]); err != nil {
+				return err
+			}
+		}
+		if page.NextCursor == "" {
+			return nil
+		}
+		filter.Cursor = page.NextCursor
+	}
+}
+
+// ImportCars creates a car from each row of a spreadsheet whose first row
+// is the header. Rows are parsed and validated like CarRequest bodies and
+// then written through BulkCars, so the mode and dry run options mean the
+// same thing as for the bulk endpoint.
+func (cs *CarService) ImportCars(ctx context.Context, options models.BulkOptions, rows [][]string, mapping map[string]string) (*models.ImportResult, error) {
+	ctx, span := otel.Tracer("carservice").Start(ctx, "ImportCars")
+	defer span.End()
+
+	mode, err := models.ValidateBulkMode(options.Mode)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, apperrors.Validation("file", "file is empty")
+	}
+	fields, err := models.MapCarImportHeader(rows[0], mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.ImportResult{Mode: mode, DryRun: options.DryRun}
+	var operations []models.BulkCarOperation
+	var operationRows []int
+	for i, row := range rows[1:] {
+		if isBlankRow(row) {
+			continue
+		}
+		rowResult := models.ImportRowResult{Row: i + 2}
+		carRequest, err := models.CarRequestFromRow(fields, row)
+		if err != nil {
+			status, body := apperrors.HTTPStatus(err)
+			rowResult.Status, rowResult.Error = status, &body
+		} else {
+			operations = append(operations, models.BulkCarOperation{Op: models.BulkOpCreate, Car: carRequest})
+			operationRows = append(operationRows, len(result.Rows))
+		}
+		result.Rows = append(result.Rows, rowResult)
+	}
+	if len(result.Rows) == 0 {
+		return nil, apperrors.Validation("file", "file has no data rows")
+	}
+
+	unparsed := len(result.Rows) - len(operations)
+	if len(operations) > 0 {
+		bulkOptions := options
+		// In atomic mode a row that could not even be parsed must stop the
+		// others from being written, so they are only checked.
+		if mode == models.BulkModeAtomic && unparsed > 0 {
+			bulkOptions.DryRun = true
+		}
+
+		bulk, err := cs.BulkCars(ctx, bulkOptions, operations)
+		if err != nil {
+			return nil, err
+		}
+		for i, item := range bulk.Items {
+			rowResult := &result.Rows[operationRows[i]]
+			rowResult.ID, rowResult.Status, rowResult.Error = item.ID, item.Status, item.Error
+			if bulkOptions.DryRun && !options.DryRun && item.Error == nil {
+				status, body := apperrors.HTTPStatus(errNotImported)
+				rowResult.Status, rowResult.Error = status, &body
+			}
+		}
+	}
+
+	for _, row := range result.Rows {
+		if row.Error == nil {
+			result.Accepted++
+		} else {
+			result.Rejected++
+		}
+	}
+	cs.logger.InfoContext(ctx, "Imported cars", "mode", mode, "dry_run", options.DryRun,
+		"rows", len(result.Rows), "accepted", result.Accepted, "rejected", result.Rejected)
+	return result, nil
+}
+
+func isBlankRow(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
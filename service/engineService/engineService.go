@@ -2,25 +2,42 @@ package engineService
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
 
+	"github.com/Tushar456/go-carzone/apperrors"
+	"github.com/Tushar456/go-carzone/auth"
 	"github.com/Tushar456/go-carzone/models"
 	"github.com/Tushar456/go-carzone/repository"
+	"github.com/Tushar456/go-carzone/service"
+	"github.com/google/uuid"
 	"go.opentelemetry.io/otel"
 )
 
 type EngineService struct {
-	store repository.EngineRepositoryInterface
+	store      repository.EngineRepositoryInterface
+	transactor repository.TransactorInterface
+	audit      service.AuditRecorder
+	logger     *slog.Logger
 }
 
-func NewEngineService(store repository.EngineRepositoryInterface) *EngineService {
+func NewEngineService(store repository.EngineRepositoryInterface, transactor repository.TransactorInterface, audit service.AuditRecorder, logger *slog.Logger) *EngineService {
 	return &EngineService{
-		store: store,
+		store:      store,
+		transactor: transactor,
+		audit:      audit,
+		logger:     logger,
 	}
 }
 
 func (es *EngineService) GetEngineById(ctx context.Context, id string) (*models.Engine, error) {
 	ctx, span := otel.Tracer("engineservice").Start(ctx, "GetEngineById")
 	defer span.End()
+	if err := validateID(id); err != nil {
+		return &models.Engine{}, err
+	}
 	engine, err := es.store.GetEngineById(ctx, id)
 	if err != nil {
 		return &models.Engine{}, err
@@ -36,33 +53,204 @@ func (es *EngineService) CreateEngine(ctx context.Context, engine *models.Engine
 	if err := engine.Validate(); err != nil {
 		return &models.Engine{}, err
 	}
-	createdEngine, err := es.store.CreateEngine(ctx, engine)
+	var createdEngine *models.Engine
+	err := es.transactor.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		if createdEngine, err = es.store.CreateEngine(ctx, engine); err != nil {
+			return err
+		}
+		return es.audit.Record(ctx, models.AuditActionCreate, models.AuditEntityEngine, createdEngine.ID, nil, createdEngine)
+	})
 	if err != nil {
 		return &models.Engine{}, err
 	}
+	es.logger.InfoContext(ctx, "Created engine", "engine_id", createdEngine.ID)
 	return createdEngine, nil
 
 }
 
-func (es *EngineService) UpdateEngine(ctx context.Context, id string, engineRequest *models.EngineRequest) (*models.Engine, error) {
+// UpdateEngine updates an engine. A non-zero version must match the stored one.
+func (es *EngineService) UpdateEngine(ctx context.Context, id string, engineRequest *models.EngineRequest, version int64) (*models.Engine, error) {
 	ctx, span := otel.Tracer("engineservice").Start(ctx, "UpdateEngine")
 	defer span.End()
+	if err := validateID(id); err != nil {
+		return &models.Engine{}, err
+	}
 	if err := engineRequest.Validate(); err != nil {
 		return &models.Engine{}, err
 	}
-	updatedEngine, err := es.store.UpdateEngine(ctx, id, engineRequest)
+	updatedEngine, err := es.updateEngine(ctx, id, engineRequest, version)
 	if err != nil {
 		return &models.Engine{}, err
 	}
+	es.logger.InfoContext(ctx, "Updated engine", "engine_id", updatedEngine.ID, "version", updatedEngine.Version)
 	return updatedEngine, nil
 }
 
-func (es *EngineService) DeleteEngine(ctx context.Context, id string) (*models.Engine, error) {
+// PatchEngine applies a JSON Merge Patch or JSON Patch to the stored engine
+// and saves the result after the same validation as UpdateEngine.
+func (es *EngineService) PatchEngine(ctx context.Context, id string, contentType string, patch []byte, version int64) (*models.Engine, error) {
+	ctx, span := otel.Tracer("engineservice").Start(ctx, "PatchEngine")
+	defer span.End()
+	if err := validateID(id); err != nil {
+		return &models.Engine{}, err
+	}
+	engine, err := es.store.GetEngineById(ctx, id)
+	if err != nil {
+		return &models.Engine{}, err
+	}
+	if version != 0 && engine.Version != version {
+		return &models.Engine{}, repository.ErrEngineModified
+	}
+
+	current := engine.ToRequest()
+	var engineRequest models.EngineRequest
+	if err := models.ApplyPatch(contentType, patch, &current, &engineRequest); err != nil {
+		return &models.Engine{}, err
+	}
+	if err := engineRequest.Validate(); err != nil {
+		return &models.Engine{}, err
+	}
+
+	patchedEngine, err := es.updateEngine(ctx, id, &engineRequest, engine.Version)
+	if err != nil {
+		return &models.Engine{}, err
+	}
+	es.logger.InfoContext(ctx, "Patched engine", "engine_id", patchedEngine.ID, "version", patchedEngine.Version)
+	return patchedEngine, nil
+}
+
+// DeleteEngine deletes an engine that no car uses. With cascade=reassign the
+// cars are moved to another engine first, in the same transaction. A non-zero
+// version must match the stored one.
+func (es *EngineService) DeleteEngine(ctx context.Context, id string, version int64, options *models.DeleteEngineOptions) (*models.Engine, error) {
 	ctx, span := otel.Tracer("engineservice").Start(ctx, "DeleteEngine")
 	defer span.End()
-	deletedEngine, err := es.store.DeleteEngine(ctx, id)
+	if err := validateID(id); err != nil {
+		return &models.Engine{}, err
+	}
+	if err := options.Validate(); err != nil {
+		return &models.Engine{}, err
+	}
+	if options.Cascade == models.CascadeReassign && options.To == id {
+		return &models.Engine{}, apperrors.Validation("to", "cannot reassign cars to the engine being deleted")
+	}
+
+	var deletedEngine *models.Engine
+	var reassigned []uuid.UUID
+	err := es.transactor.Transaction(ctx, func(ctx context.Context) error {
+		if options.Cascade == models.CascadeReassign {
+			if _, err := es.store.GetEngineById(ctx, options.To); err != nil {
+				if errors.Is(err, apperrors.ErrNotFound) {
+					return apperrors.ForeignKey("to", "engine to reassign cars to not found")
+				}
+				return err
+			}
+			var err error
+			if reassigned, err = es.store.ReassignCars(ctx, id, options.To); err != nil {
+				return err
+			}
+			for _, carID := range reassigned {
+				err := es.audit.Record(ctx, models.AuditActionUpdate, models.AuditEntityCar, carID,
+					map[string]string{"engine_id": id}, map[string]string{"engine_id": options.To})
+				if err != nil {
+					return err
+				}
+			}
+		} else {
+			carIDs, err := es.store.GetReferencingCarIDs(ctx, id)
+			if err != nil {
+				return err
+			}
+			if len(carIDs) > 0 {
+				return apperrors.Conflict(fmt.Sprintf("engine is still used by %d cars", len(carIDs))).
+					WithDetails(map[string]interface{}{"car_ids": carIDs})
+			}
+		}
+
+		var err error
+		if deletedEngine, err = es.store.DeleteEngine(ctx, id, version); err != nil {
+			return err
+		}
+		return es.audit.Record(ctx, models.AuditActionDelete, models.AuditEntityEngine, deletedEngine.ID, deletedEngine, nil)
+	})
 	if err != nil {
 		return &models.Engine{}, err
 	}
+	if len(reassigned) > 0 {
+		es.logger.InfoContext(ctx, "Deleted engine", "engine_id", deletedEngine.ID, "reassigned_cars", len(reassigned), "to", options.To)
+	} else {
+		es.logger.InfoContext(ctx, "Deleted engine", "engine_id", deletedEngine.ID)
+	}
 	return deletedEngine, nil
 }
+
+func (es *EngineService) RestoreEngine(ctx context.Context, id string) (*models.Engine, error) {
+	ctx, span := otel.Tracer("engineservice").Start(ctx, "RestoreEngine")
+	defer span.End()
+	if err := validateID(id); err != nil {
+		return &models.Engine{}, err
+	}
+	var restoredEngine *models.Engine
+	err := es.transactor.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		if restoredEngine, err = es.store.RestoreEngine(ctx, id); err != nil {
+			return err
+		}
+		return es.audit.Record(ctx, models.AuditActionRestore, models.AuditEntityEngine, restoredEngine.ID, nil, restoredEngine)
+	})
+	if err != nil {
+		return &models.Engine{}, err
+	}
+	es.logger.InfoContext(ctx, "Restored engine", "engine_id", restoredEngine.ID)
+	return restoredEngine, nil
+}
+
+// updateEngine saves an engine and its audit entry together.
+func (es *EngineService) updateEngine(ctx context.Context, id string, engineRequest *models.EngineRequest, version int64) (*models.Engine, error) {
+	var engine *models.Engine
+	err := es.transactor.Transaction(ctx, func(ctx context.Context) error {
+		before, err := es.store.GetEngineById(ctx, id)
+		if err != nil {
+			return err
+		}
+		if engine, err = es.store.UpdateEngine(ctx, id, engineRequest, version); err != nil {
+			return err
+		}
+		return es.audit.Record(ctx, models.AuditActionUpdate, models.AuditEntityEngine, engine.ID, before, engine)
+	})
+	return engine, err
+}
+
+// PurgeDeletedEngines permanently removes engines soft deleted before the
+// given time, recording each in the audit log as deleted by the system.
+func (es *EngineService) PurgeDeletedEngines(ctx context.Context, before time.Time) (int64, error) {
+	ctx, span := otel.Tracer("engineservice").Start(ctx, "PurgeDeletedEngines")
+	defer span.End()
+
+	ctx = auth.WithActor(ctx, auth.SystemActor)
+	var engines []models.Engine
+	err := es.transactor.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		if engines, err = es.store.PurgeDeletedEngines(ctx, before); err != nil {
+			return err
+		}
+		for i := range engines {
+			if err := es.audit.Record(ctx, models.AuditActionDelete, models.AuditEntityEngine, engines[i].ID, &engines[i], nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(engines)), nil
+}
+
+func validateID(id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return apperrors.BadRequest("engine id must be a valid UUID")
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package engineService_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Tushar456/go-carzone/models"
+	"github.com/Tushar456/go-carzone/repository/contract"
+	memoryRepository "github.com/Tushar456/go-carzone/repository/memory-repository"
+	"github.com/Tushar456/go-carzone/service/engineService"
+	"github.com/google/uuid"
+)
+
+// recorder keeps the audit entries recorded through it.
+type recorder struct {
+	entries []entry
+}
+
+type entry struct {
+	action, entityType string
+	entityID           uuid.UUID
+}
+
+func (r *recorder) Record(ctx context.Context, action, entityType string, entityID uuid.UUID, before, after interface{}) error {
+	r.entries = append(r.entries, entry{action, entityType, entityID})
+	return nil
+}
+
+func TestDeleteEngineReassignAuditsEveryMovedCar(t *testing.T) {
+	ctx := context.Background()
+	db, logger := memoryRepository.NewDB(), contract.Logger(t)
+	if _, err := db.AddBrand(&models.BrandRequest{Name: "Toyota"}); err != nil {
+		t.Fatalf("AddBrand: %v", err)
+	}
+	engines, cars := memoryRepository.NewEngineRepository(db, logger), memoryRepository.NewCarRepository(db, logger)
+	audit := &recorder{}
+	service := engineService.NewEngineService(engines, memoryRepository.NewTransactor(db), audit, logger)
+
+	from, err := engines.CreateEngine(ctx, &models.EngineRequest{Displacement: 1998, NoOfCylinders: 4})
+	if err != nil {
+		t.Fatalf("CreateEngine: %v", err)
+	}
+	to, err := engines.CreateEngine(ctx, &models.EngineRequest{Displacement: 2494, NoOfCylinders: 4})
+	if err != nil {
+		t.Fatalf("CreateEngine: %v", err)
+	}
+	moved := map[uuid.UUID]bool{}
+	for _, name := range []string{"Corolla", "Yaris"} {
+		car, err := cars.CreateCar(ctx, &models.CarRequest{Name: name, Year: "2020", Brand: "Toyota", FuelType: "Petrol", EngineID: from.ID.String()})
+		if err != nil {
+			t.Fatalf("CreateCar: %v", err)
+		}
+		moved[car.ID] = true
+		if name == "Yaris" {
+			if _, err := cars.DeleteCar(ctx, car.ID.String(), 0); err != nil {
+				t.Fatalf("DeleteCar: %v", err)
+			}
+		}
+	}
+
+	options := &models.DeleteEngineOptions{Cascade: models.CascadeReassign, To: to.ID.String()}
+	if _, err := service.DeleteEngine(ctx, from.ID.String(), 0, options); err != nil {
+		t.Fatalf("DeleteEngine: %v", err)
+	}
+
+	audited := map[uuid.UUID]bool{}
+	for _, e := range audit.entries {
+		if e.entityType == models.AuditEntityCar && e.action == models.AuditActionUpdate {
+			audited[e.entityID] = true
+		}
+	}
+	if len(audited) != len(moved) {
+		t.Fatalf("audited %d moved cars, want %d, the soft deleted one included", len(audited), len(moved))
+	}
+	for id := range moved {
+		if !audited[id] {
+			t.Errorf("car %s was moved but not audited", id)
+		}
+	}
+}
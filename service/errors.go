@@ -0,0 +1,10 @@
+package service
+
+import "github.com/Tushar456/go-carzone/apperrors"
+
+var (
+	ErrInvalidCredentials  = apperrors.Unauthorized("invalid credentials")
+	ErrUsernameTaken       = apperrors.Conflict("username already taken")
+	ErrInvalidRefreshToken = apperrors.Unauthorized("invalid refresh token")
+	ErrRefreshTokenReused  = apperrors.Unauthorized("refresh token reused")
+)
@@ -2,21 +2,84 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/Tushar456/go-carzone/models"
+	"github.com/google/uuid"
 )
 
 type CarServiceInterface interface {
 	GetCarById(ctx context.Context, id string) (*models.Car, error)
 	GetCarByBrand(ctx context.Context, brand string, isEngine bool) ([]models.Car, error)
+	ListCars(ctx context.Context, filter *models.CarFilter) (*models.Page[models.Car], error)
+	SearchCars(ctx context.Context, query *models.CarSearchQuery) (*models.Page[models.CarSearchResult], error)
 	CreateCar(ctx context.Context, car *models.CarRequest) (*models.Car, error)
-	UpdateCar(ctx context.Context, id string, updateCar *models.CarRequest) (*models.Car, error)
-	DeleteCar(ctx context.Context, id string) (*models.Car, error)
+	BulkCars(ctx context.Context, options models.BulkOptions, operations []models.BulkCarOperation) (*models.BulkCarResult, error)
+	ExportCars(ctx context.Context, filter *models.CarFilter, each func(car *models.Car) error) error
+	ImportCars(ctx context.Context, options models.BulkOptions, rows [][]string, mapping map[string]string) (*models.ImportResult, error)
+	UpdateCar(ctx context.Context, id string, updateCar *models.CarRequest, version int64) (*models.Car, error)
+	PatchCar(ctx context.Context, id string, contentType string, patch []byte, version int64) (*models.Car, error)
+	GetCarPriceHistory(ctx context.Context, id string) ([]models.CarPriceHistory, error)
+	DeleteCar(ctx context.Context, id string, version int64) (*models.Car, error)
+	RestoreCar(ctx context.Context, id string) (*models.Car, error)
+	PurgeDeletedCars(ctx context.Context, before time.Time) (int64, error)
 }
 
 type EngineServiceInterface interface {
 	GetEngineById(ctx context.Context, id string) (*models.Engine, error)
 	CreateEngine(ctx context.Context, engine *models.EngineRequest) (*models.Engine, error)
-	UpdateEngine(ctx context.Context, id string, updateEngine *models.EngineRequest) (*models.Engine, error)
-	DeleteEngine(ctx context.Context, id string) (*models.Engine, error)
+	UpdateEngine(ctx context.Context, id string, updateEngine *models.EngineRequest, version int64) (*models.Engine, error)
+	PatchEngine(ctx context.Context, id string, contentType string, patch []byte, version int64) (*models.Engine, error)
+	DeleteEngine(ctx context.Context, id string, version int64, options *models.DeleteEngineOptions) (*models.Engine, error)
+	RestoreEngine(ctx context.Context, id string) (*models.Engine, error)
+	PurgeDeletedEngines(ctx context.Context, before time.Time) (int64, error)
+}
+
+type BrandServiceInterface interface {
+	GetBrandById(ctx context.Context, id string) (*models.Brand, error)
+	ListBrands(ctx context.Context, filter *models.BrandFilter) (*models.Page[models.Brand], error)
+	ResolveBrand(ctx context.Context, name string) (*models.Brand, error)
+	CreateBrand(ctx context.Context, brand *models.BrandRequest) (*models.Brand, error)
+	UpdateBrand(ctx context.Context, id string, updateBrand *models.BrandRequest) (*models.Brand, error)
+	DeleteBrand(ctx context.Context, id string) (*models.Brand, error)
+}
+
+// AuditRecorder records a change to a car or engine in the audit log.
+// before is nil for a creation and after is nil for a deletion. Called with
+// the context of the transaction making the change, the record is kept only
+// if the change is.
+type AuditRecorder interface {
+	Record(ctx context.Context, action, entityType string, entityID uuid.UUID, before, after interface{}) error
+}
+
+type AuditServiceInterface interface {
+	AuditRecorder
+	ListAuditEntries(ctx context.Context, filter *models.AuditFilter) (*models.Page[models.AuditEntry], error)
+}
+
+type WebhookServiceInterface interface {
+	GetSubscriptionById(ctx context.Context, id string) (*models.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error)
+	CreateSubscription(ctx context.Context, request *models.WebhookSubscriptionRequest) (*models.CreatedWebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, id string, request *models.WebhookSubscriptionRequest) (*models.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id string) (*models.WebhookSubscription, error)
+	ListDeliveries(ctx context.Context, id string, filter *models.WebhookDeliveryFilter) (*models.Page[models.WebhookDelivery], error)
+	Redeliver(ctx context.Context, id string, deliveryID string) (*models.WebhookDelivery, error)
+}
+
+type UserServiceInterface interface {
+	Register(ctx context.Context, request *models.RegisterRequest) (*models.User, error)
+	Authenticate(ctx context.Context, credentials *models.Credentials) (*models.User, error)
+	ChangePassword(ctx context.Context, username string, request *models.ChangePasswordRequest) error
+	UpdateRole(ctx context.Context, username string, request *models.UpdateRoleRequest) (*models.User, error)
+	EnsureAdmin(ctx context.Context, username string, password string) error
+}
+
+type TokenServiceInterface interface {
+	IssueRefreshToken(ctx context.Context, user *models.User) (string, error)
+	RotateRefreshToken(ctx context.Context, refreshToken string) (*models.User, string, error)
+	RevokeRefreshToken(ctx context.Context, refreshToken string) error
+	RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+	PurgeExpiredTokens(ctx context.Context) (int64, error)
 }
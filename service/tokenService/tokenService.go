@@ -0,0 +1,175 @@
+package tokenService
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/Tushar456/go-carzone/models"
+	"github.com/Tushar456/go-carzone/repository"
+	"github.com/Tushar456/go-carzone/service"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+)
+
+type TokenService struct {
+	store     repository.TokenRepositoryInterface
+	userStore repository.UserRepositoryInterface
+	logger    *slog.Logger
+}
+
+func NewTokenService(store repository.TokenRepositoryInterface, userStore repository.UserRepositoryInterface, logger *slog.Logger) *TokenService {
+	return &TokenService{
+		store:     store,
+		userStore: userStore,
+		logger:    logger,
+	}
+}
+
+func (ts *TokenService) IssueRefreshToken(ctx context.Context, user *models.User) (string, error) {
+	ctx, span := otel.Tracer("tokenservice").Start(ctx, "IssueRefreshToken")
+	defer span.End()
+
+	token, _, err := ts.createRefreshToken(ctx, user.ID, uuid.New())
+	return token, err
+}
+
+// RotateRefreshToken exchanges a refresh token for a new one in the same
+// family and returns the user it belongs to. Presenting a token that was
+// already rotated or revoked is treated as theft, and the whole family is
+// revoked so neither the thief nor the legitimate client can continue.
+func (ts *TokenService) RotateRefreshToken(ctx context.Context, refreshToken string) (*models.User, string, error) {
+	ctx, span := otel.Tracer("tokenservice").Start(ctx, "RotateRefreshToken")
+	defer span.End()
+
+	stored, err := ts.store.GetRefreshTokenByHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrTokenNotFound) {
+			return nil, "", service.ErrInvalidRefreshToken
+		}
+		return nil, "", err
+	}
+
+	if stored.RevokedAt != nil {
+		if err := ts.store.RevokeRefreshTokenFamily(ctx, stored.FamilyID); err != nil {
+			return nil, "", err
+		}
+		ts.logger.WarnContext(ctx, "Refresh token reused, revoked its family", "user_id", stored.UserID, "family_id", stored.FamilyID)
+		return nil, "", service.ErrRefreshTokenReused
+	}
+
+	if stored.ExpiresAt.Before(time.Now()) {
+		return nil, "", service.ErrInvalidRefreshToken
+	}
+
+	user, err := ts.userStore.GetUserById(ctx, stored.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, "", service.ErrInvalidRefreshToken
+		}
+		return nil, "", err
+	}
+
+	token, newID, err := ts.createRefreshToken(ctx, stored.UserID, stored.FamilyID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	revoked, err := ts.store.RevokeRefreshToken(ctx, stored.ID, &newID)
+	if err != nil {
+		return nil, "", err
+	}
+	if !revoked {
+		// A concurrent request rotated the same token first.
+		if err := ts.store.RevokeRefreshTokenFamily(ctx, stored.FamilyID); err != nil {
+			return nil, "", err
+		}
+		ts.logger.WarnContext(ctx, "Refresh token rotated concurrently, revoked its family", "user_id", stored.UserID, "family_id", stored.FamilyID)
+		return nil, "", service.ErrRefreshTokenReused
+	}
+
+	return user, token, nil
+}
+
+// RevokeRefreshToken ends the session the refresh token belongs to.
+func (ts *TokenService) RevokeRefreshToken(ctx context.Context, refreshToken string) error {
+	ctx, span := otel.Tracer("tokenservice").Start(ctx, "RevokeRefreshToken")
+	defer span.End()
+
+	stored, err := ts.store.GetRefreshTokenByHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrTokenNotFound) {
+			return service.ErrInvalidRefreshToken
+		}
+		return err
+	}
+
+	return ts.store.RevokeRefreshTokenFamily(ctx, stored.FamilyID)
+}
+
+func (ts *TokenService) RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	ctx, span := otel.Tracer("tokenservice").Start(ctx, "RevokeAccessToken")
+	defer span.End()
+
+	return ts.store.RevokeAccessToken(ctx, &models.RevokedToken{
+		JTI:       jti,
+		ExpiresAt: expiresAt,
+	})
+}
+
+func (ts *TokenService) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	ctx, span := otel.Tracer("tokenservice").Start(ctx, "IsRevoked")
+	defer span.End()
+
+	return ts.store.IsAccessTokenRevoked(ctx, jti)
+}
+
+// PurgeExpiredTokens deletes the refresh tokens and the access token
+// revocations that have expired and can no longer be presented.
+func (ts *TokenService) PurgeExpiredTokens(ctx context.Context) (int64, error) {
+	ctx, span := otel.Tracer("tokenservice").Start(ctx, "PurgeExpiredTokens")
+	defer span.End()
+
+	return ts.store.PurgeExpiredTokens(ctx, time.Now())
+}
+
+func (ts *TokenService) createRefreshToken(ctx context.Context, userID uuid.UUID, familyID uuid.UUID) (string, uuid.UUID, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", uuid.Nil, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	stored := &models.RefreshToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(refreshTokenExpiry()),
+	}
+
+	if err := ts.store.CreateRefreshToken(ctx, stored); err != nil {
+		return "", uuid.Nil, err
+	}
+	return token, stored.ID, nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func refreshTokenExpiry() time.Duration {
+	expiryTime, err := strconv.Atoi(os.Getenv("JWT_REFRESH_EXPIRY_TIME"))
+	if err != nil || expiryTime <= 0 {
+		expiryTime = 7 * 24 * 60 // default to 7 days, in minutes like JWT_EXPIRY_TIME
+	}
+	return time.Minute * time.Duration(expiryTime)
+}
@@ -0,0 +1,186 @@
+package userService
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/Tushar456/go-carzone/models"
+	"github.com/Tushar456/go-carzone/repository"
+	"github.com/Tushar456/go-carzone/service"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// dummyHash is compared against when the user does not exist so that a
+// failed login takes the same time whether or not the username is known.
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("carzone-dummy-password"), bcrypt.DefaultCost)
+
+type UserService struct {
+	store      repository.UserRepositoryInterface
+	tokenStore repository.TokenRepositoryInterface
+	transactor repository.TransactorInterface
+	logger     *slog.Logger
+}
+
+func NewUserService(store repository.UserRepositoryInterface, tokenStore repository.TokenRepositoryInterface, transactor repository.TransactorInterface, logger *slog.Logger) *UserService {
+	return &UserService{
+		store:      store,
+		tokenStore: tokenStore,
+		transactor: transactor,
+		logger:     logger,
+	}
+}
+
+func (us *UserService) Register(ctx context.Context, request *models.RegisterRequest) (*models.User, error) {
+	ctx, span := otel.Tracer("userservice").Start(ctx, "Register")
+	defer span.End()
+
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	_, err := us.store.GetUserByUsername(ctx, request.Username)
+	if err == nil {
+		return nil, service.ErrUsernameTaken
+	}
+	if !errors.Is(err, repository.ErrUserNotFound) {
+		return nil, err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{
+		ID:           uuid.New(),
+		Username:     request.Username,
+		PasswordHash: string(hash),
+		Role:         models.RoleViewer,
+	}
+
+	user, err = us.store.CreateUser(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	us.logger.InfoContext(ctx, "Registered user", "username", user.Username)
+	return user, nil
+}
+
+func (us *UserService) Authenticate(ctx context.Context, credentials *models.Credentials) (*models.User, error) {
+	ctx, span := otel.Tracer("userservice").Start(ctx, "Authenticate")
+	defer span.End()
+
+	user, err := us.store.GetUserByUsername(ctx, credentials.Username)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(credentials.Password))
+			return nil, service.ErrInvalidCredentials
+		}
+		return nil, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(credentials.Password)); err != nil {
+		us.logger.InfoContext(ctx, "Rejected wrong password", "username", credentials.Username)
+		return nil, service.ErrInvalidCredentials
+	}
+
+	return user, nil
+}
+
+func (us *UserService) ChangePassword(ctx context.Context, username string, request *models.ChangePasswordRequest) error {
+	ctx, span := otel.Tracer("userservice").Start(ctx, "ChangePassword")
+	defer span.End()
+
+	if err := request.Validate(); err != nil {
+		return err
+	}
+
+	credentials := &models.Credentials{Username: username, Password: request.CurrentPassword}
+	user, err := us.Authenticate(ctx, credentials)
+	if err != nil {
+		return err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(request.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	// Whoever knew the old password may hold refresh tokens; they must not
+	// outlive it.
+	err = us.transactor.Transaction(ctx, func(ctx context.Context) error {
+		if err := us.store.UpdatePassword(ctx, username, string(hash)); err != nil {
+			return err
+		}
+		return us.tokenStore.RevokeUserRefreshTokens(ctx, user.ID)
+	})
+	if err != nil {
+		return err
+	}
+	us.logger.InfoContext(ctx, "Changed password", "username", username)
+	return nil
+}
+
+func (us *UserService) UpdateRole(ctx context.Context, username string, request *models.UpdateRoleRequest) (*models.User, error) {
+	ctx, span := otel.Tracer("userservice").Start(ctx, "UpdateRole")
+	defer span.End()
+
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Refresh tokens must not keep issuing access tokens with the old role.
+	var user *models.User
+	err := us.transactor.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		if user, err = us.store.UpdateRole(ctx, username, request.Role); err != nil {
+			return err
+		}
+		return us.tokenStore.RevokeUserRefreshTokens(ctx, user.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	us.logger.InfoContext(ctx, "Updated role", "username", username, "role", user.Role)
+	return user, nil
+}
+
+// EnsureAdmin creates the given admin account if it does not exist yet, so a
+// fresh deployment has someone able to grant roles to everybody else.
+func (us *UserService) EnsureAdmin(ctx context.Context, username string, password string) error {
+	ctx, span := otel.Tracer("userservice").Start(ctx, "EnsureAdmin")
+	defer span.End()
+
+	_, err := us.store.GetUserByUsername(ctx, username)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, repository.ErrUserNotFound) {
+		return err
+	}
+
+	request := &models.RegisterRequest{Username: username, Password: password}
+	if err := request.Validate(); err != nil {
+		return err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	_, err = us.store.CreateUser(ctx, &models.User{
+		ID:           uuid.New(),
+		Username:     username,
+		PasswordHash: string(hash),
+		Role:         models.RoleAdmin,
+	})
+	if err != nil {
+		return err
+	}
+	us.logger.InfoContext(ctx, "Created admin user", "username", username)
+	return nil
+}
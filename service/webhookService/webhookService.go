@@ -0,0 +1,235 @@
+package webhookService
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+
+	"github.com/Tushar456/go-carzone/apperrors"
+	"github.com/Tushar456/go-carzone/models"
+	"github.com/Tushar456/go-carzone/repository"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+)
+
+type WebhookService struct {
+	store      repository.WebhookRepositoryInterface
+	brandStore repository.BrandRepositoryInterface
+	transactor repository.TransactorInterface
+	logger     *slog.Logger
+}
+
+func NewWebhookService(store repository.WebhookRepositoryInterface, brandStore repository.BrandRepositoryInterface, transactor repository.TransactorInterface, logger *slog.Logger) *WebhookService {
+	return &WebhookService{
+		store:      store,
+		brandStore: brandStore,
+		transactor: transactor,
+		logger:     logger,
+	}
+}
+
+func (ws *WebhookService) GetSubscriptionById(ctx context.Context, id string) (*models.WebhookSubscription, error) {
+	ctx, span := otel.Tracer("webhookservice").Start(ctx, "GetSubscriptionById")
+	defer span.End()
+	if err := validateID(id, "webhook"); err != nil {
+		return &models.WebhookSubscription{}, err
+	}
+	subscription, err := ws.store.GetSubscriptionById(ctx, id)
+	if err != nil {
+		return &models.WebhookSubscription{}, err
+	}
+	return subscription, nil
+}
+
+func (ws *WebhookService) ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	ctx, span := otel.Tracer("webhookservice").Start(ctx, "ListSubscriptions")
+	defer span.End()
+
+	return ws.store.ListSubscriptions(ctx)
+}
+
+// CreateSubscription creates an active subscription unless the request says
+// otherwise, generating its secret when none is given. The response is the
+// only place the secret is returned.
+func (ws *WebhookService) CreateSubscription(ctx context.Context, request *models.WebhookSubscriptionRequest) (*models.CreatedWebhookSubscription, error) {
+	ctx, span := otel.Tracer("webhookservice").Start(ctx, "CreateSubscription")
+	defer span.End()
+
+	if err := request.Validate(); err != nil {
+		return &models.CreatedWebhookSubscription{}, err
+	}
+	brandIDs, err := ws.resolveBrands(ctx, request.Brands)
+	if err != nil {
+		return &models.CreatedWebhookSubscription{}, err
+	}
+
+	secret := request.Secret
+	if secret == "" {
+		if secret, err = generateSecret(); err != nil {
+			return &models.CreatedWebhookSubscription{}, err
+		}
+	}
+	active := true
+	if request.Active != nil {
+		active = *request.Active
+	}
+
+	subscription, err := ws.store.CreateSubscription(ctx, &models.WebhookSubscription{
+		ID:         uuid.New(),
+		URL:        request.URL,
+		Secret:     secret,
+		EventTypes: eventTypes(request.EventTypes),
+		BrandIDs:   brandIDs,
+		Active:     active,
+	})
+	if err != nil {
+		return &models.CreatedWebhookSubscription{}, err
+	}
+	ws.logger.InfoContext(ctx, "Created webhook subscription", "webhook_id", subscription.ID, "url", subscription.URL)
+	return &models.CreatedWebhookSubscription{WebhookSubscription: *subscription, Secret: secret}, nil
+}
+
+// UpdateSubscription replaces the settings of a subscription. The secret
+// and the active flag are kept when the request leaves them out.
+func (ws *WebhookService) UpdateSubscription(ctx context.Context, id string, request *models.WebhookSubscriptionRequest) (*models.WebhookSubscription, error) {
+	ctx, span := otel.Tracer("webhookservice").Start(ctx, "UpdateSubscription")
+	defer span.End()
+
+	if err := validateID(id, "webhook"); err != nil {
+		return &models.WebhookSubscription{}, err
+	}
+	if err := request.Validate(); err != nil {
+		return &models.WebhookSubscription{}, err
+	}
+
+	subscription, err := ws.store.GetSubscriptionById(ctx, id)
+	if err != nil {
+		return &models.WebhookSubscription{}, err
+	}
+	brandIDs, err := ws.resolveBrands(ctx, request.Brands)
+	if err != nil {
+		return &models.WebhookSubscription{}, err
+	}
+
+	subscription.URL = request.URL
+	subscription.EventTypes = eventTypes(request.EventTypes)
+	subscription.BrandIDs = brandIDs
+	if request.Secret != "" {
+		subscription.Secret = request.Secret
+	}
+	if request.Active != nil {
+		subscription.Active = *request.Active
+	}
+
+	subscription, err = ws.store.UpdateSubscription(ctx, subscription)
+	if err != nil {
+		return &models.WebhookSubscription{}, err
+	}
+	ws.logger.InfoContext(ctx, "Updated webhook subscription", "webhook_id", subscription.ID, "url", subscription.URL, "active", subscription.Active)
+	return subscription, nil
+}
+
+func (ws *WebhookService) DeleteSubscription(ctx context.Context, id string) (*models.WebhookSubscription, error) {
+	ctx, span := otel.Tracer("webhookservice").Start(ctx, "DeleteSubscription")
+	defer span.End()
+	if err := validateID(id, "webhook"); err != nil {
+		return &models.WebhookSubscription{}, err
+	}
+	var subscription *models.WebhookSubscription
+	err := ws.transactor.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		subscription, err = ws.store.DeleteSubscription(ctx, id)
+		return err
+	})
+	if err != nil {
+		return &models.WebhookSubscription{}, err
+	}
+	ws.logger.InfoContext(ctx, "Deleted webhook subscription", "webhook_id", subscription.ID)
+	return subscription, nil
+}
+
+func (ws *WebhookService) ListDeliveries(ctx context.Context, id string, filter *models.WebhookDeliveryFilter) (*models.Page[models.WebhookDelivery], error) {
+	ctx, span := otel.Tracer("webhookservice").Start(ctx, "ListDeliveries")
+	defer span.End()
+
+	if err := validateID(id, "webhook"); err != nil {
+		return nil, err
+	}
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	filter.ApplyDefaults()
+
+	if _, err := ws.store.GetSubscriptionById(ctx, id); err != nil {
+		return nil, err
+	}
+	return ws.store.ListDeliveries(ctx, id, filter)
+}
+
+// Redeliver queues a delivery again, whatever its state, for the deliverer
+// to send at once.
+func (ws *WebhookService) Redeliver(ctx context.Context, id string, deliveryID string) (*models.WebhookDelivery, error) {
+	ctx, span := otel.Tracer("webhookservice").Start(ctx, "Redeliver")
+	defer span.End()
+
+	if err := validateID(id, "webhook"); err != nil {
+		return &models.WebhookDelivery{}, err
+	}
+	if err := validateID(deliveryID, "delivery"); err != nil {
+		return &models.WebhookDelivery{}, err
+	}
+	delivery, err := ws.store.Redeliver(ctx, id, deliveryID)
+	if err != nil {
+		return &models.WebhookDelivery{}, err
+	}
+	ws.logger.InfoContext(ctx, "Queued webhook redelivery", "webhook_id", id, "delivery_id", delivery.ID)
+	return delivery, nil
+}
+
+// resolveBrands returns the IDs of the catalog brands with the given names
+// or aliases.
+func (ws *WebhookService) resolveBrands(ctx context.Context, names []string) (models.StringList, error) {
+	resolved, err := ws.brandStore.ResolveBrands(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := models.StringList{}
+	for _, name := range names {
+		brand, ok := resolved[models.NormalizeBrandName(name)]
+		if !ok {
+			return nil, apperrors.ForeignKey("brands", fmt.Sprintf("brand %q is not in the catalog", name))
+		}
+		if !ids.Contains(brand.ID.String()) {
+			ids = append(ids, brand.ID.String())
+		}
+	}
+	return ids, nil
+}
+
+func eventTypes(types []string) models.StringList {
+	list := models.StringList{}
+	for _, t := range types {
+		if !list.Contains(t) {
+			list = append(list, t)
+		}
+	}
+	return list
+}
+
+func generateSecret() (string, error) {
+	raw := make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(raw), nil
+}
+
+func validateID(id string, name string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return apperrors.BadRequest(name + " id must be a valid UUID")
+	}
+	return nil
+}
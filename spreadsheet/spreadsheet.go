@@ -0,0 +1,133 @@
+// Package spreadsheet reads and writes tabular data as CSV or XLSX, so the
+// import and export endpoints can treat both formats the same way.
+package spreadsheet
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Tushar456/go-carzone/apperrors"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// Writer writes one row at a time. Close must be called to flush the output.
+type Writer interface {
+	WriteRow(values []interface{}) error
+	Close() error
+}
+
+// ValidateFormat checks a format name, defaulting to CSV when it is empty.
+func ValidateFormat(format string) (string, error) {
+	switch strings.ToLower(format) {
+	case "", FormatCSV:
+		return FormatCSV, nil
+	case FormatXLSX:
+		return FormatXLSX, nil
+	default:
+		return "", apperrors.Validation("format", "format must be one of csv xlsx")
+	}
+}
+
+// ContentType returns the media type of a format.
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv"
+}
+
+// NewWriter returns a writer of the given format. XLSX rows go to the sheet
+// named sheet and the workbook is written to w on Close.
+func NewWriter(format string, w io.Writer, sheet string) (Writer, error) {
+	if format == FormatCSV {
+		return &csvWriter{w: csv.NewWriter(w)}, nil
+	}
+
+	file := excelize.NewFile()
+	if err := file.SetSheetName(file.GetSheetName(0), sheet); err != nil {
+		return nil, err
+	}
+	stream, err := file.NewStreamWriter(sheet)
+	if err != nil {
+		return nil, err
+	}
+	return &xlsxWriter{file: file, stream: stream, out: w}, nil
+}
+
+// ReadAll reads every row of a CSV file or of the first sheet of an XLSX
+// workbook.
+func ReadAll(format string, r io.Reader) ([][]string, error) {
+	if format == FormatCSV {
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		rows, err := reader.ReadAll()
+		if err != nil {
+			return nil, apperrors.Wrap(err, apperrors.BadRequest("invalid CSV file: "+err.Error()))
+		}
+		return rows, nil
+	}
+
+	file, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.BadRequest("invalid XLSX file: "+err.Error()))
+	}
+	defer file.Close()
+
+	rows, err := file.GetRows(file.GetSheetName(0))
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.BadRequest("invalid XLSX file: "+err.Error()))
+	}
+	return rows, nil
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvWriter) WriteRow(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		if v != nil {
+			record[i] = fmt.Sprint(v)
+		}
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type xlsxWriter struct {
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	out    io.Writer
+	row    int
+}
+
+func (x *xlsxWriter) WriteRow(values []interface{}) error {
+	x.row++
+	cell, err := excelize.CoordinatesToCellName(1, x.row)
+	if err != nil {
+		return err
+	}
+	return x.stream.SetRow(cell, values)
+}
+
+func (x *xlsxWriter) Close() error {
+	defer x.file.Close()
+	if err := x.stream.Flush(); err != nil {
+		return err
+	}
+	_, err := x.file.WriteTo(x.out)
+	return err
+}
@@ -0,0 +1,198 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Tushar456/go-carzone/models"
+	"github.com/Tushar456/go-carzone/repository"
+	"go.opentelemetry.io/otel"
+)
+
+const (
+	DefaultBatchSize    = 50
+	DefaultPollInterval = time.Second
+	// DefaultLease outlasts sending a full batch to receivers that all time
+	// out.
+	DefaultLease = 10 * time.Minute
+
+	// EventHeader and DeliveryHeader carry the event type and the delivery
+	// ID. A delivery keeps its ID across attempts, so receivers can use it
+	// to drop duplicates.
+	EventHeader    = "X-Carzone-Event"
+	DeliveryHeader = "X-Carzone-Delivery"
+
+	minBackoff = 30 * time.Second
+	maxBackoff = 6 * time.Hour
+
+	// maxErrorLength bounds the part of a response body kept as the error
+	// of a failed attempt.
+	maxErrorLength = 512
+)
+
+// Deliverer sends queued deliveries to their subscriptions. A response with
+// a 2xx status marks a delivery delivered; anything else is retried with
+// backoff, and after models.MaxWebhookAttempts failures the delivery is
+// dead until it is redelivered by hand.
+//
+// Deliveries are claimed in a short transaction that leases them for Lease
+// and are sent outside any transaction, so a slow receiver holds neither
+// locks nor a database connection. A delivery whose worker dies is claimed
+// again once its lease runs out.
+type Deliverer struct {
+	store      repository.WebhookRepositoryInterface
+	transactor repository.TransactorInterface
+
+	Client       *http.Client
+	BatchSize    int
+	PollInterval time.Duration
+	Lease        time.Duration
+	Logger       *slog.Logger
+}
+
+func NewDeliverer(store repository.WebhookRepositoryInterface, transactor repository.TransactorInterface) *Deliverer {
+	return &Deliverer{
+		store:        store,
+		transactor:   transactor,
+		Client:       &http.Client{Timeout: 10 * time.Second},
+		BatchSize:    DefaultBatchSize,
+		PollInterval: DefaultPollInterval,
+		Lease:        DefaultLease,
+		Logger:       slog.Default(),
+	}
+}
+
+// Run sends deliveries until ctx is cancelled. It polls again at once while
+// it finds full batches and waits PollInterval otherwise.
+func (d *Deliverer) Run(ctx context.Context) {
+	for {
+		n, err := d.DeliverOnce(ctx)
+		if err != nil {
+			d.Logger.ErrorContext(ctx, "Error delivering webhooks", "error", err)
+		}
+		if err == nil && n == d.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(d.PollInterval):
+		}
+	}
+}
+
+// DeliverOnce makes one attempt at each delivery of a batch of due ones and
+// reports how many it attempted.
+func (d *Deliverer) DeliverOnce(ctx context.Context) (int, error) {
+	ctx, span := otel.Tracer("webhook").Start(ctx, "DeliverOnce")
+	defer span.End()
+
+	var deliveries []models.WebhookDelivery
+	err := d.transactor.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		deliveries, err = d.store.ClaimDeliveries(ctx, d.BatchSize, time.Now(), d.Lease)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	subscriptions := map[string]*models.WebhookSubscription{}
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		subscription, ok := subscriptions[delivery.SubscriptionID.String()]
+		if !ok {
+			if subscription, err = d.store.GetSubscriptionById(ctx, delivery.SubscriptionID.String()); err != nil {
+				return i, err
+			}
+			subscriptions[delivery.SubscriptionID.String()] = subscription
+		}
+
+		d.attempt(ctx, subscription, delivery)
+		err = d.transactor.Transaction(ctx, func(ctx context.Context) error {
+			return d.store.SaveDeliveryAttempt(ctx, delivery)
+		})
+		if err != nil {
+			return i + 1, err
+		}
+	}
+	return len(deliveries), nil
+}
+
+// attempt sends a delivery once and records the outcome in it.
+func (d *Deliverer) attempt(ctx context.Context, subscription *models.WebhookSubscription, delivery *models.WebhookDelivery) {
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.LastStatusCode = 0
+	delivery.LastError = ""
+
+	var err error
+	if subscription.Active {
+		delivery.LastStatusCode, err = d.send(ctx, subscription, delivery)
+	} else {
+		err = fmt.Errorf("subscription is inactive")
+	}
+
+	if err == nil {
+		delivery.Status = models.DeliveryStatusDelivered
+		delivery.DeliveredAt = &now
+		return
+	}
+
+	delivery.LastError = err.Error()
+	if delivery.Attempts >= models.MaxWebhookAttempts {
+		delivery.Status = models.DeliveryStatusDead
+		d.Logger.WarnContext(ctx, "Webhook delivery is dead",
+			"delivery_id", delivery.ID, "event_type", delivery.EventType, "event_id", delivery.EventID,
+			"attempts", delivery.Attempts, "error", err)
+		return
+	}
+	delivery.NextAttemptAt = now.Add(Backoff(delivery.Attempts))
+}
+
+// send POSTs a delivery and returns the response status.
+func (d *Deliverer) send(ctx context.Context, subscription *models.WebhookSubscription, delivery *models.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, delivery.ID.String())
+	req.Header.Set(SignatureHeader, Sign(subscription.Secret, body, time.Now()))
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorLength))
+		if len(detail) > 0 {
+			return resp.StatusCode, fmt.Errorf("webhook responded %s: %s", resp.Status, detail)
+		}
+		return resp.StatusCode, fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode, nil
+}
+
+// Backoff returns how long to wait before another attempt after the given
+// number of failed ones: 30 seconds after the first, doubling up to six
+// hours.
+func Backoff(attempts int) time.Duration {
+	backoff := minBackoff
+	for i := 1; i < attempts && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxBackoff)
+}
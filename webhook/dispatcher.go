@@ -0,0 +1,71 @@
+// Package webhook delivers domain events to the URLs of webhook
+// subscriptions. A Dispatcher, run as an outbox sink, queues a delivery for
+// every subscription an event matches; a Deliverer sends the queued
+// deliveries, signed with the subscription's secret, retrying failures with
+// exponential backoff until they succeed or are given up as dead.
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/Tushar456/go-carzone/models"
+	"github.com/Tushar456/go-carzone/repository"
+	webhookRepository "github.com/Tushar456/go-carzone/repository/webhook-repository"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+)
+
+// Dispatcher is an outbox.Sink queueing deliveries of events to the
+// matching subscriptions. Publishing the same event again queues nothing new.
+type Dispatcher struct {
+	store repository.WebhookRepositoryInterface
+}
+
+func NewDispatcher(store repository.WebhookRepositoryInterface) *Dispatcher {
+	return &Dispatcher{store: store}
+}
+
+func (d *Dispatcher) Publish(ctx context.Context, event *models.OutboxEvent) error {
+	ctx, span := otel.Tracer("webhook").Start(ctx, "Dispatch")
+	defer span.End()
+
+	subscriptions, err := d.store.ListActiveSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	brandID := eventBrandID(event)
+	var body []byte
+	var deliveries []models.WebhookDelivery
+	for i := range subscriptions {
+		if !subscriptions[i].Matches(event.Type, brandID) {
+			continue
+		}
+		if body == nil {
+			if body, err = json.Marshal(event); err != nil {
+				return err
+			}
+		}
+		deliveries = append(deliveries, webhookRepository.NewDelivery(subscriptions[i].ID, event, body))
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return d.store.CreateDeliveries(ctx, deliveries)
+}
+
+// eventBrandID returns the brand of the car a car event is about, and
+// uuid.Nil for other events.
+func eventBrandID(event *models.OutboxEvent) uuid.UUID {
+	if event.AggregateType != models.AggregateCar {
+		return uuid.Nil
+	}
+	var car struct {
+		BrandID uuid.UUID `json:"brand_id"`
+	}
+	if err := json.Unmarshal(event.Payload, &car); err != nil {
+		return uuid.Nil
+	}
+	return car.BrandID
+}
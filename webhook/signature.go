@@ -0,0 +1,65 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureHeader carries the signature of a delivery, in the form
+// "t=<unix seconds>,v1=<hex HMAC-SHA256>". The MAC is computed with the
+// subscription's secret over the timestamp, a dot and the request body, so
+// a receiver can reject both forged and replayed deliveries.
+const SignatureHeader = "X-Carzone-Signature"
+
+var (
+	ErrInvalidSignature = errors.New("webhook: invalid signature")
+	ErrSignatureExpired = errors.New("webhook: signature timestamp outside tolerance")
+)
+
+// Sign returns the signature header value for a body sent at the given time.
+func Sign(secret string, body []byte, at time.Time) string {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	return "t=" + timestamp + ",v1=" + mac(secret, timestamp, body)
+}
+
+// Verify checks a signature header value against a body. It is what a
+// receiver runs; a tolerance of zero skips the timestamp check.
+func Verify(secret string, body []byte, header string, tolerance time.Duration, now time.Time) error {
+	var timestamp, signature string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signature = value
+		}
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || signature == "" {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(signature), []byte(mac(secret, timestamp, body))) {
+		return ErrInvalidSignature
+	}
+	if tolerance > 0 {
+		if age := now.Sub(time.Unix(unix, 0)); age > tolerance || age < -tolerance {
+			return ErrSignatureExpired
+		}
+	}
+	return nil
+}
+
+func mac(secret, timestamp string, body []byte) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(timestamp))
+	h.Write([]byte("."))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
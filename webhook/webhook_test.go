@@ -0,0 +1,374 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Tushar456/go-carzone/models"
+	"github.com/Tushar456/go-carzone/repository"
+	"github.com/Tushar456/go-carzone/repository/contract"
+	webhookRepository "github.com/Tushar456/go-carzone/repository/webhook-repository"
+	"github.com/Tushar456/go-carzone/webhook"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const secret = "0123456789abcdef"
+
+type fixture struct {
+	db        *gorm.DB
+	store     *webhookRepository.WebhookRepository
+	deliverer *webhook.Deliverer
+}
+
+func newFixture(t *testing.T) *fixture {
+	db, logger := contract.OpenSQLite(t), contract.Logger(t)
+	if err := db.AutoMigrate(&models.WebhookSubscription{}, &models.WebhookDelivery{}); err != nil {
+		t.Fatalf("creating tables: %v", err)
+	}
+	store := webhookRepository.NewWebhookRepository(db, logger)
+	deliverer := webhook.NewDeliverer(store, repository.NewTransactor(db))
+	deliverer.Logger = logger
+	return &fixture{db: db, store: store, deliverer: deliverer}
+}
+
+func (f *fixture) subscribe(t *testing.T, url string, eventTypes []string, brandIDs []string) *models.WebhookSubscription {
+	t.Helper()
+	subscription, err := f.store.CreateSubscription(context.Background(), &models.WebhookSubscription{
+		ID:         uuid.New(),
+		URL:        url,
+		Secret:     secret,
+		EventTypes: eventTypes,
+		BrandIDs:   brandIDs,
+		Active:     true,
+	})
+	if err != nil {
+		t.Fatalf("CreateSubscription: %v", err)
+	}
+	return subscription
+}
+
+func (f *fixture) publish(t *testing.T, event *models.OutboxEvent) {
+	t.Helper()
+	if err := webhook.NewDispatcher(f.store).Publish(context.Background(), event); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+}
+
+func (f *fixture) deliveries(t *testing.T, subscription *models.WebhookSubscription) []models.WebhookDelivery {
+	t.Helper()
+	page, err := f.store.ListDeliveries(context.Background(), subscription.ID.String(), &models.WebhookDeliveryFilter{Limit: models.MaxPageLimit})
+	if err != nil {
+		t.Fatalf("ListDeliveries: %v", err)
+	}
+	return page.Items
+}
+
+// makeDue moves the next attempt of every pending delivery to the past, as if
+// its backoff had run out.
+func (f *fixture) makeDue(t *testing.T) {
+	t.Helper()
+	err := f.db.Model(&models.WebhookDelivery{}).Where("status = ?", models.DeliveryStatusPending).
+		Update("next_attempt_at", time.Now().Add(-time.Second)).Error
+	if err != nil {
+		t.Fatalf("making deliveries due: %v", err)
+	}
+}
+
+func (f *fixture) deliverOnce(t *testing.T, want int) {
+	t.Helper()
+	n, err := f.deliverer.DeliverOnce(context.Background())
+	if err != nil {
+		t.Fatalf("DeliverOnce: %v", err)
+	}
+	if n != want {
+		t.Fatalf("DeliverOnce attempted %d deliveries, want %d", n, want)
+	}
+}
+
+func carEvent(eventType string, brandID uuid.UUID) *models.OutboxEvent {
+	payload, _ := json.Marshal(map[string]interface{}{"id": uuid.New(), "brand_id": brandID})
+	return &models.OutboxEvent{
+		ID:            uuid.New(),
+		Type:          eventType,
+		AggregateType: models.AggregateCar,
+		AggregateID:   uuid.New(),
+		Payload:       payload,
+	}
+}
+
+func engineEvent() *models.OutboxEvent {
+	return &models.OutboxEvent{
+		ID:            uuid.New(),
+		Type:          models.EventEngineUpdated,
+		AggregateType: models.AggregateEngine,
+		AggregateID:   uuid.New(),
+		Payload:       models.JSON(`{"id":"` + uuid.NewString() + `"}`),
+	}
+}
+
+// receiver is a webhook endpoint answering with status and recording the
+// requests it gets.
+type receiver struct {
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func newReceiver(t *testing.T, status int) (*receiver, *httptest.Server) {
+	r := &receiver{status: status}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.requests = append(r.requests, req)
+		r.bodies = append(r.bodies, body)
+		w.WriteHeader(r.status)
+		if r.status >= 300 {
+			io.WriteString(w, "receiver is down")
+		}
+	}))
+	t.Cleanup(server.Close)
+	return r, server
+}
+
+func (r *receiver) received() ([]*http.Request, [][]byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.requests, r.bodies
+}
+
+func (r *receiver) setStatus(status int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status = status
+}
+
+func TestSignVerify(t *testing.T) {
+	body := []byte(`{"type":"CarCreated"}`)
+	at := time.Unix(1700000000, 0)
+	header := webhook.Sign(secret, body, at)
+
+	if !strings.HasPrefix(header, "t=1700000000,v1=") {
+		t.Fatalf("Sign = %q, want it to start with the timestamp", header)
+	}
+
+	tests := []struct {
+		name      string
+		secret    string
+		body      []byte
+		header    string
+		tolerance time.Duration
+		now       time.Time
+		want      error
+	}{
+		{"valid", secret, body, header, 5 * time.Minute, at.Add(time.Minute), nil},
+		{"no tolerance", secret, body, header, 0, at.Add(24 * time.Hour), nil},
+		{"spaces around parts", secret, body, strings.ReplaceAll(header, ",", ", "), 0, at, nil},
+		{"wrong secret", "fedcba9876543210", body, header, 0, at, webhook.ErrInvalidSignature},
+		{"tampered body", secret, []byte(`{"type":"CarDeleted"}`), header, 0, at, webhook.ErrInvalidSignature},
+		{"tampered timestamp", secret, body, strings.Replace(header, "t=1700000000", "t=1700000001", 1), 0, at, webhook.ErrInvalidSignature},
+		{"missing signature", secret, body, "t=1700000000", 0, at, webhook.ErrInvalidSignature},
+		{"garbage", secret, body, "nonsense", 0, at, webhook.ErrInvalidSignature},
+		{"too old", secret, body, header, 5 * time.Minute, at.Add(6 * time.Minute), webhook.ErrSignatureExpired},
+		{"from the future", secret, body, header, 5 * time.Minute, at.Add(-6 * time.Minute), webhook.ErrSignatureExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := webhook.Verify(tt.secret, tt.body, tt.header, tt.tolerance, tt.now)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Verify = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{5, 8 * time.Minute},
+		{10, 256 * time.Minute},
+		{11, 6 * time.Hour},
+		{100, 6 * time.Hour},
+	}
+	for _, tt := range tests {
+		if got := webhook.Backoff(tt.attempts); got != tt.want {
+			t.Errorf("Backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestDeliverer(t *testing.T) {
+	f := newFixture(t)
+	r, server := newReceiver(t, http.StatusNoContent)
+	subscription := f.subscribe(t, server.URL, nil, nil)
+
+	event := carEvent(models.EventCarCreated, uuid.New())
+	f.publish(t, event)
+	f.deliverOnce(t, 1)
+
+	requests, bodies := r.received()
+	if len(requests) != 1 {
+		t.Fatalf("receiver got %d requests, want 1", len(requests))
+	}
+	req, body := requests[0], bodies[0]
+	if err := webhook.Verify(secret, body, req.Header.Get(webhook.SignatureHeader), time.Minute, time.Now()); err != nil {
+		t.Errorf("Verify: %v", err)
+	}
+	if got := req.Header.Get(webhook.EventHeader); got != models.EventCarCreated {
+		t.Errorf("%s = %q, want %q", webhook.EventHeader, got, models.EventCarCreated)
+	}
+
+	var sent models.OutboxEvent
+	if err := json.Unmarshal(body, &sent); err != nil {
+		t.Fatalf("decoding the body: %v", err)
+	}
+	if sent.ID != event.ID || sent.Type != event.Type {
+		t.Errorf("sent event %s %s, want %s %s", sent.ID, sent.Type, event.ID, event.Type)
+	}
+
+	deliveries := f.deliveries(t, subscription)
+	if len(deliveries) != 1 {
+		t.Fatalf("got %d deliveries, want 1", len(deliveries))
+	}
+	delivery := deliveries[0]
+	if got := req.Header.Get(webhook.DeliveryHeader); got != delivery.ID.String() {
+		t.Errorf("%s = %q, want %q", webhook.DeliveryHeader, got, delivery.ID)
+	}
+	if delivery.Status != models.DeliveryStatusDelivered || delivery.Attempts != 1 || delivery.LastStatusCode != http.StatusNoContent || delivery.DeliveredAt == nil {
+		t.Errorf("delivery = %s after %d attempts, status code %d, delivered at %v; want delivered after 1 attempt with 204",
+			delivery.Status, delivery.Attempts, delivery.LastStatusCode, delivery.DeliveredAt)
+	}
+
+	// A delivered delivery is not sent again.
+	f.makeDue(t)
+	f.deliverOnce(t, 0)
+}
+
+func TestDelivererRetriesUntilDead(t *testing.T) {
+	f := newFixture(t)
+	r, server := newReceiver(t, http.StatusInternalServerError)
+	subscription := f.subscribe(t, server.URL, nil, nil)
+	f.publish(t, carEvent(models.EventCarUpdated, uuid.New()))
+
+	f.deliverOnce(t, 1)
+	delivery := f.deliveries(t, subscription)[0]
+	if delivery.Status != models.DeliveryStatusPending || delivery.Attempts != 1 || delivery.LastStatusCode != http.StatusInternalServerError {
+		t.Fatalf("delivery = %s after %d attempts, status code %d; want pending after 1 attempt with 500",
+			delivery.Status, delivery.Attempts, delivery.LastStatusCode)
+	}
+	if !strings.Contains(delivery.LastError, "receiver is down") {
+		t.Errorf("LastError = %q, want the response body in it", delivery.LastError)
+	}
+	if wait := delivery.NextAttemptAt.Sub(*delivery.LastAttemptAt); wait.Round(time.Second) != webhook.Backoff(1) {
+		t.Errorf("next attempt after %v, want %v", wait, webhook.Backoff(1))
+	}
+
+	// Not due before the backoff runs out.
+	f.deliverOnce(t, 0)
+
+	for attempt := 2; attempt <= models.MaxWebhookAttempts; attempt++ {
+		f.makeDue(t)
+		f.deliverOnce(t, 1)
+	}
+	delivery = f.deliveries(t, subscription)[0]
+	if delivery.Status != models.DeliveryStatusDead || delivery.Attempts != models.MaxWebhookAttempts {
+		t.Fatalf("delivery = %s after %d attempts, want dead after %d", delivery.Status, delivery.Attempts, models.MaxWebhookAttempts)
+	}
+	if requests, _ := r.received(); len(requests) != models.MaxWebhookAttempts {
+		t.Errorf("receiver got %d requests, want %d", len(requests), models.MaxWebhookAttempts)
+	}
+
+	// A dead delivery stays dead.
+	f.makeDue(t)
+	f.deliverOnce(t, 0)
+
+	r.setStatus(http.StatusOK)
+	redelivered, err := f.store.Redeliver(context.Background(), subscription.ID.String(), delivery.ID.String())
+	if err != nil {
+		t.Fatalf("Redeliver: %v", err)
+	}
+	if redelivered.Status != models.DeliveryStatusPending || redelivered.Attempts != 0 {
+		t.Errorf("redelivered = %s after %d attempts, want pending after 0", redelivered.Status, redelivered.Attempts)
+	}
+
+	f.deliverOnce(t, 1)
+	delivery = f.deliveries(t, subscription)[0]
+	if delivery.Status != models.DeliveryStatusDelivered || delivery.Attempts != 1 || delivery.LastError != "" {
+		t.Errorf("delivery = %s after %d attempts, error %q; want delivered after 1 with no error", delivery.Status, delivery.Attempts, delivery.LastError)
+	}
+	if delivery.ID != redelivered.ID {
+		t.Errorf("delivered %s, want the redelivered %s", delivery.ID, redelivered.ID)
+	}
+}
+
+func TestDelivererInactiveSubscription(t *testing.T) {
+	f := newFixture(t)
+	r, server := newReceiver(t, http.StatusOK)
+	subscription := f.subscribe(t, server.URL, nil, nil)
+	f.publish(t, carEvent(models.EventCarCreated, uuid.New()))
+
+	subscription.Active = false
+	if _, err := f.store.UpdateSubscription(context.Background(), subscription); err != nil {
+		t.Fatalf("UpdateSubscription: %v", err)
+	}
+
+	f.deliverOnce(t, 1)
+	if requests, _ := r.received(); len(requests) != 0 {
+		t.Errorf("receiver got %d requests, want none", len(requests))
+	}
+	delivery := f.deliveries(t, subscription)[0]
+	if delivery.Status != models.DeliveryStatusPending || delivery.LastError != "subscription is inactive" {
+		t.Errorf("delivery = %s with error %q, want pending with the subscription inactive", delivery.Status, delivery.LastError)
+	}
+}
+
+func TestDispatcher(t *testing.T) {
+	f := newFixture(t)
+	toyota, volkswagen := uuid.New(), uuid.New()
+	everything := f.subscribe(t, "https://example.com/everything", nil, nil)
+	created := f.subscribe(t, "https://example.com/created", []string{models.EventCarCreated}, nil)
+	toyotas := f.subscribe(t, "https://example.com/toyota", nil, []string{toyota.String()})
+	toyotaUpdates := f.subscribe(t, "https://example.com/toyota-updates", []string{models.EventCarUpdated}, []string{toyota.String()})
+
+	toyotaCreated := carEvent(models.EventCarCreated, toyota)
+	f.publish(t, toyotaCreated)
+	f.publish(t, carEvent(models.EventCarUpdated, toyota))
+	f.publish(t, carEvent(models.EventCarCreated, volkswagen))
+	f.publish(t, engineEvent())
+
+	// Publishing an event again queues nothing new.
+	f.publish(t, toyotaCreated)
+
+	tests := []struct {
+		name         string
+		subscription *models.WebhookSubscription
+		want         int
+	}{
+		{"every event", everything, 4},
+		{"by event type", created, 2},
+		{"by brand, without engine events", toyotas, 2},
+		{"by event type and brand", toyotaUpdates, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := len(f.deliveries(t, tt.subscription)); got != tt.want {
+				t.Errorf("got %d deliveries, want %d", got, tt.want)
+			}
+		})
+	}
+}